@@ -0,0 +1,390 @@
+// Package alertmanager translates Oh Dear webhooks into [Prometheus
+// Alertmanager] alerts and posts them to the Alertmanager v2 API.
+//
+// Failure and warning notifications fire an alert labeled with the site, its
+// tags and team, and the type of check. Recovery notifications resolve the
+// alerts previously fired for the same site and check, matched by their
+// fingerprint, so routing, silencing and inhibition can all be configured in
+// Alertmanager.
+//
+//	bridge, err := alertmanager.New("http://alertmanager:9093", nil)
+//	if err != nil {
+//		return err
+//	}
+//
+//	http.Handle("/webhook", bridge.Handler(secret))
+//
+// In the other direction, StatusPageHandler accepts the notifications of the
+// webhook receiver of Alertmanager and posts them to Oh Dear status pages, so
+// incidents Oh Dear can't see still reach the public status page.
+//
+//	handler, err := alertmanager.NewStatusPageHandler(client, &alertmanager.StatusPageOptions{
+//		Routes: []alertmanager.StatusPageRoute{
+//			{Matchers: map[string]string{"team": "jobs"}, StatusPageID: 42},
+//		},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	http.Handle("/alertmanager", handler)
+//
+// [Prometheus Alertmanager]: https://prometheus.io/docs/alerting/latest/alertmanager/
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrURLRequired is returned when a Bridge is created without the URL of
+	// Alertmanager.
+	ErrURLRequired xerrors.Error = "alertmanager URL cannot be empty"
+
+	// ErrUnexpectedStatus is returned when Alertmanager responds with an
+	// unsuccessful status code.
+	ErrUnexpectedStatus xerrors.Error = "unexpected status code"
+)
+
+// Default values for the Options struct.
+const (
+	DefaultAlertName string        = "OhDearCheckFailing"
+	DefaultAlertTTL  time.Duration = 24 * time.Hour
+	DefaultTimeout   time.Duration = 30 * time.Second
+)
+
+// Labels set on every alert.
+const (
+	LabelAlertName string = "alertname"
+	LabelSiteID    string = "site_id"
+	LabelSiteURL   string = "site_url"
+	LabelSiteName  string = "site"
+	LabelCheckType string = "check_type"
+	LabelTeam      string = "team"
+	LabelTags      string = "tags"
+	LabelSeverity  string = "severity"
+)
+
+// Values of the severity label.
+const (
+	SeverityWarning  string = "warning"
+	SeverityCritical string = "critical"
+)
+
+// _alertsPath is the path of the alerts endpoint of the Alertmanager v2 API.
+const _alertsPath string = "/api/v2/alerts"
+
+// Alert is an alert as accepted by the Alertmanager v2 API.
+type Alert struct {
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// Fingerprint returns the fingerprint Alertmanager identifies the alert by,
+// which is derived from its labels only.
+func (a *Alert) Fingerprint() string {
+	names := make([]string, 0, len(a.Labels))
+
+	for name := range a.Labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	h := fnv.New64a()
+
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(a.Labels[name]))
+		h.Write([]byte{0xff})
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Options holds the configuration for a Bridge and for Translate.
+type Options struct {
+	// Client is the HTTP client used to post alerts.
+	//
+	// This field is optional. It defaults to a client with a timeout of
+	// DefaultTimeout.
+	Client *http.Client
+
+	// Labels are added to every alert, such as the environment the bridge
+	// runs in. They're overridden by the labels set by this package.
+	//
+	// This field is optional.
+	Labels map[string]string
+
+	// AlertName is the value of the alertname label.
+	//
+	// This field is optional. It defaults to DefaultAlertName.
+	AlertName string
+
+	// AlertTTL is how long a firing alert lasts if it's not resolved. Oh Dear
+	// notifies failures once, so it should be longer than the longest
+	// expected outage.
+	//
+	// This field is optional. It defaults to DefaultAlertTTL.
+	AlertTTL time.Duration
+}
+
+// Translate returns the alert for an event, firing if the event is a failure
+// or warning and resolved if it's a recovery. It returns false for events
+// that don't imply a check result.
+//
+// Recoveries don't tell the severity of the alert they resolve, so their
+// alert has the critical severity. Bridge resolves warnings as well.
+func Translate(event *webhook.Event, now time.Time, opts *Options) (*Alert, bool) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.AlertName == "" {
+		o.AlertName = DefaultAlertName
+	}
+
+	if o.AlertTTL < 1 {
+		o.AlertTTL = DefaultAlertTTL
+	}
+
+	severity := SeverityCritical
+
+	switch event.Result {
+	case ohdear.CheckResultWarning:
+		severity = SeverityWarning
+	case ohdear.CheckResultFailed, ohdear.CheckResultErroredOrTimedOut, ohdear.CheckResultSucceeded:
+	default:
+		return nil, false
+	}
+
+	labels := make(map[string]string, len(o.Labels)+8)
+
+	for name, value := range o.Labels {
+		labels[name] = value
+	}
+
+	labels[LabelAlertName] = o.AlertName
+	labels[LabelSiteID] = strconv.Itoa(event.Site.ID)
+	labels[LabelSiteURL] = event.Site.URL
+	labels[LabelSiteName] = event.Site.Name()
+	labels[LabelCheckType] = string(event.CheckType)
+	labels[LabelSeverity] = severity
+
+	if event.Site.TeamID != 0 {
+		labels[LabelTeam] = strconv.Itoa(event.Site.TeamID)
+	}
+
+	if len(event.Site.Tags) > 0 {
+		// Surrounding commas let routes match a single tag with a regular
+		// expression such as ".*,production,.*".
+		labels[LabelTags] = "," + strings.Join(event.Site.Tags, ",") + ","
+	}
+
+	startsAt := event.Time
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+
+	alert := &Alert{
+		StartsAt: startsAt,
+		EndsAt:   now.Add(o.AlertTTL),
+		Labels:   labels,
+		Annotations: map[string]string{
+			"summary": event.Summary,
+		},
+		GeneratorURL: resultURL(event),
+	}
+
+	if event.Result == ohdear.CheckResultSucceeded {
+		alert.EndsAt = now
+	}
+
+	return alert, true
+}
+
+// Post posts alerts to the Alertmanager at baseURL.
+func Post(ctx context.Context, client *http.Client, baseURL string, alerts []*Alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("could not marshal alerts: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+_alertsPath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not post alerts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Bridge posts the alerts translated from Oh Dear webhooks to Alertmanager.
+// It remembers the alerts it fired, so recoveries resolve them even if their
+// labels differ, such as when a warning turned into a failure. It's safe for
+// concurrent use.
+type Bridge struct {
+	// active holds the firing alerts by the site and check they're about,
+	// then by fingerprint.
+	active map[string]map[string]*Alert
+
+	opts Options
+	url  string
+
+	// mu protects active.
+	mu sync.Mutex
+}
+
+// New returns a new Bridge posting alerts to the Alertmanager at url.
+func New(url string, opts *Options) (*Bridge, error) {
+	if url == "" {
+		return nil, ErrURLRequired
+	}
+
+	b := &Bridge{
+		active: make(map[string]map[string]*Alert),
+		url:    url,
+	}
+
+	if opts != nil {
+		b.opts = *opts
+	}
+
+	return b, nil
+}
+
+// Handler returns an http.Handler that verifies the webhooks sent by Oh Dear
+// with the given secret and passes them to Send.
+func (b *Bridge) Handler(secret string) http.Handler {
+	return webhook.Handler(secret, b.Send)
+}
+
+// Send translates an event into an alert and posts it to Alertmanager. A
+// recovery resolves every alert fired for the same site and check, or both
+// the warning and critical alerts for them if none is known. Events that
+// don't imply a check result are ignored.
+func (b *Bridge) Send(ctx context.Context, event *webhook.Event) error {
+	alert, ok := Translate(event, time.Now(), &b.opts)
+	if !ok {
+		return nil
+	}
+
+	key := strconv.Itoa(event.Site.ID) + ":" + string(event.CheckType)
+
+	b.mu.Lock()
+
+	alerts := []*Alert{alert}
+
+	switch active := b.active[key]; {
+	case event.Result != ohdear.CheckResultSucceeded:
+	case len(active) == 0:
+		// No alert is known, such as after a restart, so the alert is
+		// resolved with every severity it may have fired with, as the
+		// severity is part of its fingerprint.
+		alerts = append(alerts, withSeverity(alert, SeverityWarning))
+	default:
+		fingerprints := make([]string, 0, len(active))
+
+		for fingerprint := range active {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+
+		sort.Strings(fingerprints)
+
+		alerts = alerts[:0]
+
+		for _, fingerprint := range fingerprints {
+			resolved := *active[fingerprint]
+			resolved.EndsAt = alert.EndsAt
+			alerts = append(alerts, &resolved)
+		}
+	}
+
+	b.mu.Unlock()
+
+	if err := Post(ctx, b.opts.Client, b.url, alerts); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.Result == ohdear.CheckResultSucceeded {
+		delete(b.active, key)
+
+		return nil
+	}
+
+	if b.active[key] == nil {
+		b.active[key] = make(map[string]*Alert)
+	}
+
+	b.active[key][alert.Fingerprint()] = alert
+
+	return nil
+}
+
+// withSeverity returns a copy of alert with the given severity.
+func withSeverity(alert *Alert, severity string) *Alert {
+	c := *alert
+	c.Labels = make(map[string]string, len(alert.Labels))
+
+	for name, value := range alert.Labels {
+		c.Labels[name] = value
+	}
+
+	c.Labels[LabelSeverity] = severity
+
+	return &c
+}
+
+// resultURL returns the URL of the check result linked to by the event, if
+// any.
+func resultURL(event *webhook.Event) string {
+	var payload struct {
+		ResultURL string `json:"resultUrl"`
+	}
+
+	if len(event.Payload) == 0 || json.Unmarshal(event.Payload, &payload) != nil {
+		return ""
+	}
+
+	return payload.ResultURL
+}
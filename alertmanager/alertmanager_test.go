@@ -0,0 +1,334 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/alertmanager"
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+)
+
+const _testSecret string = "s3cr3t"
+
+// fakeAlertmanager records the alerts posted to it.
+type fakeAlertmanager struct {
+	posts [][]alertmanager.Alert
+	mu    sync.Mutex
+}
+
+func (f *fakeAlertmanager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/api/v2/alerts" {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	var alerts []alertmanager.Alert
+	if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	f.posts = append(f.posts, alerts)
+	f.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeAlertmanager) last() []alertmanager.Alert {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.posts) == 0 {
+		return nil
+	}
+
+	return f.posts[len(f.posts)-1]
+}
+
+// webhookBody returns the body of a webhook of the given type about a tagged
+// site.
+func webhookBody(typ string) string {
+	return `{
+		"type": "` + typ + `",
+		"dateTime": "20240102030405",
+		"payload": {
+			"site": {"id": 7, "url": "https://example.com", "team_id": 3, "tags": ["production", "eu"]},
+			"resultUrl": "https://ohdear.app/sites/7/checks/1/report"
+		}
+	}`
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.January, 2, 4, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		typ          string
+		wantOK       bool
+		wantSeverity string
+		wantEndsAt   time.Time
+	}{
+		{
+			name:         "Failure",
+			typ:          "uptimeCheckFailedNotification",
+			wantOK:       true,
+			wantSeverity: alertmanager.SeverityCritical,
+			wantEndsAt:   now.Add(alertmanager.DefaultAlertTTL),
+		},
+		{
+			name:         "Warning",
+			typ:          "certificateExpiresSoonNotification",
+			wantOK:       true,
+			wantSeverity: alertmanager.SeverityWarning,
+			wantEndsAt:   now.Add(alertmanager.DefaultAlertTTL),
+		},
+		{
+			name:         "Recovery",
+			typ:          "uptimeCheckRecoveredNotification",
+			wantOK:       true,
+			wantSeverity: alertmanager.SeverityCritical,
+			wantEndsAt:   now,
+		},
+		{
+			name: "No result",
+			typ:  "siteAddedNotification",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := webhook.Parse([]byte(webhookBody(tt.typ)))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			alert, ok := alertmanager.Translate(event, now, &alertmanager.Options{
+				Labels: map[string]string{"env": "prod", alertmanager.LabelSiteID: "overridden"},
+			})
+			if ok != tt.wantOK {
+				t.Fatalf("Translate() ok = %t, want %t", ok, tt.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			want := map[string]string{
+				alertmanager.LabelAlertName: alertmanager.DefaultAlertName,
+				alertmanager.LabelSiteID:    "7",
+				alertmanager.LabelSiteURL:   "https://example.com",
+				alertmanager.LabelSiteName:  "https://example.com",
+				alertmanager.LabelCheckType: string(event.CheckType),
+				alertmanager.LabelTeam:      "3",
+				alertmanager.LabelTags:      ",production,eu,",
+				alertmanager.LabelSeverity:  tt.wantSeverity,
+				"env":                       "prod",
+			}
+
+			for name, value := range want {
+				if alert.Labels[name] != value {
+					t.Errorf("label %s = %q, want %q", name, alert.Labels[name], value)
+				}
+			}
+
+			if !alert.EndsAt.Equal(tt.wantEndsAt) {
+				t.Errorf("EndsAt = %v, want %v", alert.EndsAt, tt.wantEndsAt)
+			}
+
+			if alert.GeneratorURL != "https://ohdear.app/sites/7/checks/1/report" {
+				t.Errorf("GeneratorURL = %q, want the result URL", alert.GeneratorURL)
+			}
+		})
+	}
+}
+
+func TestAlert_Fingerprint(t *testing.T) {
+	t.Parallel()
+
+	// The fingerprint of an empty label set is the FNV-1a offset basis, as
+	// in Alertmanager.
+	empty := &alertmanager.Alert{}
+	if got := empty.Fingerprint(); got != "cbf29ce484222325" {
+		t.Errorf("Fingerprint() of empty labels = %s, want cbf29ce484222325", got)
+	}
+
+	a := &alertmanager.Alert{Labels: map[string]string{"a": "1", "b": "2"}}
+	b := &alertmanager.Alert{Labels: map[string]string{"b": "2", "a": "1"}, Annotations: map[string]string{"summary": "x"}}
+	c := &alertmanager.Alert{Labels: map[string]string{"a": "1", "b": "3"}}
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("Fingerprint() differs for the same labels")
+	}
+
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Error("Fingerprint() is the same for different labels")
+	}
+}
+
+func TestBridge(t *testing.T) {
+	t.Parallel()
+
+	am := &fakeAlertmanager{}
+
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+
+	bridge, err := alertmanager.New(srv.URL, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handler := bridge.Handler(_testSecret)
+
+	send := func(typ string) {
+		t.Helper()
+
+		body := webhookBody(typ)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign([]byte(body), _testSecret))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("%s: status = %d, want %d", typ, rec.Code, http.StatusNoContent)
+		}
+	}
+
+	// A warning that turns into a failure fires two alerts with different
+	// severities.
+	send("certificateExpiresSoonNotification")
+	warning := am.last()[0]
+
+	send("certificateHealthCheckFailedNotification")
+	failure := am.last()[0]
+
+	if warning.Fingerprint() == failure.Fingerprint() {
+		t.Fatal("warning and failure alerts share a fingerprint")
+	}
+
+	send("certificateHealthCheckRecoveredNotification")
+	resolved := am.last()
+
+	if len(resolved) != 2 {
+		t.Fatalf("recovery posted %d alerts, want 2", len(resolved))
+	}
+
+	fingerprints := map[string]bool{
+		warning.Fingerprint(): true,
+		failure.Fingerprint(): true,
+	}
+
+	for _, alert := range resolved {
+		if !fingerprints[alert.Fingerprint()] {
+			t.Errorf("resolved alert %v doesn't match a fired alert", alert.Labels)
+		}
+
+		if alert.EndsAt.After(time.Now()) {
+			t.Errorf("resolved alert ends at %v, want it in the past", alert.EndsAt)
+		}
+	}
+
+	if resolved[0].Labels[alertmanager.LabelCheckType] != string(ohdear.CheckTypeCertificateHealth) {
+		t.Errorf("check type label = %q, want %q", resolved[0].Labels[alertmanager.LabelCheckType], ohdear.CheckTypeCertificateHealth)
+	}
+}
+
+func TestBridge_RecoveryAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	am := &fakeAlertmanager{}
+
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+
+	send := func(bridge *alertmanager.Bridge, typ string) {
+		t.Helper()
+
+		event, err := webhook.Parse([]byte(webhookBody(typ)))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if err = bridge.Send(context.Background(), event); err != nil {
+			t.Fatalf("%s: Send() error = %v", typ, err)
+		}
+	}
+
+	before, err := alertmanager.New(srv.URL, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	send(before, "certificateExpiresSoonNotification")
+	warning := am.last()[0]
+
+	// A fresh bridge doesn't know the warning, as after a restart.
+	after, err := alertmanager.New(srv.URL, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	send(after, "certificateHealthCheckRecoveredNotification")
+
+	var found bool
+
+	for _, alert := range am.last() {
+		if alert.Fingerprint() == warning.Fingerprint() {
+			found = true
+
+			if alert.EndsAt.After(time.Now()) {
+				t.Errorf("resolved warning ends at %v, want it in the past", alert.EndsAt)
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("recovery posted %v, want it to resolve the warning %v", am.last(), warning.Labels)
+	}
+}
+
+func TestBridge_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := alertmanager.New("", nil); !errors.Is(err, alertmanager.ErrURLRequired) {
+		t.Errorf("New(\"\") error = %v, want %v", err, alertmanager.ErrURLRequired)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	bridge, err := alertmanager.New(srv.URL, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	event, err := webhook.Parse([]byte(webhookBody("uptimeCheckFailedNotification")))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if err = bridge.Send(context.Background(), event); !errors.Is(err, alertmanager.ErrUnexpectedStatus) {
+		t.Errorf("Send() error = %v, want %v", err, alertmanager.ErrUnexpectedStatus)
+	}
+}
@@ -0,0 +1,472 @@
+package alertmanager
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrClientRequired is returned when a StatusPageHandler is created
+	// without an Oh Dear client.
+	ErrClientRequired xerrors.Error = "client cannot be nil"
+
+	// ErrStatusPageIDRequired is returned when a status page route has no
+	// status page ID.
+	ErrStatusPageIDRequired xerrors.Error = "status page ID cannot be zero"
+
+	// ErrInvalidTemplate is returned when a title or text template cannot be
+	// parsed.
+	ErrInvalidTemplate xerrors.Error = "invalid template"
+)
+
+// Values of the status of an alert or webhook message.
+const (
+	StatusFiring   string = "firing"
+	StatusResolved string = "resolved"
+)
+
+// Default values for the StatusPageOptions struct.
+const (
+	// DefaultStatusPageLabel is the label whose value is the ID of the status
+	// page an alert is posted to.
+	DefaultStatusPageLabel string = "ohdear_status_page"
+
+	// DefaultTitleTemplate is the template of the title of status page
+	// updates.
+	DefaultTitleTemplate string = `{{ if eq .Status "resolved" }}Resolved: {{ end }}{{ or .Annotations.summary .Labels.alertname }}`
+
+	// DefaultTextTemplate is the template of the text of status page updates.
+	DefaultTextTemplate string = `{{ if eq .Status "resolved" }}This issue has been resolved.{{ else }}{{ or .Annotations.description .Annotations.summary }}{{ end }}`
+)
+
+// _maxPayloadSize is the largest webhook payload accepted from Alertmanager.
+const _maxPayloadSize int64 = 4 << 20
+
+// _resolvedTTL is how long resolved alerts are remembered, so repeated
+// notifications about them don't post the resolution twice.
+const _resolvedTTL time.Duration = 24 * time.Hour
+
+// WebhookMessage is the payload sent by the webhook receiver of Alertmanager.
+type WebhookMessage struct {
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []WebhookAlert    `json:"alerts"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+}
+
+// WebhookAlert is an alert in a WebhookMessage.
+type WebhookAlert struct {
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	Status       string            `json:"status"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// TemplateData is the data the title and text templates are executed with.
+// The fields of the alert are available directly, such as
+// {{ .Annotations.summary }}, and missing labels or annotations are empty.
+type TemplateData struct {
+	WebhookAlert
+
+	// Receiver is the name of the Alertmanager receiver the alert was sent
+	// to.
+	Receiver string
+
+	// ExternalURL is the URL of the Alertmanager that sent the alert.
+	ExternalURL string
+}
+
+// StatusPageRoute posts the alerts whose labels match to a status page.
+type StatusPageRoute struct {
+	// Matchers are the labels an alert must have, with the same values, to
+	// match the route.
+	//
+	// This field is optional. A route without matchers matches every alert.
+	Matchers map[string]string
+
+	// Severity is the severity of the updates announcing the alerts matching
+	// the route.
+	//
+	// This field is optional. It defaults to the severity derived from the
+	// severity label of the alert: critical alerts are high, warnings are
+	// warnings, and everything else is info.
+	Severity ohdear.StatusPageSeverity
+
+	// StatusPageID is the ID of the status page alerts are posted to.
+	StatusPageID uint
+}
+
+// Matches returns true if every matcher of the route is set on labels.
+func (r *StatusPageRoute) Matches(labels map[string]string) bool {
+	for name, value := range r.Matchers {
+		if got, ok := labels[name]; !ok || got != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// StatusPageOptions holds the configuration for a StatusPageHandler.
+type StatusPageOptions struct {
+	// RequestOptions are applied to every request made to Oh Dear.
+	//
+	// This field is optional.
+	RequestOptions []ohdear.RequestOption
+
+	// Routes maps alerts to status pages by their labels. An alert matching
+	// several routes is posted to each of their status pages.
+	//
+	// This field is optional.
+	Routes []StatusPageRoute
+
+	// Label is the label whose value is the ID of the status page an alert
+	// is posted to, in addition to the status pages of the matching routes.
+	// Values that aren't a valid ID are ignored.
+	//
+	// This field is optional. It defaults to DefaultStatusPageLabel.
+	Label string
+
+	// Title is the text/template template of the title of updates.
+	//
+	// This field is optional. It defaults to DefaultTitleTemplate.
+	Title string
+
+	// Text is the text/template template of the text of updates.
+	//
+	// This field is optional. It defaults to DefaultTextTemplate.
+	Text string
+
+	// Token is the bearer token Alertmanager must send in the Authorization
+	// header, as set in the authorization section of its http_config.
+	//
+	// This field is optional. Requests aren't authenticated if it's empty.
+	Token string
+}
+
+// StatusPageHandler is an http.Handler accepting the payload of the webhook
+// receiver of Alertmanager and posting the alerts to Oh Dear status pages.
+// Firing alerts announce an incident, and resolved alerts post a resolved
+// update. It's safe for concurrent use.
+type StatusPageHandler struct {
+	client *ohdear.Client
+	title  *template.Template
+	text   *template.Template
+
+	// posted holds the last update posted for each alert, keyed by status
+	// page and fingerprint, so repeated notifications aren't posted again.
+	posted map[string]postedUpdate
+
+	opts StatusPageOptions
+
+	// mu protects posted.
+	mu sync.Mutex
+}
+
+// postedUpdate is the status of the last update posted about an alert.
+type postedUpdate struct {
+	at     time.Time
+	status string
+}
+
+// Compile-time check to ensure StatusPageHandler implements http.Handler.
+var _ http.Handler = (*StatusPageHandler)(nil)
+
+// NewStatusPageHandler returns a new StatusPageHandler posting updates with
+// client.
+func NewStatusPageHandler(client *ohdear.Client, opts *StatusPageOptions) (*StatusPageHandler, error) {
+	if client == nil {
+		return nil, ErrClientRequired
+	}
+
+	h := &StatusPageHandler{
+		client: client,
+		posted: make(map[string]postedUpdate),
+	}
+
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	if h.opts.Label == "" {
+		h.opts.Label = DefaultStatusPageLabel
+	}
+
+	if h.opts.Title == "" {
+		h.opts.Title = DefaultTitleTemplate
+	}
+
+	if h.opts.Text == "" {
+		h.opts.Text = DefaultTextTemplate
+	}
+
+	for i := range h.opts.Routes {
+		if h.opts.Routes[i].StatusPageID == 0 {
+			return nil, fmt.Errorf("route %d: %w", i, ErrStatusPageIDRequired)
+		}
+	}
+
+	var err error
+
+	if h.title, err = parseTemplate("title", h.opts.Title); err != nil {
+		return nil, err
+	}
+
+	if h.text, err = parseTemplate("text", h.opts.Text); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// ServeHTTP implements the http.Handler interface. It responds with 204 when
+// every alert was handled and with 500 otherwise, so Alertmanager retries the
+// notification.
+func (h *StatusPageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.opts.Token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.Token)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, _maxPayloadSize))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	var msg WebhookMessage
+	if err = json.Unmarshal(body, &msg); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	if err = h.Notify(r.Context(), &msg); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Notify posts an update for every alert of msg to the status pages it's
+// routed to. Alerts whose status didn't change since the last update about
+// them are skipped. Errors don't stop the other alerts from being posted.
+func (h *StatusPageHandler) Notify(ctx context.Context, msg *WebhookMessage) error {
+	var errs []error
+
+	h.prune(time.Now())
+
+	for i := range msg.Alerts {
+		alert := &msg.Alerts[i]
+
+		fingerprint := alert.Fingerprint
+		if fingerprint == "" {
+			fingerprint = (&Alert{Labels: alert.Labels}).Fingerprint()
+		}
+
+		status := alert.Status
+		if status == "" {
+			status = msg.Status
+		}
+
+		data := &TemplateData{
+			WebhookAlert: *alert,
+			Receiver:     msg.Receiver,
+			ExternalURL:  msg.ExternalURL,
+		}
+
+		data.Status = status
+
+		for _, target := range h.targets(alert.Labels) {
+			key := strconv.FormatUint(uint64(target.id), 10) + ":" + fingerprint
+
+			h.mu.Lock()
+			last, ok := h.posted[key]
+			h.mu.Unlock()
+
+			if ok && last.status == status {
+				continue
+			}
+
+			if err := h.post(ctx, target, data); err != nil {
+				errs = append(errs, fmt.Errorf("status page %d: %w", target.id, err))
+
+				continue
+			}
+
+			h.mu.Lock()
+			h.posted[key] = postedUpdate{at: time.Now(), status: status}
+			h.mu.Unlock()
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// target is a status page an alert is posted to.
+type target struct {
+	severity ohdear.StatusPageSeverity
+	id       uint
+}
+
+// targets returns the status pages an alert with the given labels is posted
+// to, sorted by ID.
+func (h *StatusPageHandler) targets(labels map[string]string) []target {
+	targets := make(map[uint]ohdear.StatusPageSeverity)
+
+	if value, ok := labels[h.opts.Label]; ok {
+		if id, err := strconv.ParseUint(value, 10, 0); err == nil && id > 0 {
+			targets[uint(id)] = ""
+		}
+	}
+
+	for i := range h.opts.Routes {
+		route := &h.opts.Routes[i]
+
+		if !route.Matches(labels) {
+			continue
+		}
+
+		if severity, ok := targets[route.StatusPageID]; !ok || severity == "" {
+			targets[route.StatusPageID] = route.Severity
+		}
+	}
+
+	ret := make([]target, 0, len(targets))
+
+	for id, severity := range targets {
+		if severity == "" {
+			severity = severityOf(labels)
+		}
+
+		ret = append(ret, target{id: id, severity: severity})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].id < ret[j].id
+	})
+
+	return ret
+}
+
+// post posts an update about an alert to a status page.
+func (h *StatusPageHandler) post(ctx context.Context, t target, data *TemplateData) error {
+	title, err := execute(h.title, data)
+	if err != nil {
+		return err
+	}
+
+	text, err := execute(h.text, data)
+	if err != nil {
+		return err
+	}
+
+	update := &ohdear.StatusPageUpdate{
+		Title:        title,
+		Text:         text,
+		Severity:     t.severity,
+		StatusPageID: int(t.id),
+	}
+
+	at := data.StartsAt
+
+	if data.Status == StatusResolved {
+		update.Severity = ohdear.StatusPageSeverityResolved
+		at = data.EndsAt
+	}
+
+	if !at.IsZero() {
+		update.Time = jsonutil.Time{Time: at}
+	}
+
+	if _, _, err = h.client.StatusPages.AddUpdate(ctx, update, h.opts.RequestOptions...); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// prune forgets the alerts resolved longer than _resolvedTTL ago.
+func (h *StatusPageHandler) prune(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, last := range h.posted {
+		if last.status == StatusResolved && now.Sub(last.at) > _resolvedTTL {
+			delete(h.posted, key)
+		}
+	}
+}
+
+// severityOf returns the severity of the update announcing an alert with the
+// given labels.
+func severityOf(labels map[string]string) ohdear.StatusPageSeverity {
+	switch labels[LabelSeverity] {
+	case SeverityCritical:
+		return ohdear.StatusPageSeverityHigh
+	case SeverityWarning:
+		return ohdear.StatusPageSeverityWarning
+	default:
+		return ohdear.StatusPageSeverityInfo
+	}
+}
+
+// parseTemplate parses a title or text template. Missing labels and
+// annotations are rendered as empty strings.
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	return tmpl, nil
+}
+
+// execute executes a template and trims the result.
+func execute(tmpl *template.Template, data *TemplateData) (string, error) {
+	var b strings.Builder
+
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("could not execute %s template: %w", tmpl.Name(), err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
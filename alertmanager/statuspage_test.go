@@ -0,0 +1,237 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/alertmanager"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+// addStatusPage adds a status page to the fake server and returns its ID.
+func addStatusPage(t *testing.T, client *ohdear.Client) uint {
+	t.Helper()
+
+	statusPage, _, err := client.StatusPages.Add(context.Background(), &ohdear.StatusPage{
+		Title:  "Example",
+		TeamID: 1,
+	})
+	if err != nil {
+		t.Fatalf("StatusPages.Add() error = %v", err)
+	}
+
+	return uint(statusPage.ID)
+}
+
+// notify sends a webhook message to handler and returns the status code.
+func notify(t *testing.T, handler http.Handler, token string, msg *alertmanager.WebhookMessage) int {
+	t.Helper()
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/alertmanager", strings.NewReader(string(body)))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestStatusPageHandler(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	client := srv.Client()
+
+	var (
+		labeled = addStatusPage(t, client)
+		routed  = addStatusPage(t, client)
+	)
+
+	handler, err := alertmanager.NewStatusPageHandler(client, &alertmanager.StatusPageOptions{
+		Routes: []alertmanager.StatusPageRoute{
+			{
+				Matchers:     map[string]string{"team": "jobs"},
+				Severity:     ohdear.StatusPageSeverityWarning,
+				StatusPageID: routed,
+			},
+		},
+		Text:  "{{ .Annotations.description }} ({{ .Labels.queue }})",
+		Token: "t0k3n",
+	})
+	if err != nil {
+		t.Fatalf("NewStatusPageHandler() error = %v", err)
+	}
+
+	startsAt := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	alert := alertmanager.WebhookAlert{
+		StartsAt: startsAt,
+		Labels: map[string]string{
+			"alertname":                         "JobsDegraded",
+			"team":                              "jobs",
+			"queue":                             "mail",
+			alertmanager.LabelSeverity:          alertmanager.SeverityCritical,
+			alertmanager.DefaultStatusPageLabel: strconv.Itoa(int(labeled)),
+		},
+		Annotations: map[string]string{
+			"summary":     "Emails are delayed",
+			"description": "Outgoing emails are sent late.",
+		},
+		Status: alertmanager.StatusFiring,
+	}
+
+	msg := &alertmanager.WebhookMessage{
+		Status: alertmanager.StatusFiring,
+		Alerts: []alertmanager.WebhookAlert{alert},
+	}
+
+	if code := notify(t, handler, "wrong", msg); code != http.StatusUnauthorized {
+		t.Fatalf("status with a wrong token = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	// Alertmanager repeats notifications about alerts still firing, which
+	// must not be posted twice.
+	for i := 0; i < 2; i++ {
+		if code := notify(t, handler, "t0k3n", msg); code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", code, http.StatusNoContent)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		statusPageID uint
+		wantSeverity string
+	}{
+		{
+			name:         "Label",
+			statusPageID: labeled,
+			wantSeverity: string(ohdear.StatusPageSeverityHigh),
+		},
+		{
+			name:         "Route",
+			statusPageID: routed,
+			wantSeverity: string(ohdear.StatusPageSeverityWarning),
+		},
+	}
+
+	for _, tt := range tests {
+		updates := srv.StatusPageUpdates(int(tt.statusPageID))
+		if len(updates) != 1 {
+			t.Fatalf("%s: %d updates, want 1", tt.name, len(updates))
+		}
+
+		got := updates[0]
+
+		if got.Title != "Emails are delayed" || got.Text != "Outgoing emails are sent late. (mail)" {
+			t.Errorf("%s: update = %q, %q, want the rendered templates", tt.name, got.Title, got.Text)
+		}
+
+		if got.Severity != tt.wantSeverity {
+			t.Errorf("%s: severity = %q, want %q", tt.name, got.Severity, tt.wantSeverity)
+		}
+
+		if !got.Time.Equal(startsAt) {
+			t.Errorf("%s: time = %v, want %v", tt.name, got.Time, startsAt)
+		}
+	}
+
+	alert.Status = alertmanager.StatusResolved
+	alert.EndsAt = startsAt.Add(time.Hour)
+
+	msg = &alertmanager.WebhookMessage{
+		Status: alertmanager.StatusResolved,
+		Alerts: []alertmanager.WebhookAlert{alert},
+	}
+
+	if code := notify(t, handler, "t0k3n", msg); code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", code, http.StatusNoContent)
+	}
+
+	for _, tt := range tests {
+		updates := srv.StatusPageUpdates(int(tt.statusPageID))
+		if len(updates) != 2 {
+			t.Fatalf("%s: %d updates after resolving, want 2", tt.name, len(updates))
+		}
+
+		got := updates[0]
+
+		if got.Severity != string(ohdear.StatusPageSeverityResolved) || got.Title != "Resolved: Emails are delayed" {
+			t.Errorf("%s: update = %+v, want a resolved update", tt.name, got)
+		}
+	}
+}
+
+func TestStatusPageHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	client := srv.Client()
+
+	if _, err := alertmanager.NewStatusPageHandler(nil, nil); !errors.Is(err, alertmanager.ErrClientRequired) {
+		t.Errorf("NewStatusPageHandler(nil) error = %v, want %v", err, alertmanager.ErrClientRequired)
+	}
+
+	_, err := alertmanager.NewStatusPageHandler(client, &alertmanager.StatusPageOptions{
+		Routes: []alertmanager.StatusPageRoute{{}},
+	})
+	if !errors.Is(err, alertmanager.ErrStatusPageIDRequired) {
+		t.Errorf("NewStatusPageHandler() error = %v, want %v", err, alertmanager.ErrStatusPageIDRequired)
+	}
+
+	_, err = alertmanager.NewStatusPageHandler(client, &alertmanager.StatusPageOptions{
+		Title: "{{ .Labels",
+	})
+	if !errors.Is(err, alertmanager.ErrInvalidTemplate) {
+		t.Errorf("NewStatusPageHandler() error = %v, want %v", err, alertmanager.ErrInvalidTemplate)
+	}
+
+	handler, err := alertmanager.NewStatusPageHandler(client, nil)
+	if err != nil {
+		t.Fatalf("NewStatusPageHandler() error = %v", err)
+	}
+
+	// The status page doesn't exist, so Oh Dear rejects the update and
+	// Alertmanager must retry.
+	msg := &alertmanager.WebhookMessage{
+		Status: alertmanager.StatusFiring,
+		Alerts: []alertmanager.WebhookAlert{
+			{
+				Labels: map[string]string{
+					"alertname":                         "JobsDegraded",
+					alertmanager.DefaultStatusPageLabel: "999",
+				},
+			},
+		},
+	}
+
+	if code := notify(t, handler, "", msg); code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", code, http.StatusInternalServerError)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/alertmanager", strings.NewReader("{"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status with an invalid payload = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
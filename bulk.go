@@ -0,0 +1,128 @@
+package ohdear
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBulkWorkers is the default number of concurrent workers used by bulk
+// operations.
+const DefaultBulkWorkers int = 4
+
+// BulkOptions holds the configuration for bulk operations, such as
+// SitesService.AddMany.
+//
+// Bulk operations go through the client like any other call, so they're
+// paced by Config.RateLimiter and requests rejected with 429 Too Many
+// Requests are retried. Setting a RateLimiter is recommended for large
+// batches.
+type BulkOptions struct {
+	// Workers is the maximum number of requests in flight at the same time.
+	//
+	// This field is optional. It defaults to DefaultBulkWorkers.
+	Workers int
+}
+
+// BulkResult is the outcome of a single item of a bulk operation.
+type BulkResult[T any] struct {
+	// Value is the value returned for the item, if it succeeded.
+	Value T
+
+	// Response is the response returned by the API for the item, if any.
+	Response *Response
+
+	// Err is the error returned for the item, if it failed.
+	Err error
+}
+
+// BulkResults holds the outcome of every item of a bulk operation, in the same
+// order as the items given to it.
+type BulkResults[T any] []BulkResult[T]
+
+// Failed returns the number of items that failed.
+func (r BulkResults[T]) Failed() int {
+	var failed int
+
+	for i := range r {
+		if r[i].Err != nil {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// Err returns an error joining the errors of every item that failed, each
+// wrapped with the index of its item, or nil if every item succeeded.
+func (r BulkResults[T]) Err() error {
+	var errs []error
+
+	for i := range r {
+		if r[i].Err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", i, r[i].Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runBulk calls fn for every item using a bounded number of workers, and
+// collects every result instead of stopping at the first error. Items not yet
+// started when ctx is done fail with the context's error.
+func runBulk[In, Out any](
+	ctx context.Context,
+	items []In,
+	opts *BulkOptions,
+	fn func(ctx context.Context, item In) (Out, *Response, error),
+) BulkResults[Out] {
+	results := make(BulkResults[Out], len(items))
+
+	if ctx == nil {
+		for i := range results {
+			results[i].Err = ErrNilContext
+		}
+
+		return results
+	}
+
+	workers := DefaultBulkWorkers
+	if opts != nil && opts.Workers > 0 {
+		workers = opts.Workers
+	}
+
+	workers = min(workers, len(items))
+
+	var (
+		jobs = make(chan int)
+		wg   sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = fmt.Errorf("%w", err)
+
+					continue
+				}
+
+				results[i].Value, results[i].Response, results[i].Err = fn(ctx, items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,113 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+func TestSitesService_Bulk(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		opts = &ohdear.BulkOptions{Workers: 3}
+	)
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	client := srv.Client()
+
+	sites := make([]*ohdear.Site, 10)
+	for i := range sites {
+		sites[i] = &ohdear.Site{
+			URL:    fmt.Sprintf("https://%d.example.com", i),
+			TeamID: 1,
+		}
+	}
+
+	// The API rejects sites without a team.
+	sites[4].TeamID = 0
+
+	added := client.Sites.AddMany(ctx, sites, opts)
+
+	if len(added) != len(sites) {
+		t.Fatalf("AddMany() returned %d results, want %d", len(added), len(sites))
+	}
+
+	if added.Failed() != 1 || !errors.Is(added[4].Err, ohdear.ErrInvalidTeamID) {
+		t.Fatalf("AddMany() error = %v, want only item 4 to fail", added.Err())
+	}
+
+	ids := make([]uint, 0, len(added))
+
+	for i, result := range added {
+		if result.Err != nil {
+			continue
+		}
+
+		if result.Value.URL != sites[i].URL {
+			t.Errorf("AddMany() result %d URL = %q, want %q", i, result.Value.URL, sites[i].URL)
+		}
+
+		ids = append(ids, uint(result.Value.ID))
+	}
+
+	got := client.Sites.GetMany(ctx, append(ids, 9999), opts)
+
+	var apiErr *ohdear.APIError
+	if got.Failed() != 1 || !errors.As(got[len(ids)].Err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Errorf("GetMany() error = %v, want only the unknown site to fail with 404", got.Err())
+	}
+
+	removed := client.Sites.RemoveMany(ctx, ids, opts)
+	if err := removed.Err(); err != nil {
+		t.Fatalf("RemoveMany() error = %v", err)
+	}
+
+	if removed[0].Value != ids[0] {
+		t.Errorf("RemoveMany() result 0 value = %d, want %d", removed[0].Value, ids[0])
+	}
+
+	if n := len(srv.Sites()); n != 0 {
+		t.Errorf("server has %d sites after RemoveMany(), want 0", n)
+	}
+}
+
+func TestChecksService_RequestRunMany(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	client := srv.Client()
+
+	var ids []uint
+
+	for _, url := range []string{"https://a.example.com", "https://b.example.com"} {
+		site := srv.AddSite(&ohdear.Site{URL: url, TeamID: 1})
+
+		for _, check := range site.Checks {
+			ids = append(ids, uint(check.ID))
+		}
+	}
+
+	results := client.Checks.RequestRunMany(context.Background(), ids, nil)
+	if err := results.Err(); err != nil {
+		t.Fatalf("RequestRunMany() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results = client.Checks.RequestRunMany(ctx, ids, nil)
+	if results.Failed() != len(ids) || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("RequestRunMany() with canceled context error = %v, want %v", results.Err(), context.Canceled)
+	}
+}
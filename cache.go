@@ -0,0 +1,126 @@
+package ohdear
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrCacheMiss is returned by a Cache when no entry exists for a given key.
+const ErrCacheMiss xerrors.Error = "cache miss"
+
+// Cache defines the interface for storing responses to GET requests.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry stored under key, or ErrCacheMiss if there is
+	// none.
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+
+	// Set stores entry under key, replacing any existing entry.
+	Set(ctx context.Context, key string, entry *CacheEntry) error
+
+	// Delete removes the entry stored under key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheEntry represents a response stored in a Cache.
+type CacheEntry struct {
+	// ExpiresAt is the moment after which the entry must be revalidated with
+	// the API before being used.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Header contains the response headers.
+	Header http.Header `json:"header"`
+
+	// ETag is the entity tag returned by the API, if any. It's used to make
+	// conditional requests once the entry expires.
+	ETag string `json:"etag,omitempty"`
+
+	// Body contains the response body.
+	Body []byte `json:"body"`
+
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+}
+
+// Fresh checks if the entry can be used without revalidating it with the API.
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// response returns the entry as a Response.
+func (e *CacheEntry) response() *Response {
+	return &Response{
+		Header: e.Header.Clone(),
+		Body:   e.Body,
+		Status: e.Status,
+		Cached: true,
+	}
+}
+
+// CachePolicy defines how long responses are cached for each endpoint.
+type CachePolicy struct {
+	// TTLs holds the time to live of responses for each endpoint, keyed by
+	// the endpoint path relative to the base URL, such as "/sites". The
+	// longest matching path wins, so "/sites/1" can override "/sites".
+	//
+	// This field is optional.
+	TTLs map[string]time.Duration
+
+	// DefaultTTL is the time to live of responses for endpoints not present
+	// in TTLs.
+	//
+	// This field is optional.
+	DefaultTTL time.Duration
+}
+
+// DefaultCachePolicy returns a CachePolicy that caches every response for
+// DefaultCacheTTL.
+func DefaultCachePolicy() *CachePolicy {
+	return &CachePolicy{
+		DefaultTTL: DefaultCacheTTL,
+	}
+}
+
+// TTL returns the time to live of responses for the given endpoint path.
+func (p *CachePolicy) TTL(path string) time.Duration {
+	var (
+		ttl     = p.DefaultTTL
+		longest = -1
+	)
+
+	for prefix, d := range p.TTLs {
+		if !matchesEndpoint(path, prefix) || len(prefix) <= longest {
+			continue
+		}
+
+		ttl = d
+		longest = len(prefix)
+	}
+
+	return ttl
+}
+
+// matchesEndpoint checks if path is prefix or one of its sub-paths.
+func matchesEndpoint(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// cacheKey returns the key under which the response to req is cached. It
+// includes a hash of the credentials so clients using different API keys never
+// share entries.
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+
+	return http.MethodGet + " " + req.URL.String() + " " + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,112 @@
+package ohdear
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrCacheDirRequired is returned when a DiskCache is created without a
+// directory.
+const ErrCacheDirRequired xerrors.Error = "cache directory required"
+
+// DiskCache is a Cache that stores each entry as a JSON file in a directory,
+// so cached responses survive restarts.
+type DiskCache struct {
+	// dir is the directory where entries are stored.
+	dir string
+}
+
+// Compile-time check to ensure DiskCache implements Cache.
+var _ Cache = (*DiskCache)(nil)
+
+// NewDiskCache returns a new DiskCache storing entries in dir, creating the
+// directory if it doesn't exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if dir == "" {
+		return nil, ErrCacheDirRequired
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	return &DiskCache{
+		dir: dir,
+	}, nil
+}
+
+// Get implements the Cache interface.
+func (c *DiskCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrCacheMiss
+		}
+
+		return nil, fmt.Errorf("could not read cache entry: %w", err)
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("could not unmarshal cache entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// Set implements the Cache interface.
+func (c *DiskCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not marshal cache entry: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial entry.
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("could not create cache entry: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("could not write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Delete implements the Cache interface.
+func (c *DiskCache) Delete(_ context.Context, key string) error {
+	if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not delete cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// path returns the path of the file holding the entry stored under key.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
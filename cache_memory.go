@@ -0,0 +1,116 @@
+package ohdear
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// DefaultCacheCapacity is the default number of entries held by a MemoryCache.
+const DefaultCacheCapacity int = 512
+
+// memoryCacheItem is an item stored in the list of a MemoryCache.
+type memoryCacheItem struct {
+	entry *CacheEntry
+	key   string
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry
+// once it reaches its capacity.
+type MemoryCache struct {
+	// items maps keys to their elements in order.
+	items map[string]*list.Element
+
+	// order holds the entries from most to least recently used.
+	order *list.List
+
+	// capacity is the maximum number of entries held by the cache.
+	capacity int
+
+	// mu protects items and order.
+	mu sync.Mutex
+}
+
+// Compile-time check to ensure MemoryCache implements Cache.
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a new MemoryCache holding up to capacity entries. If
+// capacity is less than one, DefaultCacheCapacity is used.
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity < 1 {
+		capacity = DefaultCacheCapacity
+	}
+
+	return &MemoryCache{
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		capacity: capacity,
+	}
+}
+
+// Get implements the Cache interface.
+func (c *MemoryCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+
+	c.order.MoveToFront(elem)
+
+	item, _ := elem.Value.(*memoryCacheItem)
+
+	return item.entry, nil
+}
+
+// Set implements the Cache interface.
+func (c *MemoryCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		item, _ := elem.Value.(*memoryCacheItem)
+		item.entry = entry
+
+		c.order.MoveToFront(elem)
+
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&memoryCacheItem{
+		entry: entry,
+		key:   key,
+	})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+
+		item, _ := c.order.Remove(oldest).(*memoryCacheItem)
+		delete(c.items, item.key)
+	}
+
+	return nil
+}
+
+// Delete implements the Cache interface.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+
+	return nil
+}
+
+// Len returns the number of entries in the cache.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
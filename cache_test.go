@@ -0,0 +1,281 @@
+package ohdear_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+func TestCachePolicy_TTL(t *testing.T) {
+	t.Parallel()
+
+	policy := &ohdear.CachePolicy{
+		TTLs: map[string]time.Duration{
+			"/sites":   time.Minute,
+			"/sites/1": time.Hour,
+		},
+		DefaultTTL: time.Second,
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want time.Duration
+	}{
+		{
+			name: "Exact match",
+			path: "/sites",
+			want: time.Minute,
+		},
+		{
+			name: "Sub-path match",
+			path: "/sites/2",
+			want: time.Minute,
+		},
+		{
+			name: "Longest match wins",
+			path: "/sites/1",
+			want: time.Hour,
+		},
+		{
+			name: "Partial segment does not match",
+			path: "/sites-archive",
+			want: time.Second,
+		},
+		{
+			name: "No match",
+			path: "/certificate-health/1",
+			want: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := policy.TTL(tt.path); got != tt.want {
+				t.Errorf("TTL(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = context.Background()
+		cache = ohdear.NewMemoryCache(2)
+	)
+
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(ctx, key, &ohdear.CacheEntry{ETag: key}); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	if _, err := cache.Get(ctx, "a"); err != nil {
+		t.Fatalf("Get(a) error = %v", err)
+	}
+
+	if err := cache.Set(ctx, "c", &ohdear.CacheEntry{ETag: "c"}); err != nil {
+		t.Fatalf("Set(c) error = %v", err)
+	}
+
+	if got := cache.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+
+	if _, err := cache.Get(ctx, "b"); !errors.Is(err, ohdear.ErrCacheMiss) {
+		t.Errorf("Get(b) error = %v, want %v", err, ohdear.ErrCacheMiss)
+	}
+
+	entry, err := cache.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get(a) error = %v", err)
+	}
+
+	if entry.ETag != "a" {
+		t.Errorf("Get(a) ETag = %q, want %q", entry.ETag, "a")
+	}
+
+	if err = cache.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete(a) error = %v", err)
+	}
+
+	if _, err = cache.Get(ctx, "a"); !errors.Is(err, ohdear.ErrCacheMiss) {
+		t.Errorf("Get(a) after Delete error = %v, want %v", err, ohdear.ErrCacheMiss)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	cache, err := ohdear.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	if _, err = cache.Get(ctx, "missing"); !errors.Is(err, ohdear.ErrCacheMiss) {
+		t.Errorf("Get(missing) error = %v, want %v", err, ohdear.ErrCacheMiss)
+	}
+
+	want := &ohdear.CacheEntry{
+		ExpiresAt: time.Date(2023, 5, 14, 12, 0, 0, 0, time.UTC),
+		ETag:      `"abc"`,
+		Body:      []byte(`{"id":1}`),
+		Status:    200,
+	}
+
+	if err = cache.Set(ctx, "key", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := cache.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.ETag != want.ETag || string(got.Body) != string(want.Body) || !got.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+
+	if err = cache.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if err = cache.Delete(ctx, "key"); err != nil {
+		t.Errorf("Delete() of missing entry error = %v", err)
+	}
+}
+
+// keyCache is a MemoryCache remembering the key of the last entry set.
+type keyCache struct {
+	*ohdear.MemoryCache
+	key string
+}
+
+func (c *keyCache) Set(ctx context.Context, key string, entry *ohdear.CacheEntry) error {
+	c.key = key
+
+	return c.MemoryCache.Set(ctx, key, entry)
+}
+
+// failingCache is a Cache whose every operation fails.
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) (*ohdear.CacheEntry, error) {
+	return nil, errors.New("disk full")
+}
+
+func (failingCache) Set(context.Context, string, *ohdear.CacheEntry) error {
+	return errors.New("disk full")
+}
+
+func (failingCache) Delete(context.Context, string) error {
+	return errors.New("disk full")
+}
+
+func TestClient_Cache(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"url":"https://example.com"}`)
+	}))
+	defer srv.Close()
+
+	newClient := func(cache ohdear.Cache, handler slog.Handler) *ohdear.Client {
+		t.Helper()
+
+		cfg := ohdear.NewConfig(_testKey, nil)
+		cfg.BaseURL = srv.URL
+		cfg.Cache = cache
+		cfg.LogHandler = handler
+
+		// Entries expire right away, so they're always revalidated.
+		cfg.CachePolicy = &ohdear.CachePolicy{}
+
+		client, err := ohdear.NewClient(cfg)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+
+		return client
+	}
+
+	t.Run("Revalidation", func(t *testing.T) {
+		var (
+			ctx    = context.Background()
+			cache  = &keyCache{MemoryCache: ohdear.NewMemoryCache(0)}
+			client = newClient(cache, nil)
+		)
+
+		if _, _, err := client.Sites.Get(ctx, 1); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		stored, err := cache.Get(ctx, cache.key)
+		if err != nil {
+			t.Fatalf("Cache.Get() error = %v", err)
+		}
+
+		expiresAt := stored.ExpiresAt
+
+		_, ret, err := client.Sites.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if !ret.Cached {
+			t.Error("Get() after a 304 response is not marked as cached")
+		}
+
+		if !stored.ExpiresAt.Equal(expiresAt) {
+			t.Error("revalidation changed an entry shared with other callers")
+		}
+	})
+
+	t.Run("Failing cache", func(t *testing.T) {
+		var (
+			ctx    = context.Background()
+			logs   bytes.Buffer
+			client = newClient(failingCache{}, slog.NewTextHandler(&logs, nil))
+		)
+
+		site, _, err := client.Sites.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if site.ID != 1 {
+			t.Errorf("Get() = %+v, want site 1", site)
+		}
+
+		if !strings.Contains(logs.String(), "disk full") {
+			t.Errorf("logs = %q, want the cache error", logs.String())
+		}
+	})
+}
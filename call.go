@@ -0,0 +1,100 @@
+package ohdear
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// callInfoKey is the context key for CallInfo.
+type callInfoKey struct{}
+
+// CallInfo holds information about a single API call, shared by every layer
+// handling it. Middlewares can retrieve it with CallInfoFromContext.
+type CallInfo struct {
+	// Operation is the name of the service method that made the call, such
+	// as "Sites.Get", or the method and route of the request for calls made
+	// directly through Client.Do, such as "GET /sites/{id}".
+	Operation string
+
+	// attempts is the number of times the request was sent to the API.
+	attempts atomic.Int32
+}
+
+// Attempts returns the number of times the request was sent to the API so
+// far, including retries.
+func (ci *CallInfo) Attempts() int {
+	return int(ci.attempts.Load())
+}
+
+// CallInfoFromContext returns the CallInfo stored in ctx, if any.
+func CallInfoFromContext(ctx context.Context) (*CallInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(*CallInfo)
+
+	return info, ok
+}
+
+// withOperation returns a copy of ctx holding a new CallInfo for the given
+// operation.
+func withOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, &CallInfo{
+		Operation: operation,
+	})
+}
+
+// ensureCallInfo returns ctx if it already holds a CallInfo, or a copy of ctx
+// holding one named after the method and route of req otherwise. IDs in the
+// path are replaced with a placeholder, so the operation doesn't change with
+// every resource the call is made for.
+func (c *Client) ensureCallInfo(ctx context.Context, req *http.Request) context.Context {
+	if _, ok := CallInfoFromContext(ctx); ok {
+		return ctx
+	}
+
+	return withOperation(ctx, req.Method+" "+route(c.endpointPath(req)))
+}
+
+// route returns path with every numeric segment replaced with "{id}".
+func route(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// attemptTransport is an http.RoundTripper that counts how many times a
+// request is sent, so retries made by the underlying HTTP client are visible
+// to middlewares. If limiter is set, every attempt waits for it first.
+type attemptTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.limiter != nil {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+	}
+
+	if info, ok := CallInfoFromContext(req.Context()); ok {
+		info.attempts.Add(1)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,73 @@
+package ohdear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+)
+
+// CertificateHealthService handles communication with the /certificate-health
+// endpoint of Oh Dear's API.
+type CertificateHealthService service
+
+// CertificateHealth represents the health of the TLS certificate of a site.
+type CertificateHealth struct {
+	CertificateChainIssuers []string           `json:"certificate_chain_issuers,omitempty"`
+	CertificateChecks       []CertificateCheck `json:"certificate_checks,omitempty"`
+	CertificateDetails      CertificateDetails `json:"certificate_details,omitempty"`
+}
+
+// CertificateDetails contains the details of a TLS certificate.
+type CertificateDetails struct {
+	ValidFrom  jsonutil.Time `json:"valid_from,omitempty"`
+	ValidUntil jsonutil.Time `json:"valid_until,omitempty"`
+	Issuer     string        `json:"issuer,omitempty"`
+}
+
+// CertificateCheck represents a single check performed on a TLS certificate.
+type CertificateCheck struct {
+	Type   string `json:"type,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Passed bool   `json:"passed,omitempty"`
+}
+
+// Get returns the certificate health of a site by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#certificate-health
+func (s *CertificateHealthService) Get(ctx context.Context, siteID uint, opts ...RequestOption) (*CertificateHealth, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "CertificateHealth.Get")
+
+	if siteID == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.CertificateHealth + "/" + strconv.Itoa(int(siteID))
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var health CertificateHealth
+	if err := json.Unmarshal(ret.Body, &health); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal certificate health: %w", err)
+	}
+
+	return &health, ret, nil
+}
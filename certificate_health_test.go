@@ -0,0 +1,104 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+func TestCertificateHealthService_Get(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/certificate-health/1" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"certificate_details": {
+				"issuer": "Let's Encrypt",
+				"valid_from": "2024-01-01 00:00:00",
+				"valid_until": "2024-04-01 00:00:00"
+			},
+			"certificate_checks": [
+				{"type": "expiration", "label": "Expiration", "passed": true}
+			],
+			"certificate_chain_issuers": ["R3", "ISRG Root X1"]
+		}`)
+	}))
+	defer srv.Close()
+
+	cfg := ohdear.NewConfig(_testKey, nil)
+	cfg.BaseURL = srv.URL
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		siteID    uint
+		wantError error
+		wantAPI   bool
+	}{
+		{
+			name:   "Valid site",
+			siteID: 1,
+		},
+		{
+			name:      "Invalid site ID",
+			siteID:    0,
+			wantError: ohdear.ErrInvalidSiteID,
+		},
+		{
+			name:    "Unknown site",
+			siteID:  2,
+			wantAPI: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health, _, err := client.CertificateHealth.Get(context.Background(), tt.siteID)
+
+			var apiErr *ohdear.APIError
+
+			switch {
+			case tt.wantError != nil:
+				if !errors.Is(err, tt.wantError) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantError)
+				}
+
+				return
+			case tt.wantAPI:
+				if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+					t.Errorf("Get() error = %v, want a 404 *ohdear.APIError", err)
+				}
+
+				return
+			case err != nil:
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			details := health.CertificateDetails
+
+			if want := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC); !details.ValidUntil.Equal(want) {
+				t.Errorf("ValidUntil = %v, want %v", details.ValidUntil.Time, want)
+			}
+
+			if details.Issuer != "Let's Encrypt" || len(health.CertificateChecks) != 1 || len(health.CertificateChainIssuers) != 2 {
+				t.Errorf("Get() = %+v", health)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package ohdear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+)
+
+// ChecksService handles communication with the /checks endpoint of Oh Dear's
+// API.
+type ChecksService service
+
+// CheckType is the type of a check, such as CheckTypeUptime. Types added to
+// the API after this package was released are kept as they are.
+type CheckType string
+
+// Check types supported by the API.
+const (
+	CheckTypeUptime                  CheckType = "uptime"
+	CheckTypePerformance             CheckType = "performance"
+	CheckTypeBrokenLinks             CheckType = "broken_links"
+	CheckTypeMixedContent            CheckType = "mixed_content"
+	CheckTypeCertificateHealth       CheckType = "certificate_health"
+	CheckTypeCertificateTransparency CheckType = "certificate_transparency"
+	CheckTypeDNS                     CheckType = "dns"
+	CheckTypeCron                    CheckType = "cron"
+	CheckTypeApplicationHealth       CheckType = "application_health"
+	CheckTypeSitemap                 CheckType = "sitemap"
+	CheckTypeLighthouse              CheckType = "lighthouse"
+	CheckTypeDomain                  CheckType = "domain"
+)
+
+// Known returns true if t is one of the check types defined by this package.
+func (t CheckType) Known() bool {
+	switch t {
+	case CheckTypeUptime,
+		CheckTypePerformance,
+		CheckTypeBrokenLinks,
+		CheckTypeMixedContent,
+		CheckTypeCertificateHealth,
+		CheckTypeCertificateTransparency,
+		CheckTypeDNS,
+		CheckTypeCron,
+		CheckTypeApplicationHealth,
+		CheckTypeSitemap,
+		CheckTypeLighthouse,
+		CheckTypeDomain:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckResult is the result of a check run, or the summarized result of every
+// check of a site. Results added to the API after this package was released
+// are kept as they are.
+type CheckResult string
+
+// Check results returned by the API.
+const (
+	CheckResultSucceeded         CheckResult = "succeeded"
+	CheckResultWarning           CheckResult = "warning"
+	CheckResultFailed            CheckResult = "failed"
+	CheckResultPending           CheckResult = "pending"
+	CheckResultErroredOrTimedOut CheckResult = "errored-or-timed-out"
+)
+
+// Known returns true if r is one of the check results defined by this
+// package.
+func (r CheckResult) Known() bool {
+	switch r {
+	case CheckResultSucceeded,
+		CheckResultWarning,
+		CheckResultFailed,
+		CheckResultPending,
+		CheckResultErroredOrTimedOut:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFailure returns true if r means the check failed or couldn't run.
+func (r CheckResult) IsFailure() bool {
+	return r == CheckResultFailed || r == CheckResultErroredOrTimedOut
+}
+
+// Enable enables a check by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#enabling-a-check
+func (s *ChecksService) Enable(ctx context.Context, id uint, opts ...RequestOption) (*Check, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Checks.Enable")
+
+	return s.post(ctx, id, "/enable", opts...)
+}
+
+// Disable disables a check by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#disabling-a-check
+func (s *ChecksService) Disable(ctx context.Context, id uint, opts ...RequestOption) (*Check, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Checks.Disable")
+
+	return s.post(ctx, id, "/disable", opts...)
+}
+
+// RequestRun requests a new run of a check by ID. The check must be enabled.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#requesting-a-new-run
+func (s *ChecksService) RequestRun(ctx context.Context, id uint, opts ...RequestOption) (*Check, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Checks.RequestRun")
+
+	return s.post(ctx, id, "/request-run", opts...)
+}
+
+// post sends an empty POST request to the given action of a check and returns
+// the check from the response.
+func (s *ChecksService) post(ctx context.Context, id uint, action string, opts ...RequestOption) (*Check, *Response, error) {
+	if id == 0 {
+		return nil, nil, ErrInvalidCheckID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Checks + "/" + strconv.Itoa(int(id)) + action
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var check Check
+	if err := json.Unmarshal(ret.Body, &check); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal check: %w", err)
+	}
+
+	return &check, ret, nil
+}
+
+// RequestRunMany requests a new run of every given check concurrently,
+// collecting the result of every check instead of stopping at the first
+// error. To rerun checks across several sites, collect their IDs from
+// Site.Checks. See BulkOptions for how the requests are spread across
+// workers.
+func (s *ChecksService) RequestRunMany(ctx context.Context, ids []uint, bulk *BulkOptions, opts ...RequestOption) BulkResults[*Check] {
+	return runBulk(ctx, ids, bulk, func(ctx context.Context, id uint) (*Check, *Response, error) {
+		return s.RequestRun(ctx, id, opts...)
+	})
+}
@@ -2,11 +2,15 @@ package ohdear
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
-	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
 
 	"git.sr.ht/~jamesponddotco/httpx-go"
 	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
@@ -27,14 +31,33 @@ type (
 
 	// Client is a client for the Help Scout Docs API.
 	Client struct {
-		// httpc is the underlying HTTP client used by the API client.
-		httpc *httpx.Client
+		// httpc is the underlying HTTP client used by the API client. It has no
+		// timeout of its own, as every call is bounded by the deadline set in
+		// Do.
+		httpc *http.Client
 
 		// cfg specifies the configuration used by the API client.
 		cfg *Config
 
+		// doer is the underlying HTTP client wrapped by the configured
+		// middlewares.
+		doer Doer
+
+		// credentials provides the API key for every request.
+		credentials CredentialsProvider
+
+		// retry is the default retry policy of the client.
+		retry *RetryPolicy
+
 		// Service fields.
-		Sites *SitesService
+		Sites                    *SitesService
+		Checks                   *ChecksService
+		CertificateHealth        *CertificateHealthService
+		StatusPages              *StatusPagesService
+		MaintenancePeriods       *MaintenancePeriodsService
+		CronChecks               *CronChecksService
+		NotificationDestinations *NotificationDestinationsService
+		Teams                    *TeamsService
 
 		// common service fields shared by all services.
 		common service
@@ -54,44 +77,271 @@ func NewClient(cfg *Config) (*Client, error) {
 	}
 
 	c := &Client{
-		httpc: httpx.NewClient(),
-		cfg:   cfg,
+		cfg:         cfg,
+		credentials: cfg.Credentials,
+		retry:       DefaultRetryPolicy(),
+	}
+
+	c.retry.MaxRetries = max(cfg.MaxRetries, 0)
+
+	if c.credentials == nil {
+		c.credentials = StaticCredentials(cfg.Key)
+	}
+
+	transport := cfg.Transport
+	if transport == nil {
+		transport = httpx.DefaultTransport()
 	}
 
-	c.httpc.UserAgent = cfg.Application.UserAgent()
-	c.httpc.Logger = cfg.Logger
-	c.httpc.Debug = cfg.Debug
+	// Retries are handled by send, so they can be configured for each call,
+	// and the configured limiter applies to every attempt.
+	c.httpc = &http.Client{
+		Transport: &attemptTransport{
+			next:    transport,
+			limiter: cfg.RateLimiter,
+		},
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	middlewares := make([]Middleware, 0, len(cfg.Middleware)+2)
+	middlewares = append(middlewares, cfg.Middleware...)
+
+	if cfg.LogHandler != nil {
+		middlewares = append(middlewares, LoggingMiddleware(slog.New(cfg.LogHandler)))
+	}
+
+	if cfg.Debug {
+		middlewares = append(middlewares, DebugMiddleware(cfg.Logger))
+	}
+
+	c.doer = chain(DoerFunc(c.send), middlewares...)
 
 	c.common.client = c
 	c.Sites = (*SitesService)(&c.common)
+	c.Checks = (*ChecksService)(&c.common)
+	c.CertificateHealth = (*CertificateHealthService)(&c.common)
+	c.StatusPages = (*StatusPagesService)(&c.common)
+	c.MaintenancePeriods = (*MaintenancePeriodsService)(&c.common)
+	c.CronChecks = (*CronChecksService)(&c.common)
+	c.NotificationDestinations = (*NotificationDestinationsService)(&c.common)
+	c.Teams = (*TeamsService)(&c.common)
 
 	return c, nil
 }
 
 // Do performs an HTTP request using the underlying HTTP client.
-func (c *Client) Do(ctx context.Context, req *http.Request) (*Response, error) {
-	ret, err := c.httpc.Do(ctx, req)
+//
+// If a Cache is configured, responses to GET requests are served from it
+// while fresh, and successful requests using other methods evict the cached
+// response for the same URL. Errors returned by the cache are logged and
+// don't fail the call.
+//
+// If the API rejects the API key, the credentials provider is refreshed and
+// the request is retried once if the key changed.
+//
+// The given options override the configuration of the client for this call
+// only. The call, including retries, is canceled after Config.Timeout unless
+// WithTimeout is used.
+//
+// If the API responds with an unsuccessful status code, Do returns the
+// response along with an *APIError describing it.
+func (c *Client) Do(ctx context.Context, req *http.Request, opts ...RequestOption) (*Response, error) {
+	ctx = c.ensureCallInfo(ctx, req)
+
+	o := newRequestOptions(opts)
+	o.apply(req)
+
+	timeout := c.cfg.Timeout
+	if o.timeout > 0 {
+		timeout = o.timeout
+	}
+
+	ctx, cancel := context.WithTimeout(withRequestOptions(ctx, o), timeout)
+	defer cancel()
+
+	ret, err := c.dispatch(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		return nil, err
 	}
 
-	defer func() {
-		if err = httpx.DrainResponseBody(ret); err != nil {
-			log.Fatal(err)
+	if ret.Status == http.StatusUnauthorized {
+		ret, err = c.reauthenticate(ctx, req, ret)
+		if err != nil {
+			return nil, err
 		}
-	}()
+	}
+
+	if !ret.IsSuccessful() {
+		return ret, newAPIError(ret)
+	}
 
-	if c.cfg.Debug {
-		var dump []byte
+	return ret, nil
+}
+
+// dispatch performs an HTTP request, going through the cache if one is
+// configured and the call doesn't bypass it.
+func (c *Client) dispatch(ctx context.Context, req *http.Request) (*Response, error) {
+	switch {
+	case c.cfg.Cache == nil, requestOptionsFromContext(ctx).noCache:
+		return c.do(ctx, req)
+	case req.Method != http.MethodGet:
+		return c.doAndEvict(ctx, req)
+	default:
+		return c.doCached(ctx, req)
+	}
+}
 
-		dump, err = httputil.DumpResponse(ret, true)
+// reauthenticate refreshes the credentials after the API rejected a request
+// and retries it once with the new key. If the key didn't change or the
+// request body can't be sent again, the original response is returned.
+func (c *Client) reauthenticate(ctx context.Context, req *http.Request, ret *Response) (*Response, error) {
+	if err := c.credentials.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("could not refresh credentials: %w", err)
+	}
+
+	key, err := c.credentials.Key(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get API key: %w", err)
+	}
+
+	if req.Header.Get("Authorization") == "Bearer "+key {
+		return ret, nil
+	}
+
+	retry := req.Clone(ctx)
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return ret, nil
+		}
+
+		retry.Body, err = req.GetBody()
 		if err != nil {
 			return nil, fmt.Errorf("%w", err)
 		}
+	}
+
+	retry.Header.Set("Authorization", "Bearer "+key)
+
+	return c.dispatch(ctx, retry)
+}
+
+// doCached performs a GET request, serving the response from the cache when
+// possible and storing it otherwise.
+func (c *Client) doCached(ctx context.Context, req *http.Request) (*Response, error) {
+	key := cacheKey(req)
+
+	entry, err := c.cfg.Cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			c.logCacheError(ctx, "get", err)
+		}
+
+		entry = nil
+	}
+
+	now := time.Now()
+
+	if entry != nil {
+		if entry.Fresh(now) {
+			return entry.response(), nil
+		}
+
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+	}
+
+	ret, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := c.cfg.CachePolicy.TTL(c.endpointPath(req))
+
+	if ret.Status == http.StatusNotModified && entry != nil {
+		// The entry may be shared with other callers of the cache, so it's
+		// copied before being changed.
+		revalidated := *entry
+		revalidated.ExpiresAt = now.Add(ttl)
+
+		if err = c.cfg.Cache.Set(ctx, key, &revalidated); err != nil {
+			c.logCacheError(ctx, "set", err)
+		}
+
+		return revalidated.response(), nil
+	}
+
+	etag := ret.Header.Get("ETag")
+
+	if !ret.IsSuccessful() || (ttl < 1 && etag == "") {
+		return ret, nil
+	}
+
+	entry = &CacheEntry{
+		ExpiresAt: now.Add(ttl),
+		Header:    ret.Header.Clone(),
+		ETag:      etag,
+		Body:      ret.Body,
+		Status:    ret.Status,
+	}
+
+	if err = c.cfg.Cache.Set(ctx, key, entry); err != nil {
+		c.logCacheError(ctx, "set", err)
+	}
+
+	return ret, nil
+}
+
+// doAndEvict performs a request that changes a resource and evicts the cached
+// response for its URL if the request succeeds. Cached responses for other
+// URLs, such as the collection the resource belongs to, are left to expire.
+func (c *Client) doAndEvict(ctx context.Context, req *http.Request) (*Response, error) {
+	ret, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.IsSuccessful() {
+		if err = c.cfg.Cache.Delete(ctx, cacheKey(req)); err != nil {
+			c.logCacheError(ctx, "delete", err)
+		}
+	}
+
+	return ret, nil
+}
+
+// logCacheError logs an error returned by the cache. Such errors don't fail
+// the call, as the API was reached or can still be, so they're logged through
+// Config.LogHandler, or Config.Logger if it's not set.
+func (c *Client) logCacheError(ctx context.Context, op string, err error) {
+	switch {
+	case c.cfg.LogHandler != nil:
+		slog.New(c.cfg.LogHandler).LogAttrs(ctx, slog.LevelWarn, "ohdear cache error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+	case c.cfg.Logger != nil:
+		c.cfg.Logger.Printf("[WARN] ohdear cache %s failed: %v", op, err)
+	}
+}
 
-		c.cfg.Logger.Printf("\n%s", dump)
+// do performs an HTTP request through the middleware chain without going
+// through the cache.
+func (c *Client) do(ctx context.Context, req *http.Request) (*Response, error) {
+	ret, err := c.doer.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
 	}
 
+	defer func() {
+		if err = httpx.DrainResponseBody(ret); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
 	body, err := io.ReadAll(ret.Body)
 	if err != nil {
 		return nil, fmt.Errorf("%w", err)
@@ -123,18 +373,84 @@ func (c *Client) NewRequest(
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.cfg.Key)
+	key, err := c.credentials.Key(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get API key: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	return req, nil
+}
+
+// send is the innermost Doer of the client, which sends requests to the API
+// using the underlying HTTP client and retries them according to the retry
+// policy of the call.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	var (
+		ctx        = req.Context()
+		o          = requestOptionsFromContext(ctx)
+		policy     = c.retry
+		idempotent = o.idempotent || isIdempotent(req.Method)
+		rewindable = req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	)
+
+	if o.retry != nil {
+		policy = o.retry
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.cfg.Application.UserAgent().String())
+	}
+
+	for retry := 0; ; retry++ {
+		attempt := req
+
+		if retry > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("%w", err)
+			}
+
+			attempt = req.Clone(ctx)
+			attempt.Body = body
+		}
 
-	if c.cfg.Debug {
-		dump, err := httputil.DumpRequest(req, true)
+		resp, err := c.httpc.Do(attempt)
 		if err != nil {
 			return nil, fmt.Errorf("%w", err)
 		}
 
-		c.cfg.Logger.Printf("\n%s", string(dump))
+		if retry >= policy.MaxRetries || !rewindable || !policy.shouldRetry(resp.StatusCode, idempotent) {
+			return resp, nil
+		}
+
+		delay := policy.delay(resp, retry)
+
+		if err = httpx.DrainResponseBody(resp); err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, fmt.Errorf("%w", ctx.Err())
+		case <-timer.C:
+		}
 	}
+}
 
-	return req, nil
+// endpointPath returns the path of the request relative to the base URL.
+func (c *Client) endpointPath(req *http.Request) string {
+	base, err := url.Parse(c.cfg.BaseURL)
+	if err != nil {
+		return req.URL.Path
+	}
+
+	return strings.TrimPrefix(req.URL.Path, base.Path)
 }
 
 // Links contains the URLs for pagination navigation.
@@ -196,6 +512,10 @@ type Response struct {
 
 	// Status is the HTTP status code of the response.
 	Status int
+
+	// Cached specifies whether or not the response was served from the
+	// cache.
+	Cached bool
 }
 
 // IsSuccessful checks if the response status code is within the successful range.
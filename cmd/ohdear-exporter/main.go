@@ -0,0 +1,94 @@
+// Command ohdear-exporter publishes the status of the sites and checks of an
+// Oh Dear account as Prometheus metrics.
+//
+// The API key is read from the OHDEAR_API_KEY environment variable.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/exporter"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/build"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ErrKeyRequired is returned when the API key environment variable is unset.
+const ErrKeyRequired xerrors.Error = "OHDEAR_API_KEY must be set"
+
+// _keyEnv is the environment variable holding the Oh Dear API key.
+const _keyEnv string = "OHDEAR_API_KEY"
+
+func main() {
+	var (
+		listen       = flag.String("listen", ":9794", "address to listen on")
+		path         = flag.String("path", "/metrics", "path under which to publish metrics")
+		cacheTTL     = flag.Duration("cache-ttl", exporter.DefaultCacheTTL, "how long to reuse data fetched from the API")
+		timeout      = flag.Duration("timeout", exporter.DefaultTimeout, "time limit for fetching data from the API")
+		certificates = flag.Bool("certificates", false, "publish the number of days until certificates expire")
+		debug        = flag.Bool("debug", false, "enable debug logging")
+	)
+
+	flag.Parse()
+
+	if err := run(*listen, *path, *cacheTTL, *timeout, *certificates, *debug); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(listen, path string, cacheTTL, timeout time.Duration, certificates, debug bool) error {
+	key := os.Getenv(_keyEnv)
+	if key == "" {
+		return ErrKeyRequired
+	}
+
+	app := &ohdear.Application{
+		Name:    "ohdear-exporter",
+		Version: build.Version,
+		Contact: build.URL,
+	}
+
+	cfg := ohdear.NewConfig(key, app)
+	cfg.Debug = debug
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	collector, err := exporter.New(client, &exporter.Options{
+		CacheTTL:     cacheTTL,
+		Timeout:      timeout,
+		Certificates: certificates,
+	})
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("listening on %s", listen)
+
+	if err = server.ListenAndServe(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/relay"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ErrUnknownSinkType is returned when a sink has an unsupported type.
+	ErrUnknownSinkType xerrors.Error = "unknown sink type"
+
+	// ErrSinkURLRequired is returned when an HTTP sink has no URL.
+	ErrSinkURLRequired xerrors.Error = "sink URL cannot be empty"
+)
+
+// Sink types supported in the configuration file.
+const (
+	sinkHTTP  string = "http"
+	sinkSlack string = "slack"
+	sinkTeams string = "teams"
+	sinkFile  string = "file"
+)
+
+// _stdout is the path of file sinks writing to standard output.
+const _stdout string = "-"
+
+// config is the configuration file of the relay.
+//
+//	listen: ":8080"
+//	path: /webhook
+//	queue_dir: /var/lib/ohdear-relay
+//	dedup_window: 10m
+//	sinks:
+//	  - name: ops
+//	    type: slack
+//	    url: https://hooks.slack.com/services/...
+//	    retry:
+//	      max_attempts: 10
+//	      min_delay: 10s
+//	      max_delay: 30m
+//	  - name: audit
+//	    type: file
+//	    path: /var/log/ohdear.jsonl
+//	routes:
+//	  - sinks: [ops]
+//	    tags: [production]
+//	    check_types: [uptime, certificate_health]
+//	  - sinks: [audit]
+type config struct {
+	Listen      string        `yaml:"listen"`
+	Path        string        `yaml:"path"`
+	QueueDir    string        `yaml:"queue_dir"`
+	Sinks       []sinkConfig  `yaml:"sinks"`
+	Routes      []routeConfig `yaml:"routes"`
+	DedupWindow time.Duration `yaml:"dedup_window"`
+}
+
+// sinkConfig is the configuration of a sink.
+type sinkConfig struct {
+	Headers map[string]string `yaml:"headers"`
+	Retry   *retryConfig      `yaml:"retry"`
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`
+	URL     string            `yaml:"url"`
+	Path    string            `yaml:"path"`
+}
+
+// retryConfig is the retry policy of a sink.
+type retryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	MinDelay    time.Duration `yaml:"min_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+// routeConfig is a routing rule.
+type routeConfig struct {
+	Sinks      []string             `yaml:"sinks"`
+	Tags       []string             `yaml:"tags"`
+	CheckTypes []ohdear.CheckType   `yaml:"check_types"`
+	Results    []ohdear.CheckResult `yaml:"results"`
+}
+
+// loadConfig reads the configuration file at path.
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %w", err)
+	}
+
+	cfg := &config{
+		Listen: ":8080",
+		Path:   "/webhook",
+	}
+
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("could not parse config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// options returns the relay options described by the configuration, along
+// with the files opened for file sinks, which the caller must close.
+func (c *config) options() (*relay.Options, []io.Closer, error) {
+	opts := &relay.Options{
+		Destinations: make([]relay.Destination, 0, len(c.Sinks)),
+		Rules:        make([]relay.Rule, 0, len(c.Routes)),
+		DedupWindow:  c.DedupWindow,
+	}
+
+	var closers []io.Closer
+
+	for _, s := range c.Sinks {
+		sink, closer, err := s.sink()
+		if err != nil {
+			for _, closer := range closers {
+				closer.Close()
+			}
+
+			return nil, nil, fmt.Errorf("sink %s: %w", s.Name, err)
+		}
+
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+
+		dest := relay.Destination{
+			Name: s.Name,
+			Sink: sink,
+		}
+
+		if s.Retry != nil {
+			dest.Retry = &relay.RetryPolicy{
+				MaxAttempts: s.Retry.MaxAttempts,
+				MinDelay:    s.Retry.MinDelay,
+				MaxDelay:    s.Retry.MaxDelay,
+			}
+		}
+
+		opts.Destinations = append(opts.Destinations, dest)
+	}
+
+	for _, r := range c.Routes {
+		opts.Rules = append(opts.Rules, relay.Rule{
+			Destinations: r.Sinks,
+			Tags:         r.Tags,
+			CheckTypes:   r.CheckTypes,
+			Results:      r.Results,
+		})
+	}
+
+	if c.QueueDir != "" {
+		queue, err := relay.NewDiskQueue(c.QueueDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w", err)
+		}
+
+		opts.Queue = queue
+	}
+
+	return opts, closers, nil
+}
+
+// sink returns the sink described by the configuration, along with the file
+// it writes to, if any.
+func (s *sinkConfig) sink() (relay.Sink, io.Closer, error) {
+	if s.Type != sinkFile && s.URL == "" {
+		return nil, nil, ErrSinkURLRequired
+	}
+
+	switch s.Type {
+	case sinkHTTP:
+		header := make(http.Header, len(s.Headers))
+
+		for key, value := range s.Headers {
+			header.Set(key, os.ExpandEnv(value))
+		}
+
+		return &relay.HTTPSink{URL: s.URL, Header: header}, nil, nil
+	case sinkSlack:
+		return &relay.SlackSink{URL: s.URL}, nil, nil
+	case sinkTeams:
+		return &relay.TeamsSink{URL: s.URL}, nil, nil
+	case sinkFile:
+		if s.Path == "" || s.Path == _stdout {
+			return relay.NewWriterSink(os.Stdout), nil, nil
+		}
+
+		f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not open file: %w", err)
+		}
+
+		return relay.NewWriterSink(f), f, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownSinkType, s.Type)
+	}
+}
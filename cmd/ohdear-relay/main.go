@@ -0,0 +1,120 @@
+// Command ohdear-relay receives the webhooks sent by Oh Dear, verifies their
+// signature and forwards them to generic webhooks, Slack, Microsoft Teams or
+// files, routed by the tags of the site and the type of check.
+//
+// Sinks and routes are read from a YAML configuration file, and the webhook
+// secret from the OHDEAR_WEBHOOK_SECRET environment variable. Header values
+// of HTTP sinks can refer to other environment variables, such as
+// "Bearer ${SINK_TOKEN}", to keep credentials out of the file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/relay"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrSecretRequired is returned when the webhook secret environment variable
+// is unset.
+const ErrSecretRequired xerrors.Error = "OHDEAR_WEBHOOK_SECRET must be set"
+
+// _secretEnv is the environment variable holding the webhook secret.
+const _secretEnv string = "OHDEAR_WEBHOOK_SECRET"
+
+// _shutdownTimeout is how long in-flight requests are given to finish on
+// shutdown.
+const _shutdownTimeout time.Duration = 10 * time.Second
+
+func main() {
+	var (
+		configPath = flag.String("config", "ohdear-relay.yaml", "path to the configuration file")
+		debug      = flag.Bool("debug", false, "enable debug logging")
+	)
+
+	flag.Parse()
+
+	if err := run(*configPath, *debug); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(configPath string, debug bool) error {
+	secret := os.Getenv(_secretEnv)
+	if secret == "" {
+		return ErrSecretRequired
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	opts, closers, err := cfg.options()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}()
+
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+
+	opts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	r, err := relay.New(opts)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- r.Run(ctx)
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, r.Handler(secret))
+
+	server := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), _shutdownTimeout)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	opts.Logger.Info("listening", slog.String("address", cfg.Listen), slog.String("path", cfg.Path))
+
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%w", err)
+	}
+
+	return <-done
+}
@@ -2,13 +2,20 @@ package ohdear
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"git.sr.ht/~jamesponddotco/httpx-go"
 	"git.sr.ht/~jamesponddotco/ohdear-go/internal/build"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
 	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
 	"git.sr.ht/~jamesponddotco/xstd-go/xlog"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -31,16 +38,42 @@ const (
 	// without contact information.
 	ErrApplicationContactRequired xerrors.Error = "application contact required"
 
-	// ErrKeyRequired is returned when a Config is created without an API key.
+	// ErrKeyRequired is returned when a Config is created without an API key
+	// or credentials provider.
 	ErrKeyRequired xerrors.Error = "API key required"
+
+	// ErrAPILocationSet is returned when SetAPILocation is called more than
+	// once.
+	ErrAPILocationSet xerrors.Error = "API location already set"
 )
 
 // Default values for the Config struct.
 const (
 	DefaultMaxRetries int           = 3
 	DefaultTimeout    time.Duration = 60 * time.Second
+	DefaultCacheTTL   time.Duration = 60 * time.Second
 )
 
+// _apiLocationSet reports whether SetAPILocation has been called.
+var _apiLocationSet atomic.Bool
+
+// SetAPILocation sets the timezone used to interpret timestamps the API
+// returns without an offset, such as "2023-05-14 12:00:00", and to format
+// them in requests. A nil location means UTC, the default.
+//
+// The timezone is shared by every client in the process, including the ones
+// in a ClientPool, so it can only be set once. Call it at startup, before any
+// client is created. Later calls return ErrAPILocationSet.
+func SetAPILocation(loc *time.Location) error {
+	if !_apiLocationSet.CompareAndSwap(false, true) {
+		return ErrAPILocationSet
+	}
+
+	jsonutil.SetLocation(loc)
+
+	return nil
+}
+
 // Logger defines the interface for logging. It is basically a thin wrapper
 // around the standard logger which implements only a subset of the logger API.
 type Logger interface {
@@ -109,18 +142,77 @@ type Config struct {
 	// Logger is the logger to use for logging requests when debugging.
 	Logger Logger
 
-	// Key is the API key used to authenticate with the API.
+	// LogHandler is used to log every API call as a structured record. See
+	// LoggingMiddleware for the attributes included.
+	//
+	// This field is optional. Calls are not logged if it's nil.
+	LogHandler slog.Handler
+
+	// Cache is used to store responses to GET requests. Stale entries are
+	// revalidated with the API using their ETag when the API provides one.
+	//
+	// A successful change only evicts the cached response for its own URL.
+	// Related responses, such as the list of sites after one is added or the
+	// site after one of its checks is disabled, are served from the cache
+	// until they expire. Keep the TTLs in CachePolicy short for endpoints
+	// that must reflect changes right away.
+	//
+	// This field is optional. Responses are not cached if it's nil.
+	Cache Cache
+
+	// CachePolicy defines how long responses are cached for each endpoint.
+	//
+	// This field is optional. It defaults to DefaultCachePolicy if Cache is
+	// set.
+	CachePolicy *CachePolicy
+
+	// Credentials provides the API key for every request, allowing keys to be
+	// rotated without restarting the application.
+	//
+	// This field is optional. If it's nil, Key is used instead.
+	Credentials CredentialsProvider
+
+	// Key is the API key used to authenticate with the API. It's ignored if
+	// Credentials is set.
 	Key string
 
-	// MaxRetries specifies the maximum number of times to retry a request if it
-	// fails due to rate limiting.
+	// BaseURL is the base URL of the Oh Dear API, without a trailing slash.
+	//
+	// This field is optional. It defaults to DefaultBaseURL.
+	BaseURL string
+
+	// Transport specifies the mechanism by which individual HTTP requests are
+	// made, such as a recorder for tests.
+	//
+	// This field is optional. It defaults to a transport tuned for the Oh
+	// Dear API.
+	Transport http.RoundTripper
+
+	// RateLimiter limits how many requests per second the client sends to the
+	// API, including retries. Clients sharing a RateLimiter share the same
+	// budget.
+	//
+	// This field is optional. If it's nil, requests are not rate limited.
+	RateLimiter *rate.Limiter
+
+	// Middleware is an ordered chain of middlewares wrapping every request
+	// made by the client. The first middleware is the outermost one.
 	//
 	// This field is optional.
+	Middleware []Middleware
+
+	// MaxRetries is the maximum number of times a request is retried when
+	// the API responds with a retryable status code. See RetryPolicy for the
+	// status codes retried, and WithRetryPolicy to override it for a single
+	// call. A negative value disables retries.
+	//
+	// This field is optional. DefaultMaxRetries is used if it's zero.
 	MaxRetries int
 
-	// Timeout is the time limit for requests made by the client to the  API.
+	// Timeout is the time limit for each call to the API, including retries.
+	// Use WithTimeout to override it for a single call.
 	//
-	// This field is optional.
+	// This field is optional. It defaults to DefaultTimeout.
 	Timeout time.Duration
 
 	// Debug specifies whether or not to enable debug logging.
@@ -137,6 +229,7 @@ func NewConfig(key string, app *Application) *Config {
 	return &Config{
 		Application: app,
 		Key:         key,
+		BaseURL:     DefaultBaseURL,
 		MaxRetries:  DefaultMaxRetries,
 		Timeout:     DefaultTimeout,
 		Debug:       false,
@@ -152,7 +245,13 @@ func (c *Config) init() {
 		c.Application = DefaultApplication()
 	}
 
-	if c.MaxRetries < 1 {
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultBaseURL
+	}
+
+	c.BaseURL = strings.TrimSuffix(c.BaseURL, "/")
+
+	if c.MaxRetries == 0 {
 		c.MaxRetries = DefaultMaxRetries
 	}
 
@@ -160,6 +259,10 @@ func (c *Config) init() {
 		c.Timeout = DefaultTimeout
 	}
 
+	if c.Cache != nil && c.CachePolicy == nil {
+		c.CachePolicy = DefaultCachePolicy()
+	}
+
 	if c.Logger == nil && c.Debug {
 		c.Logger = xlog.DefaultZeroLogger
 	}
@@ -175,9 +278,13 @@ func (c *Config) validate() error {
 		return err
 	}
 
-	if c.Key == "" {
+	if c.Key == "" && c.Credentials == nil {
 		return ErrKeyRequired
 	}
 
+	if err := urlutil.Validate(c.BaseURL); err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,224 @@
+package ohdear
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrEmptyKey is returned by a CredentialsProvider when the API key it finds
+// is empty.
+const ErrEmptyKey xerrors.Error = "API key is empty"
+
+// CredentialsProvider defines the interface for retrieving the API key used to
+// authenticate requests. It's consulted for every request, so implementations
+// should cache keys that are expensive to retrieve.
+//
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	// Key returns the API key to use for a request.
+	Key(ctx context.Context) (string, error)
+
+	// Refresh discards any cached key. It's called when the API rejects the
+	// key returned by Key, after which the request is retried once with the
+	// key returned by the next call to Key.
+	Refresh(ctx context.Context) error
+}
+
+// StaticCredentials is a CredentialsProvider that always returns the same API
+// key.
+type StaticCredentials string
+
+// Compile-time check to ensure StaticCredentials implements
+// CredentialsProvider.
+var _ CredentialsProvider = StaticCredentials("")
+
+// Key implements the CredentialsProvider interface.
+func (c StaticCredentials) Key(_ context.Context) (string, error) {
+	if c == "" {
+		return "", ErrEmptyKey
+	}
+
+	return string(c), nil
+}
+
+// Refresh implements the CredentialsProvider interface. It does nothing, as
+// the key never changes.
+func (StaticCredentials) Refresh(_ context.Context) error {
+	return nil
+}
+
+// EnvCredentials is a CredentialsProvider that reads the API key from an
+// environment variable every time it's needed.
+type EnvCredentials string
+
+// Compile-time check to ensure EnvCredentials implements CredentialsProvider.
+var _ CredentialsProvider = EnvCredentials("")
+
+// Key implements the CredentialsProvider interface.
+func (c EnvCredentials) Key(_ context.Context) (string, error) {
+	key := strings.TrimSpace(os.Getenv(string(c)))
+	if key == "" {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrEmptyKey, string(c))
+	}
+
+	return key, nil
+}
+
+// Refresh implements the CredentialsProvider interface. It does nothing, as
+// the variable is read for every request.
+func (EnvCredentials) Refresh(_ context.Context) error {
+	return nil
+}
+
+// FileCredentials is a CredentialsProvider that reads the API key from a file,
+// such as a mounted Kubernetes secret, and reads it again whenever the file
+// changes.
+type FileCredentials struct {
+	// modTime and size identify the version of the file the key was read
+	// from.
+	modTime time.Time
+	size    int64
+
+	// path is the path of the file holding the key.
+	path string
+
+	// key is the cached API key.
+	key string
+
+	// mu protects the cached key.
+	mu sync.Mutex
+}
+
+// Compile-time check to ensure FileCredentials implements CredentialsProvider.
+var _ CredentialsProvider = (*FileCredentials)(nil)
+
+// NewFileCredentials returns a new FileCredentials reading the API key from
+// the file at path. Leading and trailing whitespace is ignored.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{
+		path: path,
+	}
+}
+
+// Key implements the CredentialsProvider interface.
+func (c *FileCredentials) Key(_ context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return "", fmt.Errorf("could not read API key file: %w", err)
+	}
+
+	if c.key != "" && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return c.key, nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return "", fmt.Errorf("could not read API key file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrEmptyKey, c.path)
+	}
+
+	c.key = key
+	c.modTime = info.ModTime()
+	c.size = info.Size()
+
+	return c.key, nil
+}
+
+// Refresh implements the CredentialsProvider interface.
+func (c *FileCredentials) Refresh(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.key = ""
+
+	return nil
+}
+
+// CommandCredentials is a CredentialsProvider that runs a command, such as a
+// secrets manager CLI, and uses its standard output as the API key.
+type CommandCredentials struct {
+	// fetchedAt is the moment the cached key was retrieved.
+	fetchedAt time.Time
+
+	// name and args are the command to run and its arguments.
+	name string
+	args []string
+
+	// key is the cached API key.
+	key string
+
+	// TTL is how long the key is cached before the command runs again. If
+	// it's zero, the key is cached until Refresh is called.
+	TTL time.Duration
+
+	// mu protects the cached key.
+	mu sync.Mutex
+}
+
+// Compile-time check to ensure CommandCredentials implements
+// CredentialsProvider.
+var _ CredentialsProvider = (*CommandCredentials)(nil)
+
+// NewCommandCredentials returns a new CommandCredentials running the given
+// command. The command is not run through a shell.
+func NewCommandCredentials(name string, args ...string) *CommandCredentials {
+	return &CommandCredentials{
+		name: name,
+		args: args,
+	}
+}
+
+// Key implements the CredentialsProvider interface.
+func (c *CommandCredentials) Key(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.key != "" && (c.TTL < 1 || time.Since(c.fetchedAt) < c.TTL) {
+		return c.key, nil
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not run API key command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	key := strings.TrimSpace(string(out))
+	if key == "" {
+		return "", fmt.Errorf("%w: command %s printed nothing", ErrEmptyKey, c.name)
+	}
+
+	c.key = key
+	c.fetchedAt = time.Now()
+
+	return c.key, nil
+}
+
+// Refresh implements the CredentialsProvider interface.
+func (c *CommandCredentials) Refresh(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.key = ""
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+// rotatingCredentials returns the next key in keys every time it's refreshed.
+type rotatingCredentials struct {
+	keys      []string
+	current   int
+	refreshes int
+	mu        sync.Mutex
+}
+
+func (c *rotatingCredentials) Key(_ context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.keys[c.current], nil
+}
+
+func (c *rotatingCredentials) Refresh(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refreshes++
+
+	if c.current < len(c.keys)-1 {
+		c.current++
+	}
+
+	return nil
+}
+
+func TestClient_Reauthenticate(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+
+	creds := &rotatingCredentials{
+		keys: []string{"expired", ohdeartest.DefaultKey},
+	}
+
+	cfg := ohdear.NewConfig("", nil)
+	cfg.Credentials = creds
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	if _, _, err = client.Sites.Get(context.Background(), 1); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if creds.refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", creds.refreshes)
+	}
+
+	// A key that keeps being rejected is only retried once.
+	creds.keys = []string{"expired", "revoked"}
+	creds.current = 0
+
+	_, _, err = client.Sites.Get(context.Background(), 1)
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != 401 {
+		t.Errorf("Get() error = %v, want a 401 *ohdear.APIError", err)
+	}
+
+	if creds.refreshes != 2 {
+		t.Errorf("refreshes = %d, want 2", creds.refreshes)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "key")
+	)
+
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds := ohdear.NewFileCredentials(path)
+
+	key, err := creds.Key(ctx)
+	if err != nil || key != "first" {
+		t.Fatalf("Key() = %q, %v, want %q", key, err, "first")
+	}
+
+	if err = os.WriteFile(path, []byte("second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure the modification time changes even on coarse filesystems.
+	future := time.Now().Add(time.Minute)
+	if err = os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err = creds.Key(ctx)
+	if err != nil || key != "second" {
+		t.Errorf("Key() after change = %q, %v, want %q", key, err, "second")
+	}
+
+	if err = os.WriteFile(path, []byte("  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = creds.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = creds.Key(ctx); !errors.Is(err, ohdear.ErrEmptyKey) {
+		t.Errorf("Key() of empty file error = %v, want %v", err, ohdear.ErrEmptyKey)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("OHDEAR_TEST_KEY", "from-env")
+
+	key, err := ohdear.EnvCredentials("OHDEAR_TEST_KEY").Key(context.Background())
+	if err != nil || key != "from-env" {
+		t.Errorf("Key() = %q, %v, want %q", key, err, "from-env")
+	}
+
+	if _, err = ohdear.EnvCredentials("OHDEAR_TEST_MISSING").Key(context.Background()); !errors.Is(err, ohdear.ErrEmptyKey) {
+		t.Errorf("Key() of unset variable error = %v, want %v", err, ohdear.ErrEmptyKey)
+	}
+}
+
+func TestCommandCredentials(t *testing.T) {
+	t.Parallel()
+
+	creds := ohdear.NewCommandCredentials("echo", "from-command")
+
+	key, err := creds.Key(context.Background())
+	if err != nil || key != "from-command" {
+		t.Errorf("Key() = %q, %v, want %q", key, err, "from-command")
+	}
+}
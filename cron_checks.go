@@ -0,0 +1,216 @@
+package ohdear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+)
+
+// CronChecksService handles communication with the /cron-checks endpoint of
+// Oh Dear's API.
+type CronChecksService service
+
+// CronCheckType is the way a cron check defines when pings are expected.
+type CronCheckType string
+
+// Cron check types supported by the API.
+const (
+	// CronCheckTypeSimple expects a ping every FrequencyInMinutes.
+	CronCheckTypeSimple CronCheckType = "simple"
+
+	// CronCheckTypeCron expects a ping on the schedule of CronExpression.
+	CronCheckTypeCron CronCheckType = "cron"
+)
+
+// CronCheck represents a scheduled task monitored by Oh Dear. The task pings
+// PingURL when it runs, and Oh Dear notifies when a ping is late by more than
+// GraceTimeInMinutes.
+type CronCheck struct {
+	UUID               string        `json:"uuid,omitempty"`
+	Name               string        `json:"name,omitempty"`
+	Type               CronCheckType `json:"type,omitempty"`
+	CronExpression     string        `json:"cron_expression,omitempty"`
+	Description        string        `json:"description,omitempty"`
+	ServerTimezone     string        `json:"server_timezone,omitempty"`
+	PingURL            string        `json:"ping_url,omitempty"`
+	ID                 int           `json:"id,omitempty"`
+	FrequencyInMinutes int           `json:"frequency_in_minutes,omitempty"`
+	GraceTimeInMinutes int           `json:"grace_time_in_minutes,omitempty"`
+}
+
+// validate checks that the check sets the fields its type requires.
+func (c *CronCheck) validate() error {
+	switch c.Type {
+	case CronCheckTypeSimple:
+		if c.FrequencyInMinutes < 1 {
+			return fmt.Errorf("%w: simple checks need a frequency", ErrInvalidCronCheckType)
+		}
+	case CronCheckTypeCron:
+		if c.CronExpression == "" {
+			return fmt.Errorf("%w: cron checks need an expression", ErrInvalidCronCheckType)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidCronCheckType, c.Type)
+	}
+
+	return nil
+}
+
+// List returns the cron checks of a site.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#cron-job-monitoring
+func (s *CronChecksService) List(ctx context.Context, siteID uint, opts ...RequestOption) ([]CronCheck, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "CronChecks.List")
+
+	if siteID == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(siteID)) + endpoint.CronChecks
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var checks struct {
+		Data []CronCheck `json:"data"`
+	}
+
+	if err := json.Unmarshal(ret.Body, &checks); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal cron checks: %w", err)
+	}
+
+	return checks.Data, ret, nil
+}
+
+// Add adds a cron check to a site.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#cron-job-monitoring
+func (s *CronChecksService) Add(ctx context.Context, siteID uint, check *CronCheck, opts ...RequestOption) (*CronCheck, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "CronChecks.Add")
+
+	if siteID == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	if check == nil {
+		return nil, nil, ErrNilCronCheck
+	}
+
+	if err := check.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(siteID)) + endpoint.CronChecks
+
+	return s.send(ctx, http.MethodPost, path, check, opts...)
+}
+
+// Update updates the settings of a cron check by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#cron-job-monitoring
+func (s *CronChecksService) Update(ctx context.Context, id uint, check *CronCheck, opts ...RequestOption) (*CronCheck, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "CronChecks.Update")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidCronCheckID
+	}
+
+	if check == nil {
+		return nil, nil, ErrNilCronCheck
+	}
+
+	if err := check.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.CronChecks + "/" + strconv.Itoa(int(id))
+
+	return s.send(ctx, http.MethodPut, path, check, opts...)
+}
+
+// Remove removes a cron check by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#cron-job-monitoring
+func (s *CronChecksService) Remove(ctx context.Context, id uint, opts ...RequestOption) (*Response, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "CronChecks.Remove")
+
+	if id == 0 {
+		return nil, ErrInvalidCronCheckID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.CronChecks + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// send sends a cron check to the given path and returns the check in the
+// response.
+func (s *CronChecksService) send(ctx context.Context, method, path string, check *CronCheck, opts ...RequestOption) (*CronCheck, *Response, error) {
+	payload, err := json.Marshal(check)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal cron check: %w", err)
+	}
+
+	req, err := s.client.NewRequest(ctx, method, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sentCheck CronCheck
+	if err := json.Unmarshal(ret.Body, &sentCheck); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal cron check: %w", err)
+	}
+
+	return &sentCheck, ret, nil
+}
@@ -0,0 +1,72 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+func TestCronChecksService(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		srv = ohdeartest.NewServer(nil)
+	)
+
+	defer srv.Close()
+
+	client := srv.Client()
+	site := srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+
+	added, _, err := client.CronChecks.Add(ctx, uint(site.ID), &ohdear.CronCheck{
+		Name:               "backup",
+		Type:               ohdear.CronCheckTypeSimple,
+		FrequencyInMinutes: 60,
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if added.ID == 0 || !strings.HasSuffix(added.PingURL, added.UUID) {
+		t.Errorf("Add() = %+v, want an ID and a ping URL", added)
+	}
+
+	updated, _, err := client.CronChecks.Update(ctx, uint(added.ID), &ohdear.CronCheck{
+		Name:           "backup",
+		Type:           ohdear.CronCheckTypeCron,
+		CronExpression: "0 3 * * *",
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if updated.CronExpression != "0 3 * * *" || updated.PingURL != added.PingURL {
+		t.Errorf("Update() = %+v, want the new expression and the same ping URL", updated)
+	}
+
+	checks, _, err := client.CronChecks.List(ctx, uint(site.ID))
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(checks) != 1 || checks[0].Type != ohdear.CronCheckTypeCron {
+		t.Errorf("List() = %+v, want the updated check", checks)
+	}
+
+	if _, err = client.CronChecks.Remove(ctx, uint(added.ID)); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	_, _, err = client.CronChecks.Add(ctx, uint(site.ID), &ohdear.CronCheck{
+		Name: "backup",
+		Type: ohdear.CronCheckTypeCron,
+	})
+	if !errors.Is(err, ohdear.ErrInvalidCronCheckType) {
+		t.Errorf("Add() error = %v, want %v", err, ohdear.ErrInvalidCronCheckType)
+	}
+}
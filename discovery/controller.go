@@ -0,0 +1,264 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// Default values for the ControllerOptions struct.
+const (
+	DefaultInterval time.Duration = 5 * time.Minute
+	DefaultOwnerTag string        = "managed-by:ohdear-discovery"
+)
+
+const (
+	// ErrClientRequired is returned when a Controller is created without a
+	// client.
+	ErrClientRequired xerrors.Error = "client cannot be nil"
+
+	// ErrProviderRequired is returned when a Controller is created without
+	// a provider.
+	ErrProviderRequired xerrors.Error = "provider cannot be nil"
+)
+
+// ControllerOptions configures a Controller.
+type ControllerOptions struct {
+	// Logger logs the changes made by Run and the reconciliations that
+	// failed.
+	//
+	// This field is optional. It defaults to slog.Default.
+	Logger *slog.Logger
+
+	// RequestOptions are applied to every request made to the API.
+	//
+	// This field is optional.
+	RequestOptions []ohdear.RequestOption
+
+	// OwnerTag is added to every site the controller registers, so they can
+	// be told apart from sites added by other means. It's never added to
+	// sites that already exist.
+	//
+	// This field is optional. It defaults to DefaultOwnerTag.
+	OwnerTag string
+
+	// Interval is how often Run reconciles the sites.
+	//
+	// This field is optional. It defaults to DefaultInterval.
+	Interval time.Duration
+
+	// Prune removes the sites tagged with OwnerTag that are no longer
+	// discovered. Sites without the tag are never removed.
+	//
+	// This field is optional.
+	Prune bool
+
+	// DryRun reports the changes a reconciliation would make without making
+	// them.
+	//
+	// This field is optional.
+	DryRun bool
+}
+
+// Result is the outcome of a reconciliation.
+type Result struct {
+	// Diff is the difference between the discovered and the existing sites
+	// the reconciliation acted on.
+	Diff *Diff
+
+	// Added holds the sites that were added.
+	Added []ohdear.Site
+
+	// Updated holds the sites whose tags were updated.
+	Updated []ohdear.Site
+
+	// Removed holds the sites that were removed because they're no longer
+	// discovered.
+	Removed []ohdear.Site
+}
+
+// Controller reconciles the sites of an account with the sites discovered by
+// a Provider. Discovered sites are added if they're missing and given their
+// discovered tags if they lack them. Other settings of existing sites are
+// left alone.
+type Controller struct {
+	client   *ohdear.Client
+	provider Provider
+	logger   *slog.Logger
+	opts     []ohdear.RequestOption
+	ownerTag string
+	interval time.Duration
+	prune    bool
+	dryRun   bool
+}
+
+// NewController returns a new Controller reconciling the sites of client
+// with the ones discovered by provider.
+func NewController(client *ohdear.Client, provider Provider, opts *ControllerOptions) (*Controller, error) {
+	if client == nil {
+		return nil, ErrClientRequired
+	}
+
+	if provider == nil {
+		return nil, ErrProviderRequired
+	}
+
+	var o ControllerOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	c := &Controller{
+		client:   client,
+		provider: provider,
+		logger:   o.Logger,
+		opts:     append([]ohdear.RequestOption(nil), o.RequestOptions...),
+		ownerTag: o.OwnerTag,
+		interval: o.Interval,
+		prune:    o.Prune,
+		dryRun:   o.DryRun,
+	}
+
+	if c.logger == nil {
+		c.logger = slog.Default()
+	}
+
+	if c.ownerTag == "" {
+		c.ownerTag = DefaultOwnerTag
+	}
+
+	if c.interval <= 0 {
+		c.interval = DefaultInterval
+	}
+
+	return c, nil
+}
+
+// Reconcile discovers the sites and brings the account in line with them
+// once. Failing to change a site doesn't stop the others from being changed;
+// the errors are joined and returned with the result.
+func (c *Controller) Reconcile(ctx context.Context) (*Result, error) {
+	discovered, err := c.provider.Sites(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not discover sites: %w", err)
+	}
+
+	existing, err := c.client.Sites.ListAll(ctx, c.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list existing sites: %w", err)
+	}
+
+	var (
+		diff   = Compare(discovered, existing)
+		result = &Result{Diff: diff}
+		errs   []error
+	)
+
+	// Only the sites the controller registers are marked as its own, so
+	// sites added by other means are never pruned.
+	for i := range diff.Missing {
+		site := &diff.Missing[i]
+		site.Tags = append(slices.Clone(site.Tags), missingTags(site.Tags, []string{c.ownerTag})...)
+	}
+
+	if c.prune {
+		diff.Undiscovered = slices.DeleteFunc(diff.Undiscovered, func(site ohdear.Site) bool {
+			return !slices.Contains(site.Tags, c.ownerTag)
+		})
+	} else {
+		diff.Undiscovered = nil
+	}
+
+	if c.dryRun {
+		return result, nil
+	}
+
+	for i := range diff.Missing {
+		site := diff.Missing[i]
+
+		added, _, err := c.client.Sites.Add(ctx, &site, c.opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not add %s: %w", site.URL, err))
+
+			continue
+		}
+
+		result.Added = append(result.Added, *added)
+	}
+
+	for i := range diff.Changed {
+		change := &diff.Changed[i]
+
+		updated, _, err := c.client.Sites.Update(ctx, uint(change.Current.ID), &ohdear.Site{
+			Tags: append(slices.Clone(change.Current.Tags), change.Tags...),
+		}, c.opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not update %s: %w", change.Current.URL, err))
+
+			continue
+		}
+
+		result.Updated = append(result.Updated, *updated)
+	}
+
+	for i := range diff.Undiscovered {
+		site := diff.Undiscovered[i]
+
+		if _, err := c.client.Sites.Remove(ctx, uint(site.ID), c.opts...); err != nil {
+			errs = append(errs, fmt.Errorf("could not remove %s: %w", site.URL, err))
+
+			continue
+		}
+
+		result.Removed = append(result.Removed, site)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// Run reconciles the sites every interval until ctx is done. Failed
+// reconciliations are logged and retried on the next interval.
+func (c *Controller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.reconcile(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile runs Reconcile and logs its outcome.
+func (c *Controller) reconcile(ctx context.Context) {
+	result, err := c.Reconcile(ctx)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "could not reconcile sites", slog.Any("error", err))
+	}
+
+	if result == nil {
+		return
+	}
+
+	for _, site := range result.Added {
+		c.logger.InfoContext(ctx, "added site", slog.String("url", site.URL), slog.Int("id", site.ID))
+	}
+
+	for _, site := range result.Updated {
+		c.logger.InfoContext(ctx, "updated site tags", slog.String("url", site.URL), slog.Int("id", site.ID))
+	}
+
+	for _, site := range result.Removed {
+		c.logger.InfoContext(ctx, "removed site", slog.String("url", site.URL), slog.Int("id", site.ID))
+	}
+}
@@ -0,0 +1,185 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/discovery"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+// staticProvider is a Provider returning a fixed set of sites.
+type staticProvider []ohdear.Site
+
+func (p staticProvider) Sites(_ context.Context) ([]ohdear.Site, error) {
+	return append([]ohdear.Site(nil), p...), nil
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	discovered := []ohdear.Site{
+		{URL: "https://example.com", TeamID: 1, Tags: []string{"web"}},
+		{URL: "https://example.org", TeamID: 1, Tags: []string{"web"}},
+		{URL: "https://example.net", TeamID: 1},
+		{URL: "https://example.info"},
+	}
+
+	existing := []ohdear.Site{
+		{ID: 1, URL: "https://EXAMPLE.com/", TeamID: 1, Tags: []string{"web", "eu"}},
+		{ID: 2, URL: "https://example.org", TeamID: 1},
+		{ID: 3, URL: "https://example.net", TeamID: 2},
+		{ID: 4, URL: "https://example.info", TeamID: 3},
+		{ID: 5, URL: "https://example.dev", TeamID: 1},
+	}
+
+	diff := discovery.Compare(discovered, existing)
+
+	ids := func(sites []ohdear.Site) []int {
+		var ids []int
+
+		for _, site := range sites {
+			ids = append(ids, site.ID)
+		}
+
+		return ids
+	}
+
+	if got := ids(diff.Unchanged); !reflect.DeepEqual(got, []int{1, 4}) {
+		t.Errorf("Unchanged = %v, want [1 4]", got)
+	}
+
+	if len(diff.Changed) != 1 || diff.Changed[0].Current.ID != 2 || !reflect.DeepEqual(diff.Changed[0].Tags, []string{"web"}) {
+		t.Errorf("Changed = %+v, want site 2 lacking [web]", diff.Changed)
+	}
+
+	if len(diff.Missing) != 1 || diff.Missing[0].URL != "https://example.net" {
+		t.Errorf("Missing = %+v, want https://example.net", diff.Missing)
+	}
+
+	if got := ids(diff.Undiscovered); !reflect.DeepEqual(got, []int{3, 5}) {
+		t.Errorf("Undiscovered = %v, want [3 5]", got)
+	}
+
+	if diff.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+}
+
+func TestController(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	server := ohdeartest.NewServer(nil)
+	defer server.Close()
+
+	var (
+		tagged = server.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+		stale  = server.AddSite(&ohdear.Site{URL: "https://old.example.com", TeamID: 1, Tags: []string{discovery.DefaultOwnerTag}})
+		manual = server.AddSite(&ohdear.Site{URL: "https://manual.example.com", TeamID: 1})
+	)
+
+	provider := staticProvider{
+		{URL: "https://example.com", TeamID: 1, Tags: []string{"web"}},
+		{URL: "https://new.example.com", TeamID: 1, Tags: []string{"web"}},
+	}
+
+	if _, err := discovery.NewController(nil, provider, nil); !errors.Is(err, discovery.ErrClientRequired) {
+		t.Errorf("NewController() without client error = %v, want %v", err, discovery.ErrClientRequired)
+	}
+
+	dryRun, err := discovery.NewController(server.Client(), provider, &discovery.ControllerOptions{
+		Prune:  true,
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("NewController() error = %v", err)
+	}
+
+	result, err := dryRun.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile() with DryRun error = %v", err)
+	}
+
+	if len(result.Diff.Missing) != 1 || len(result.Diff.Changed) != 1 || len(result.Diff.Undiscovered) != 1 {
+		t.Errorf("Reconcile() with DryRun diff = %+v, want one missing, changed and undiscovered site", result.Diff)
+	}
+
+	if got := len(server.Sites()); got != 3 {
+		t.Fatalf("Reconcile() with DryRun left %d sites, want 3", got)
+	}
+
+	controller, err := discovery.NewController(server.Client(), provider, &discovery.ControllerOptions{
+		Prune: true,
+	})
+	if err != nil {
+		t.Fatalf("NewController() error = %v", err)
+	}
+
+	result, err = controller.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(result.Added) != 1 || result.Added[0].URL != "https://new.example.com" {
+		t.Errorf("Reconcile() added = %+v, want https://new.example.com", result.Added)
+	}
+
+	if len(result.Removed) != 1 || result.Removed[0].ID != stale.ID {
+		t.Errorf("Reconcile() removed = %+v, want site %d", result.Removed, stale.ID)
+	}
+
+	site, _ := server.Site(tagged.ID)
+
+	tags := append([]string(nil), site.Tags...)
+	sort.Strings(tags)
+
+	if want := []string{"web"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("updated site tags = %v, want %v", tags, want)
+	}
+
+	if added, _ := server.Site(result.Added[0].ID); !slices.Contains(added.Tags, discovery.DefaultOwnerTag) {
+		t.Errorf("added site tags = %v, want %s", added.Tags, discovery.DefaultOwnerTag)
+	}
+
+	if _, ok := server.Site(manual.ID); !ok {
+		t.Error("Reconcile() removed a site it doesn't own")
+	}
+
+	result, err = controller.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("second Reconcile() error = %v", err)
+	}
+
+	if !result.Diff.Empty() || len(result.Removed) != 0 {
+		t.Errorf("second Reconcile() diff = %+v, want no changes", result.Diff)
+	}
+
+	// The pre-existing site is no longer discovered, but it wasn't
+	// registered by the controller, so it's kept.
+	shrunk, err := discovery.NewController(server.Client(), provider[1:], &discovery.ControllerOptions{
+		Prune: true,
+	})
+	if err != nil {
+		t.Fatalf("NewController() error = %v", err)
+	}
+
+	result, err = shrunk.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile() after the site disappeared error = %v", err)
+	}
+
+	if len(result.Removed) != 0 {
+		t.Errorf("Reconcile() removed %+v, want no sites removed", result.Removed)
+	}
+
+	if _, ok := server.Site(tagged.ID); !ok {
+		t.Error("Reconcile() removed a site that existed before the controller")
+	}
+}
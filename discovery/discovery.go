@@ -0,0 +1,228 @@
+// Package discovery finds the sites that should be monitored by Oh Dear in
+// other sources of truth, such as Kubernetes manifests or DNS zone files,
+// compares them with the sites of an account and registers the ones that are
+// missing.
+//
+// A Provider returns the discovered sites, Compare tells which of them are
+// missing from an account, and a Controller keeps an account in sync with a
+// provider, so new sites are monitored without anyone having to add them.
+// Compare can also be used on its own to report unmonitored hostnames:
+//
+//	provider, err := discovery.NewZoneProvider(&discovery.ZoneOptions{
+//		Paths:   []string{"zones/example.com.zone"},
+//		Exclude: []string{"mail.*"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	discovered, err := provider.Sites(ctx)
+//	if err != nil {
+//		return err
+//	}
+//
+//	existing, err := client.Sites.ListAll(ctx)
+//	if err != nil {
+//		return err
+//	}
+//
+//	for _, site := range discovery.Compare(discovered, existing).Missing {
+//		fmt.Println("not monitored:", site.URL)
+//	}
+//
+// Kubernetes manifests can be reconciled continuously:
+//
+//	provider, err := discovery.NewKubernetesProvider(&discovery.KubernetesOptions{
+//		Paths:  []string{"./rendered"},
+//		TeamID: 42,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	controller, err := discovery.NewController(client, provider, &discovery.ControllerOptions{
+//		Prune: true,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	go controller.Run(ctx)
+package discovery
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
+)
+
+// Provider discovers the sites that should be monitored.
+type Provider interface {
+	// Sites returns the discovered sites. Only the URL, team and tags of the
+	// sites are expected to be set.
+	Sites(ctx context.Context) ([]ohdear.Site, error)
+}
+
+// Change is a discovered site that exists in the account but lacks some of
+// its discovered tags.
+type Change struct {
+	// Current is the site as it exists in the account.
+	Current ohdear.Site
+
+	// Discovered is the site as it was discovered.
+	Discovered ohdear.Site
+
+	// Tags holds the tags of the discovered site the current site lacks.
+	Tags []string
+}
+
+// Diff is the difference between discovered sites and the existing sites of
+// an account.
+type Diff struct {
+	// Missing holds the discovered sites that don't exist in the account.
+	Missing []ohdear.Site
+
+	// Changed holds the discovered sites that exist in the account but lack
+	// some of their tags.
+	Changed []Change
+
+	// Unchanged holds the existing sites that match a discovered site.
+	Unchanged []ohdear.Site
+
+	// Undiscovered holds the existing sites that match no discovered site.
+	Undiscovered []ohdear.Site
+}
+
+// Empty reports whether there are no missing or changed sites.
+func (d *Diff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Changed) == 0
+}
+
+// Compare compares discovered sites with the existing sites of an account.
+// Sites are matched by normalized URL, and by team when the discovered site
+// has one.
+func Compare(discovered, existing []ohdear.Site) *Diff {
+	var (
+		diff    = &Diff{}
+		byURL   = make(map[string][]int, len(existing))
+		matched = make([]bool, len(existing))
+	)
+
+	for i := range existing {
+		key := normalizeURL(existing[i].URL)
+		byURL[key] = append(byURL[key], i)
+	}
+
+	for i := range discovered {
+		site := discovered[i]
+
+		index := -1
+
+		for _, j := range byURL[normalizeURL(site.URL)] {
+			if site.TeamID == 0 || site.TeamID == existing[j].TeamID {
+				index = j
+
+				break
+			}
+		}
+
+		if index == -1 {
+			diff.Missing = append(diff.Missing, site)
+
+			continue
+		}
+
+		matched[index] = true
+
+		current := existing[index]
+
+		if tags := missingTags(current.Tags, site.Tags); len(tags) > 0 {
+			diff.Changed = append(diff.Changed, Change{
+				Current:    current,
+				Discovered: site,
+				Tags:       tags,
+			})
+
+			continue
+		}
+
+		diff.Unchanged = append(diff.Unchanged, current)
+	}
+
+	for i := range existing {
+		if !matched[i] {
+			diff.Undiscovered = append(diff.Undiscovered, existing[i])
+		}
+	}
+
+	return diff
+}
+
+// missingTags returns the tags in want that are not in have.
+func missingTags(have, want []string) []string {
+	var missing []string
+
+	for _, tag := range want {
+		if !slices.Contains(have, tag) && !slices.Contains(missing, tag) {
+			missing = append(missing, tag)
+		}
+	}
+
+	return missing
+}
+
+// mergeSites merges sites with the same URL and team, combining their tags,
+// and returns them sorted by URL.
+func mergeSites(sites []ohdear.Site) []ohdear.Site {
+	var (
+		merged = make([]ohdear.Site, 0, len(sites))
+		index  = make(map[string]int, len(sites))
+	)
+
+	for i := range sites {
+		key := strconv.Itoa(sites[i].TeamID) + " " + normalizeURL(sites[i].URL)
+
+		j, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, sites[i])
+
+			continue
+		}
+
+		merged[j].Tags = append(merged[j].Tags, missingTags(merged[j].Tags, sites[i].Tags)...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].URL < merged[j].URL
+	})
+
+	return merged
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" && !slices.Contains(list, entry) {
+			list = append(list, entry)
+		}
+	}
+
+	return list
+}
+
+// normalizeURL returns the form of rawURL used to match sites.
+func normalizeURL(rawURL string) string {
+	if normalized, err := urlutil.Normalize(rawURL); err == nil {
+		return normalized
+	}
+
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(rawURL)), "/")
+}
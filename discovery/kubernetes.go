@@ -0,0 +1,351 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// Annotations read from Kubernetes resources.
+const (
+	// AnnotationMonitor tells whether the hosts of a resource are
+	// monitored. Unless KubernetesOptions.MonitorAll is set, only resources
+	// with the annotation set to "true" are discovered.
+	AnnotationMonitor = "ohdear.app/monitor"
+
+	// AnnotationTags holds a comma-separated list of tags added to the sites
+	// of a resource.
+	AnnotationTags = "ohdear.app/tags"
+
+	// AnnotationURL holds a comma-separated list of URLs monitored instead
+	// of the ones derived from the hosts of a resource. It's the only way to
+	// discover Services, which have no hosts.
+	AnnotationURL = "ohdear.app/url"
+)
+
+const (
+	// ErrPathsRequired is returned when a KubernetesProvider is created
+	// without paths to read manifests from.
+	ErrPathsRequired xerrors.Error = "at least one path is required"
+
+	// ErrInvalidManifest is returned when a manifest can't be decoded.
+	ErrInvalidManifest xerrors.Error = "invalid manifest"
+
+	// ErrInvalidAnnotation is returned when an annotation of a resource has
+	// an invalid value.
+	ErrInvalidAnnotation xerrors.Error = "invalid annotation"
+)
+
+// manifestExtensions are the extensions of the files read from directories.
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// KubernetesOptions configures a KubernetesProvider.
+type KubernetesOptions struct {
+	// Paths holds the manifest files and directories to read. Directories
+	// are walked recursively and their files with a .yaml, .yml or .json
+	// extension are read, which makes the output of helm template usable
+	// as-is.
+	Paths []string
+
+	// Tags are added to every discovered site.
+	//
+	// This field is optional.
+	Tags []string
+
+	// TeamID is the team discovered sites belong to.
+	//
+	// This field is optional, but sites can't be added without a team.
+	TeamID int
+
+	// MonitorAll discovers the hosts of every resource, not only the ones
+	// with AnnotationMonitor set to "true". Resources with the annotation
+	// set to "false" are still skipped.
+	//
+	// This field is optional.
+	MonitorAll bool
+}
+
+// KubernetesProvider discovers sites in the Ingress and Gateway API
+// HTTPRoute resources of Kubernetes manifests.
+//
+// Hosts of Ingresses listed in their TLS section are monitored over HTTPS and
+// other hosts over HTTP. Hosts of HTTPRoutes are monitored over HTTPS, as TLS
+// is configured on their Gateway. Wildcard hosts are skipped.
+type KubernetesProvider struct {
+	opts KubernetesOptions
+}
+
+// Compile-time check to ensure KubernetesProvider implements the Provider
+// interface.
+var _ Provider = (*KubernetesProvider)(nil)
+
+// NewKubernetesProvider returns a new KubernetesProvider with the given
+// options.
+func NewKubernetesProvider(opts *KubernetesOptions) (*KubernetesProvider, error) {
+	if opts == nil || len(opts.Paths) == 0 {
+		return nil, ErrPathsRequired
+	}
+
+	o := *opts
+	o.Paths = append([]string(nil), opts.Paths...)
+	o.Tags = append([]string(nil), opts.Tags...)
+
+	return &KubernetesProvider{
+		opts: o,
+	}, nil
+}
+
+// Sites implements the Provider interface. Manifests are read again on every
+// call, so changes to them are picked up.
+func (p *KubernetesProvider) Sites(ctx context.Context) ([]ohdear.Site, error) {
+	var sites []ohdear.Site
+
+	for _, root := range p.opts.Paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			// Files given explicitly are read whatever their extension.
+			if path != root && !hasManifestExtension(path) {
+				return nil
+			}
+
+			found, err := p.readFile(path)
+			if err != nil {
+				return err
+			}
+
+			sites = append(sites, found...)
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+	}
+
+	return mergeSites(sites), nil
+}
+
+// Read returns the sites discovered in the manifests read from r, which may
+// hold multiple YAML or JSON documents.
+func (p *KubernetesProvider) Read(r io.Reader) ([]ohdear.Site, error) {
+	resources, err := decodeManifests(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var sites []ohdear.Site
+
+	for i := range resources {
+		found, err := p.sites(&resources[i])
+		if err != nil {
+			return nil, err
+		}
+
+		sites = append(sites, found...)
+	}
+
+	return mergeSites(sites), nil
+}
+
+// readFile returns the sites discovered in the manifests of a file.
+func (p *KubernetesProvider) readFile(path string) ([]ohdear.Site, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	defer f.Close()
+
+	sites, err := p.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return sites, nil
+}
+
+// sites returns the sites discovered in a resource.
+func (p *KubernetesProvider) sites(res *manifest) ([]ohdear.Site, error) {
+	annotations := res.Metadata.Annotations
+
+	monitor := p.opts.MonitorAll
+
+	if value, ok := annotations[AnnotationMonitor]; ok {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s on %s: %q", ErrInvalidAnnotation, AnnotationMonitor, res.id(), value)
+		}
+
+		monitor = parsed
+	}
+
+	if !monitor {
+		return nil, nil
+	}
+
+	urls := splitList(annotations[AnnotationURL])
+	if len(urls) == 0 {
+		urls = res.urls()
+	}
+
+	tags := append(append([]string(nil), p.opts.Tags...), splitList(annotations[AnnotationTags])...)
+
+	sites := make([]ohdear.Site, 0, len(urls))
+
+	for _, uri := range urls {
+		if err := urlutil.Validate(uri); err != nil {
+			return nil, fmt.Errorf("%w: %s on %s: %w", ErrInvalidAnnotation, AnnotationURL, res.id(), err)
+		}
+
+		sites = append(sites, ohdear.Site{
+			URL:    uri,
+			TeamID: p.opts.TeamID,
+			Tags:   missingTags(nil, tags),
+		})
+	}
+
+	return sites, nil
+}
+
+// manifest is the part of a Kubernetes resource used to discover sites.
+type manifest struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Annotations map[string]string `yaml:"annotations"`
+		Name        string            `yaml:"name"`
+		Namespace   string            `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Rules []struct {
+			Host string `yaml:"host"`
+		} `yaml:"rules"`
+		TLS []struct {
+			Hosts []string `yaml:"hosts"`
+		} `yaml:"tls"`
+		Hostnames []string `yaml:"hostnames"`
+	} `yaml:"spec"`
+	Items []manifest `yaml:"items"`
+}
+
+// id returns the kind, namespace and name of the resource, for errors.
+func (m *manifest) id() string {
+	if m.Metadata.Namespace == "" {
+		return m.Kind + "/" + m.Metadata.Name
+	}
+
+	return m.Kind + "/" + m.Metadata.Namespace + "/" + m.Metadata.Name
+}
+
+// urls returns the URLs derived from the hosts of the resource.
+func (m *manifest) urls() []string {
+	var urls []string
+
+	add := func(scheme, host string) {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || strings.Contains(host, "*") {
+			return
+		}
+
+		if uri := scheme + "://" + host; !slices.Contains(urls, uri) {
+			urls = append(urls, uri)
+		}
+	}
+
+	switch m.Kind {
+	case "Ingress":
+		for _, rule := range m.Spec.Rules {
+			scheme := "http"
+			if m.tls(rule.Host) {
+				scheme = "https"
+			}
+
+			add(scheme, rule.Host)
+		}
+	case "HTTPRoute":
+		for _, host := range m.Spec.Hostnames {
+			add("https", host)
+		}
+	}
+
+	return urls
+}
+
+// tls reports whether the TLS section of an Ingress covers host.
+func (m *manifest) tls(host string) bool {
+	for _, tls := range m.Spec.TLS {
+		for _, h := range tls.Hosts {
+			if strings.EqualFold(h, host) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// decodeManifests decodes the resources of a multi-document YAML or JSON
+// stream, flattening lists and skipping empty documents and unsupported
+// kinds.
+func decodeManifests(r io.Reader) ([]manifest, error) {
+	var (
+		decoder   = yaml.NewDecoder(r)
+		resources []manifest
+	)
+
+	for {
+		var m manifest
+
+		err := decoder.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			return resources, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+		}
+
+		resources = appendResources(resources, &m)
+	}
+}
+
+// appendResources appends m, or the items of m if it's a list, to resources.
+func appendResources(resources []manifest, m *manifest) []manifest {
+	switch {
+	case strings.HasSuffix(m.Kind, "List"):
+		for i := range m.Items {
+			resources = appendResources(resources, &m.Items[i])
+		}
+	case m.Kind == "Ingress", m.Kind == "HTTPRoute", m.Kind == "Service":
+		resources = append(resources, *m)
+	}
+
+	return resources
+}
+
+// hasManifestExtension reports whether path has the extension of a manifest
+// file.
+func hasManifestExtension(path string) bool {
+	return slices.Contains(manifestExtensions, strings.ToLower(filepath.Ext(path)))
+}
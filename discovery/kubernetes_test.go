@@ -0,0 +1,139 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/discovery"
+)
+
+func TestKubernetesProvider(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{"testdata/rendered", "testdata/list.json"}
+
+	tests := []struct {
+		name string
+		opts *discovery.KubernetesOptions
+		want []ohdear.Site
+	}{
+		{
+			name: "Annotated resources",
+			opts: &discovery.KubernetesOptions{
+				Paths:  paths,
+				Tags:   []string{"k8s"},
+				TeamID: 1,
+			},
+			want: []ohdear.Site{
+				{URL: "http://legacy.example.com", TeamID: 1, Tags: []string{"k8s", "shop", "production"}},
+				{URL: "https://api.example.com", TeamID: 1, Tags: []string{"k8s", "api"}},
+				{URL: "https://blog.example.com", TeamID: 1, Tags: []string{"k8s"}},
+				{URL: "https://shop.example.com", TeamID: 1, Tags: []string{"k8s", "api", "shop", "production"}},
+				{URL: "https://status.example.com/health", TeamID: 1, Tags: []string{"k8s"}},
+			},
+		},
+		{
+			name: "Monitor all resources",
+			opts: &discovery.KubernetesOptions{
+				Paths:      []string{"testdata/rendered/templates/ingress.yaml", "testdata/list.json"},
+				MonitorAll: true,
+			},
+			want: []ohdear.Site{
+				{URL: "http://admin.example.com"},
+				{URL: "http://legacy.example.com", Tags: []string{"shop", "production"}},
+				{URL: "https://blog.example.com"},
+				{URL: "https://shop.example.com", Tags: []string{"shop", "production"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := discovery.NewKubernetesProvider(tt.opts)
+			if err != nil {
+				t.Fatalf("NewKubernetesProvider() error = %v", err)
+			}
+
+			got, err := provider.Sites(context.Background())
+			if err != nil {
+				t.Fatalf("Sites() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sites() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubernetesProvider_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := discovery.NewKubernetesProvider(nil); !errors.Is(err, discovery.ErrPathsRequired) {
+		t.Errorf("NewKubernetesProvider(nil) error = %v, want %v", err, discovery.ErrPathsRequired)
+	}
+
+	provider, err := discovery.NewKubernetesProvider(&discovery.KubernetesOptions{
+		Paths: []string{"testdata/missing"},
+	})
+	if err != nil {
+		t.Fatalf("NewKubernetesProvider() error = %v", err)
+	}
+
+	if _, err = provider.Sites(context.Background()); err == nil {
+		t.Error("Sites() with a missing path returned no error")
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     error
+	}{
+		{
+			name:     "Invalid YAML",
+			manifest: "kind: [Ingress",
+			want:     discovery.ErrInvalidManifest,
+		},
+		{
+			name: "Invalid monitor annotation",
+			manifest: `kind: Ingress
+metadata:
+  name: shop
+  annotations:
+    ohdear.app/monitor: sometimes
+`,
+			want: discovery.ErrInvalidAnnotation,
+		},
+		{
+			name: "Invalid URL annotation",
+			manifest: `kind: Service
+metadata:
+  name: shop
+  annotations:
+    ohdear.app/monitor: "true"
+    ohdear.app/url: shop.example.com
+`,
+			want: discovery.ErrInvalidAnnotation,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := provider.Read(strings.NewReader(tt.manifest)); !errors.Is(err, tt.want) {
+				t.Errorf("Read() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
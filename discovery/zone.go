@@ -0,0 +1,547 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// MaxIncludeDepth is how deeply $INCLUDE directives can be nested.
+const MaxIncludeDepth = 16
+
+const (
+	// ErrInvalidZone is returned when a zone file can't be parsed.
+	ErrInvalidZone xerrors.Error = "invalid zone file"
+
+	// ErrInvalidPattern is returned when an include or exclude pattern is
+	// malformed.
+	ErrInvalidPattern xerrors.Error = "invalid pattern"
+)
+
+// Record types whose owner names are discovered as sites.
+const (
+	RecordTypeA     = "A"
+	RecordTypeAAAA  = "AAAA"
+	RecordTypeCNAME = "CNAME"
+)
+
+// Record is a resource record of a zone file.
+type Record struct {
+	// Name is the fully qualified owner name of the record, in lower case
+	// and without the trailing dot.
+	Name string
+
+	// Type is the type of the record, such as A or CNAME.
+	Type string
+
+	// Data holds the fields of the record data, as written in the file.
+	Data []string
+}
+
+// ZoneOptions configures a ZoneProvider.
+type ZoneOptions struct {
+	// Paths holds the zone files to read.
+	Paths []string
+
+	// Origin is the origin of relative names in zone files without an
+	// $ORIGIN directive.
+	//
+	// This field is optional, but zone files with relative names and no
+	// $ORIGIN can't be read without it.
+	Origin string
+
+	// Include holds the patterns hostnames must match to be discovered, as
+	// understood by path.Match, in which * also matches dots. Matching is
+	// case-insensitive.
+	//
+	// This field is optional. Every hostname is discovered by default.
+	Include []string
+
+	// Exclude holds the patterns of hostnames that are never discovered,
+	// even if they match Include.
+	//
+	// This field is optional.
+	Exclude []string
+
+	// Tags are added to every discovered site.
+	//
+	// This field is optional.
+	Tags []string
+
+	// TeamID is the team discovered sites belong to.
+	//
+	// This field is optional, but sites can't be added without a team.
+	TeamID int
+}
+
+// ZoneProvider discovers sites in the A, AAAA and CNAME records of RFC 1035
+// zone files, following BIND-style $INCLUDE directives. Every hostname is
+// monitored over HTTPS. Wildcard names and names with labels starting with an
+// underscore, such as _acme-challenge, are skipped. $GENERATE directives are
+// not expanded.
+type ZoneProvider struct {
+	opts ZoneOptions
+}
+
+// Compile-time check to ensure ZoneProvider implements the Provider
+// interface.
+var _ Provider = (*ZoneProvider)(nil)
+
+// NewZoneProvider returns a new ZoneProvider with the given options.
+func NewZoneProvider(opts *ZoneOptions) (*ZoneProvider, error) {
+	if opts == nil || len(opts.Paths) == 0 {
+		return nil, ErrPathsRequired
+	}
+
+	for _, pattern := range append(append([]string(nil), opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPattern, pattern)
+		}
+	}
+
+	o := *opts
+	o.Paths = append([]string(nil), opts.Paths...)
+	o.Include = lowerAll(opts.Include)
+	o.Exclude = lowerAll(opts.Exclude)
+	o.Tags = append([]string(nil), opts.Tags...)
+
+	return &ZoneProvider{
+		opts: o,
+	}, nil
+}
+
+// Sites implements the Provider interface. Zone files are read again on
+// every call, so changes to them are picked up.
+func (p *ZoneProvider) Sites(ctx context.Context) ([]ohdear.Site, error) {
+	var sites []ohdear.Site
+
+	for _, name := range p.opts.Paths {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		records, err := ReadZoneFile(name, p.opts.Origin)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, host := range p.Hostnames(records) {
+			uri := "https://" + host
+
+			if err = urlutil.Validate(uri); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+
+			sites = append(sites, ohdear.Site{
+				URL:    uri,
+				TeamID: p.opts.TeamID,
+				Tags:   append([]string(nil), p.opts.Tags...),
+			})
+		}
+	}
+
+	return mergeSites(sites), nil
+}
+
+// Hostnames returns the distinct owner names of the A, AAAA and CNAME
+// records that are hostnames and match the include and exclude patterns.
+func (p *ZoneProvider) Hostnames(records []Record) []string {
+	var (
+		hosts []string
+		seen  = make(map[string]bool, len(records))
+	)
+
+	for i := range records {
+		record := &records[i]
+
+		switch record.Type {
+		case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME:
+		default:
+			continue
+		}
+
+		if seen[record.Name] || !isHostname(record.Name) || !p.matches(record.Name) {
+			continue
+		}
+
+		seen[record.Name] = true
+		hosts = append(hosts, record.Name)
+	}
+
+	return hosts
+}
+
+// matches reports whether host matches the include and exclude patterns.
+func (p *ZoneProvider) matches(host string) bool {
+	for _, pattern := range p.opts.Exclude {
+		if ok, _ := path.Match(pattern, host); ok {
+			return false
+		}
+	}
+
+	if len(p.opts.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range p.opts.Include {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadZoneFile parses the zone file at name. Relative names are qualified
+// with origin until an $ORIGIN directive changes it, and $INCLUDE directives
+// are resolved relative to the directory of the including file.
+func ReadZoneFile(name, origin string) ([]Record, error) {
+	z := &zoneParser{}
+
+	if err := z.parseFile(name, canonicalName(origin), 0); err != nil {
+		return nil, err
+	}
+
+	return z.records, nil
+}
+
+// ParseZone parses a zone file read from r. Relative names are qualified
+// with origin until an $ORIGIN directive changes it, and $INCLUDE directives
+// are resolved relative to the working directory.
+func ParseZone(r io.Reader, origin string) ([]Record, error) {
+	z := &zoneParser{}
+
+	if err := z.parse(r, "", canonicalName(origin), 0); err != nil {
+		return nil, err
+	}
+
+	return z.records, nil
+}
+
+// zoneParser parses zone files and the files they include.
+type zoneParser struct {
+	records []Record
+}
+
+// parseFile parses the zone file at name.
+func (z *zoneParser) parseFile(name, origin string, depth int) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+	defer f.Close()
+
+	return z.parse(f, name, origin, depth)
+}
+
+// parse parses a zone file read from r, whose path is name, if any.
+func (z *zoneParser) parse(r io.Reader, name, origin string, depth int) error {
+	var (
+		scanner = bufio.NewScanner(r)
+		owner   string
+		entry   []string
+		indent  bool
+		parens  int
+		line    int
+		start   int
+	)
+
+	fail := func(format string, args ...any) error {
+		where := "line " + strconv.Itoa(start)
+		if name != "" {
+			where = name + ":" + strconv.Itoa(start)
+		}
+
+		return fmt.Errorf("%w: %s: %s", ErrInvalidZone, where, fmt.Sprintf(format, args...))
+	}
+
+	for scanner.Scan() {
+		line++
+
+		text := scanner.Text()
+
+		if parens == 0 {
+			start = line
+			indent = text != "" && (text[0] == ' ' || text[0] == '\t')
+		}
+
+		tokens, open, err := tokenize(text)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		entry = append(entry, tokens...)
+
+		if parens += open; parens < 0 {
+			return fail("unbalanced parentheses")
+		}
+
+		if parens > 0 || len(entry) == 0 {
+			continue
+		}
+
+		fields := entry
+		entry = nil
+
+		if strings.HasPrefix(fields[0], "$") && !indent {
+			switch strings.ToUpper(fields[0]) {
+			case "$ORIGIN":
+				if len(fields) < 2 {
+					return fail("$ORIGIN without a name")
+				}
+
+				qualified, ok := qualify(fields[1], origin)
+				if !ok {
+					return fail("relative $ORIGIN %q without an origin", fields[1])
+				}
+
+				origin = qualified
+			case "$INCLUDE":
+				if len(fields) < 2 {
+					return fail("$INCLUDE without a file")
+				}
+
+				if depth >= MaxIncludeDepth {
+					return fail("$INCLUDE nested more than %d times", MaxIncludeDepth)
+				}
+
+				included := fields[1]
+				if !filepath.IsAbs(included) && name != "" {
+					included = filepath.Join(filepath.Dir(name), included)
+				}
+
+				includeOrigin := origin
+
+				if len(fields) > 2 {
+					qualified, ok := qualify(fields[2], origin)
+					if !ok {
+						return fail("relative $INCLUDE origin %q without an origin", fields[2])
+					}
+
+					includeOrigin = qualified
+				}
+
+				// The origin of the including file is restored after the
+				// included one is read, as required by RFC 1035.
+				if err = z.parseFile(included, includeOrigin, depth+1); err != nil {
+					return err
+				}
+			case "$TTL", "$GENERATE":
+			default:
+				return fail("unknown directive %s", fields[0])
+			}
+
+			continue
+		}
+
+		if !indent {
+			qualified, ok := qualify(fields[0], origin)
+			if !ok {
+				return fail("relative name %q without an origin", fields[0])
+			}
+
+			owner = qualified
+			fields = fields[1:]
+		}
+
+		if owner == "" {
+			return fail("record without an owner name")
+		}
+
+		rrType, data, ok := recordType(fields)
+		if !ok {
+			return fail("record without a type")
+		}
+
+		z.records = append(z.records, Record{
+			Name: strings.TrimSuffix(owner, "."),
+			Type: rrType,
+			Data: data,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if parens > 0 {
+		return fail("unbalanced parentheses")
+	}
+
+	return nil
+}
+
+// tokenize splits a line of a zone file into fields, dropping comments and
+// parentheses, and returns how many more parentheses it opens than closes.
+func tokenize(line string) ([]string, int, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+		escaped bool
+		pending bool
+		open    int
+	)
+
+	flush := func() {
+		if pending {
+			tokens = append(tokens, current.String())
+			current.Reset()
+			pending = false
+		}
+	}
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			current.WriteRune(r)
+			escaped = true
+			pending = true
+		case r == '"':
+			quoted = !quoted
+			pending = true
+		case quoted:
+			current.WriteRune(r)
+		case r == ';':
+			flush()
+
+			return tokens, open, nil
+		case r == '(':
+			flush()
+			open++
+		case r == ')':
+			flush()
+			open--
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			current.WriteRune(r)
+			pending = true
+		}
+	}
+
+	if quoted {
+		return nil, 0, errors.New("unterminated quoted string")
+	}
+
+	flush()
+
+	return tokens, open, nil
+}
+
+// recordType returns the type and data of a record from its fields after the
+// owner name, skipping the optional TTL and class that may come in either
+// order.
+func recordType(fields []string) (rrType string, data []string, ok bool) {
+	for i, field := range fields {
+		if isTTL(field) || isClass(field) {
+			continue
+		}
+
+		return strings.ToUpper(field), fields[i+1:], true
+	}
+
+	return "", nil, false
+}
+
+// isTTL reports whether field is a TTL, such as 3600 or the BIND form 1h30m.
+func isTTL(field string) bool {
+	if field == "" || field[0] < '0' || field[0] > '9' {
+		return false
+	}
+
+	for _, r := range strings.ToLower(field) {
+		if (r < '0' || r > '9') && !strings.ContainsRune("smhdw", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isClass reports whether field is a DNS class.
+func isClass(field string) bool {
+	switch strings.ToUpper(field) {
+	case "IN", "CS", "CH", "HS":
+		return true
+	default:
+		return false
+	}
+}
+
+// qualify returns name as a fully qualified name ending with a dot, in lower
+// case, resolving @ and relative names against origin. It returns false if
+// name is relative and there's no origin.
+func qualify(name, origin string) (string, bool) {
+	if name == "@" {
+		return origin, origin != ""
+	}
+
+	name = strings.ToLower(name)
+
+	if strings.HasSuffix(name, ".") {
+		return name, true
+	}
+
+	if origin == "" {
+		return "", false
+	}
+
+	if origin == "." {
+		return name + ".", true
+	}
+
+	return name + "." + origin, true
+}
+
+// canonicalName returns name in lower case with a trailing dot, or an empty
+// string if name is empty.
+func canonicalName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
+
+// isHostname reports whether name can be the host of a website, which
+// excludes wildcards and service labels starting with an underscore.
+func isHostname(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || label == "*" || strings.HasPrefix(label, "_") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lowerAll returns a copy of list with every entry in lower case.
+func lowerAll(list []string) []string {
+	lowered := make([]string, 0, len(list))
+
+	for _, entry := range list {
+		lowered = append(lowered, strings.ToLower(entry))
+	}
+
+	return lowered
+}
@@ -0,0 +1,199 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/discovery"
+)
+
+func TestReadZoneFile(t *testing.T) {
+	t.Parallel()
+
+	records, err := discovery.ReadZoneFile("testdata/zones/example.com.zone", "")
+	if err != nil {
+		t.Fatalf("ReadZoneFile() error = %v", err)
+	}
+
+	find := func(name, rrType string) *discovery.Record {
+		for i := range records {
+			if records[i].Name == name && records[i].Type == rrType {
+				return &records[i]
+			}
+		}
+
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		owner    string
+		rrType   string
+		wantData []string
+	}{
+		{
+			name:     "SOA spanning lines",
+			owner:    "example.com",
+			rrType:   "SOA",
+			wantData: []string{"ns1.example.com.", "hostmaster.example.com.", "2024010101", "7200", "3600", "1209600", "3600"},
+		},
+		{
+			name:     "Quoted semicolon",
+			owner:    "example.com",
+			rrType:   "TXT",
+			wantData: []string{"v=spf1 mx; -all"},
+		},
+		{
+			name:     "TTL before class and upper case owner",
+			owner:    "www2.example.com",
+			rrType:   "CNAME",
+			wantData: []string{"www"},
+		},
+		{
+			name:     "Previous owner",
+			owner:    "api.example.com",
+			rrType:   "AAAA",
+			wantData: []string{"2001:db8::2"},
+		},
+		{
+			name:     "Included file with its own origin",
+			owner:    "internal.app.staging.example.com",
+			rrType:   "CNAME",
+			wantData: []string{"app"},
+		},
+		{
+			name:     "Origin restored after include",
+			owner:    "status.example.com",
+			rrType:   "CNAME",
+			wantData: []string{"statuspage.example.net."},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			record := find(tt.owner, tt.rrType)
+			if record == nil {
+				t.Fatalf("no %s record for %s", tt.rrType, tt.owner)
+			}
+
+			if !reflect.DeepEqual(record.Data, tt.wantData) {
+				t.Errorf("data = %q, want %q", record.Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestParseZone_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		zone   string
+		origin string
+	}{
+		{
+			name: "Relative name without origin",
+			zone: "www IN A 192.0.2.1\n",
+		},
+		{
+			name:   "Unknown directive",
+			zone:   "$UNKNOWN example.com.\n",
+			origin: "example.com",
+		},
+		{
+			name:   "Unbalanced parentheses",
+			zone:   "@ IN SOA ns1 hostmaster ( 1 2 3 4 5\n",
+			origin: "example.com",
+		},
+		{
+			name:   "Record without type",
+			zone:   "www 3600 IN\n",
+			origin: "example.com",
+		},
+		{
+			name:   "Missing include",
+			zone:   "$INCLUDE testdata/zones/missing.zone\n",
+			origin: "example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := discovery.ParseZone(strings.NewReader(tt.zone), tt.origin); err == nil {
+				t.Error("ParseZone() returned no error")
+			}
+		})
+	}
+}
+
+func TestZoneProvider(t *testing.T) {
+	t.Parallel()
+
+	if _, err := discovery.NewZoneProvider(&discovery.ZoneOptions{
+		Paths:   []string{"testdata/zones/example.com.zone"},
+		Include: []string{"[example.com"},
+	}); !errors.Is(err, discovery.ErrInvalidPattern) {
+		t.Errorf("NewZoneProvider() error = %v, want %v", err, discovery.ErrInvalidPattern)
+	}
+
+	provider, err := discovery.NewZoneProvider(&discovery.ZoneOptions{
+		Paths:   []string{"testdata/zones/example.com.zone"},
+		Include: []string{"example.com", "*.EXAMPLE.com"},
+		Exclude: []string{"mail.*", "ns*", "*.staging.example.com"},
+		Tags:    []string{"dns"},
+		TeamID:  1,
+	})
+	if err != nil {
+		t.Fatalf("NewZoneProvider() error = %v", err)
+	}
+
+	sites, err := provider.Sites(context.Background())
+	if err != nil {
+		t.Fatalf("Sites() error = %v", err)
+	}
+
+	var urls []string
+
+	for _, site := range sites {
+		if site.TeamID != 1 || !reflect.DeepEqual(site.Tags, []string{"dns"}) {
+			t.Errorf("site %s has team %d and tags %v, want team 1 and [dns]", site.URL, site.TeamID, site.Tags)
+		}
+
+		urls = append(urls, site.URL)
+	}
+
+	want := []string{
+		"https://api.example.com",
+		"https://example.com",
+		"https://shop.example.com",
+		"https://staging.example.com",
+		"https://status.example.com",
+		"https://www.example.com",
+		"https://www2.example.com",
+	}
+
+	if !reflect.DeepEqual(urls, want) {
+		t.Fatalf("Sites() URLs = %v, want %v", urls, want)
+	}
+
+	diff := discovery.Compare(sites, []ohdear.Site{
+		{ID: 1, URL: "https://example.com/", TeamID: 1, Tags: []string{"dns"}},
+		{ID: 2, URL: "https://www.example.com", TeamID: 1, Tags: []string{"dns"}},
+	})
+
+	if got := len(diff.Missing); got != len(want)-2 {
+		t.Errorf("Compare() found %d unmonitored hostnames, want %d", got, len(want)-2)
+	}
+}
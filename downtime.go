@@ -0,0 +1,122 @@
+package ohdear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+)
+
+// _filterLayout is the layout of the time filters accepted by the uptime and
+// downtime endpoints.
+const _filterLayout string = "20060102150405"
+
+// Downtime represents a period of time during which a site was down. EndedAt
+// is zero while the downtime is ongoing.
+type Downtime struct {
+	StartedAt jsonutil.Time `json:"started_at,omitempty"`
+	EndedAt   jsonutil.Time `json:"ended_at,omitempty"`
+	ID        int           `json:"id,omitempty"`
+}
+
+// MaintenancePeriod represents a period of time during which a site was under
+// maintenance and its checks didn't send notifications.
+type MaintenancePeriod struct {
+	StartsAt jsonutil.Time `json:"starts_at,omitempty"`
+	EndsAt   jsonutil.Time `json:"ends_at,omitempty"`
+	ID       int           `json:"id,omitempty"`
+	SiteID   int           `json:"site_id,omitempty"`
+}
+
+// Downtime returns the downtime periods of a site overlapping the time range
+// between start and end.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#downtime
+func (s *SitesService) Downtime(ctx context.Context, id uint, start, end time.Time, opts ...RequestOption) ([]Downtime, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Sites.Downtime")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	if !end.After(start) {
+		return nil, nil, ErrInvalidTimeRange
+	}
+
+	query := url.Values{}
+	query.Set("filter[started_at]", start.In(jsonutil.Location()).Format(_filterLayout))
+	query.Set("filter[ended_at]", end.In(jsonutil.Location()).Format(_filterLayout))
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id)) + "/downtime?" + query.Encode()
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var downtime struct {
+		Data []Downtime `json:"data"`
+	}
+
+	if err := json.Unmarshal(ret.Body, &downtime); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal downtime: %w", err)
+	}
+
+	return downtime.Data, ret, nil
+}
+
+// MaintenancePeriods returns every maintenance period of a site.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#maintenance-windows
+func (s *SitesService) MaintenancePeriods(ctx context.Context, id uint, opts ...RequestOption) ([]MaintenancePeriod, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Sites.MaintenancePeriods")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id)) + "/maintenance-periods"
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var periods struct {
+		Data []MaintenancePeriod `json:"data"`
+	}
+
+	if err := json.Unmarshal(ret.Body, &periods); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal maintenance periods: %w", err)
+	}
+
+	return periods.Data, ret, nil
+}
@@ -1,6 +1,14 @@
 package ohdear
 
-import "git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
 
 const (
 	// ErrNilContext is return when a nil context is passed to a function.
@@ -12,9 +20,126 @@ const (
 	// ErrInvalidSiteID is returned when the site ID passed to a function is zero.
 	ErrInvalidSiteID xerrors.Error = "site ID cannot be zero"
 
+	// ErrInvalidCheckID is returned when the check ID passed to a function is zero.
+	ErrInvalidCheckID xerrors.Error = "check ID cannot be zero"
+
 	// ErrInvalidURL is returned when the URL passed to a function is empty or cannot be parsed.
 	ErrInvalidURL xerrors.Error = "invalid URL"
 
 	// ErrInvalidTeamID is returned when the team ID passed to a function is zero.
 	ErrInvalidTeamID xerrors.Error = "team ID cannot be zero"
+
+	// ErrInvalidStatusPageID is returned when the status page ID passed to a
+	// function is zero.
+	ErrInvalidStatusPageID xerrors.Error = "status page ID cannot be zero"
+
+	// ErrInvalidStatusPageUpdateID is returned when the status page update ID
+	// passed to a function is zero.
+	ErrInvalidStatusPageUpdateID xerrors.Error = "status page update ID cannot be zero"
+
+	// ErrNilStatusPage is returned when a nil status page or status page update
+	// is passed to a function.
+	ErrNilStatusPage xerrors.Error = "status page cannot be nil"
+
+	// ErrInvalidSeverity is returned when a status page update has an unknown
+	// severity.
+	ErrInvalidSeverity xerrors.Error = "invalid status page update severity"
+
+	// ErrNilUptimeSettings is returned when nil uptime settings are passed to
+	// a function.
+	ErrNilUptimeSettings xerrors.Error = "uptime settings cannot be nil"
+
+	// ErrInvalidUptimeSettings is returned when uptime settings would be
+	// rejected by Oh Dear.
+	ErrInvalidUptimeSettings xerrors.Error = "invalid uptime settings"
+
+	// ErrInvalidMaintenancePeriodID is returned when the maintenance period ID
+	// passed to a function is zero.
+	ErrInvalidMaintenancePeriodID xerrors.Error = "maintenance period ID cannot be zero"
+
+	// ErrNilMaintenancePeriod is returned when a nil maintenance period is
+	// passed to a function.
+	ErrNilMaintenancePeriod xerrors.Error = "maintenance period cannot be nil"
+
+	// ErrInvalidCronCheckID is returned when the cron check ID passed to a
+	// function is zero.
+	ErrInvalidCronCheckID xerrors.Error = "cron check ID cannot be zero"
+
+	// ErrNilCronCheck is returned when a nil cron check is passed to a
+	// function.
+	ErrNilCronCheck xerrors.Error = "cron check cannot be nil"
+
+	// ErrInvalidCronCheckType is returned when a cron check has an unknown
+	// type or lacks the fields its type requires.
+	ErrInvalidCronCheckType xerrors.Error = "invalid cron check type"
+
+	// ErrInvalidNotificationDestinationID is returned when the notification
+	// destination ID passed to a function is zero.
+	ErrInvalidNotificationDestinationID xerrors.Error = "notification destination ID cannot be zero"
+
+	// ErrNilNotificationDestination is returned when a nil notification
+	// destination is passed to a function.
+	ErrNilNotificationDestination xerrors.Error = "notification destination cannot be nil"
+
+	// ErrInvalidTimeRange is returned when the end of a time range passed to a
+	// function is not after its start.
+	ErrInvalidTimeRange xerrors.Error = "time range must end after it starts"
 )
+
+// APIError is returned when the Oh Dear API responds with an unsuccessful
+// status code.
+type APIError struct {
+	// Errors holds the validation errors returned by the API, keyed by field
+	// name.
+	Errors map[string][]string `json:"errors,omitempty"`
+
+	// Message is the error message returned by the API.
+	Message string `json:"message,omitempty"`
+
+	// Status is the HTTP status code of the response.
+	Status int `json:"-"`
+}
+
+// newAPIError returns an APIError describing the given response.
+func newAPIError(resp *Response) *APIError {
+	apiErr := &APIError{
+		Status: resp.Status,
+	}
+
+	// The body is not guaranteed to be JSON, such as when a proxy in front of
+	// the API fails, so decoding errors are ignored.
+	_ = json.Unmarshal(resp.Body, apiErr)
+
+	return apiErr
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var b strings.Builder
+
+	b.WriteString("API error: ")
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteString(" ")
+	b.WriteString(http.StatusText(e.Status))
+
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+
+	fields := make([]string, 0, len(e.Errors))
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		b.WriteString("; ")
+		b.WriteString(field)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(e.Errors[field], ", "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,338 @@
+// Package exporter implements a [Prometheus] collector that publishes the
+// status of the sites and checks of an Oh Dear account.
+//
+// Site metrics are labeled with the ID, team ID and URL of the site, so sites
+// sharing a URL across teams are published as separate series.
+//
+// [Prometheus]: https://prometheus.io
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrClientRequired is returned when a Collector is created without a client.
+const ErrClientRequired xerrors.Error = "client cannot be nil"
+
+// Namespace is the namespace used for all metrics published by the collector.
+const Namespace string = "ohdear"
+
+// Default values for the Options struct.
+const (
+	DefaultCacheTTL time.Duration = 60 * time.Second
+	DefaultTimeout  time.Duration = 30 * time.Second
+)
+
+// Values published for check and site results.
+//
+// Checks that errored or timed out are published as ResultFailed. Any other
+// result, including "pending" and results unknown to this package, is
+// published as ResultUnknown so that alerting rules can match on
+// ResultSucceeded alone.
+const (
+	ResultSucceeded float64 = 1
+	ResultWarning   float64 = 0.5
+	ResultFailed    float64 = 0
+	ResultUnknown   float64 = -1
+)
+
+// Options holds the configuration for a Collector.
+type Options struct {
+	// CacheTTL is how long the results of a scrape are reused before the Oh
+	// Dear API is queried again. It keeps frequent scrapes, such as Grafana
+	// refreshes, from using up the API quota.
+	//
+	// This field is optional.
+	CacheTTL time.Duration
+
+	// Timeout is the time limit for collecting data from the Oh Dear API.
+	//
+	// This field is optional.
+	Timeout time.Duration
+
+	// Certificates specifies whether or not to query the certificate health of
+	// each site to publish the number of days until its certificate expires.
+	// It costs one extra API call per site for every uncached scrape.
+	//
+	// This field is optional.
+	Certificates bool
+}
+
+// snapshot holds the data fetched from the API during a single scrape.
+type snapshot struct {
+	fetchedAt    time.Time
+	sites        []ohdear.Site
+	certificates map[int]time.Time
+	err          error
+}
+
+// Collector is a prometheus.Collector for the sites and checks of an Oh Dear
+// account.
+type Collector struct {
+	// client is the Oh Dear API client used to fetch data.
+	client *ohdear.Client
+
+	// opts are the options for the collector.
+	opts Options
+
+	// last is the last snapshot fetched from the API.
+	last *snapshot
+
+	// Metric descriptors.
+	up                  *prometheus.Desc
+	checkResult         *prometheus.Desc
+	checkLastRun        *prometheus.Desc
+	siteResult          *prometheus.Desc
+	certificateExpiry   *prometheus.Desc
+	scrapeDuration      *prometheus.Desc
+	scrapeCacheAgeInSec *prometheus.Desc
+
+	// mu protects last and serializes requests to the API.
+	mu sync.Mutex
+}
+
+// Compile-time check to ensure Collector implements prometheus.Collector.
+var _ prometheus.Collector = (*Collector)(nil)
+
+// New returns a new Collector that uses the given client to fetch data.
+func New(client *ohdear.Client, opts *Options) (*Collector, error) {
+	if client == nil {
+		return nil, ErrClientRequired
+	}
+
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.CacheTTL < 1 {
+		o.CacheTTL = DefaultCacheTTL
+	}
+
+	if o.Timeout < 1 {
+		o.Timeout = DefaultTimeout
+	}
+
+	return &Collector{
+		client: client,
+		opts:   o,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "", "up"),
+			"Whether or not the last query to the Oh Dear API was successful.",
+			nil, nil,
+		),
+		checkResult: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "check", "result"),
+			"Latest result of a check: 1 succeeded, 0.5 warning, 0 failed, -1 unknown.",
+			[]string{"site_id", "team_id", "site", "check_type"}, nil,
+		),
+		checkLastRun: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "check", "last_run_timestamp"),
+			"Unix timestamp of the moment the latest run of a check ended.",
+			[]string{"site_id", "team_id", "site", "check_type"}, nil,
+		),
+		siteResult: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "site", "summarized_result"),
+			"Summarized result of all checks of a site: 1 succeeded, 0.5 warning, 0 failed, -1 unknown.",
+			[]string{"site_id", "team_id", "site"}, nil,
+		),
+		certificateExpiry: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "certificate", "expiry_days"),
+			"Number of days until the TLS certificate of a site expires.",
+			[]string{"site_id", "team_id", "site"}, nil,
+		),
+		scrapeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "scrape", "duration_seconds"),
+			"Time it took to query the Oh Dear API.",
+			nil, nil,
+		),
+		scrapeCacheAgeInSec: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "scrape", "cache_age_seconds"),
+			"Age of the cached data served by the exporter.",
+			nil, nil,
+		),
+	}, nil
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.checkResult
+	ch <- c.checkLastRun
+	ch <- c.siteResult
+	ch <- c.certificateExpiry
+	ch <- c.scrapeDuration
+	ch <- c.scrapeCacheAgeInSec
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	snap, duration := c.snapshot()
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, duration.Seconds())
+	ch <- prometheus.MustNewConstMetric(
+		c.scrapeCacheAgeInSec,
+		prometheus.GaugeValue,
+		time.Since(snap.fetchedAt).Seconds(),
+	)
+
+	if snap.err != nil {
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+
+	for i := range snap.sites {
+		var (
+			site   = &snap.sites[i]
+			siteID = strconv.Itoa(site.ID)
+			teamID = strconv.Itoa(site.TeamID)
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.siteResult,
+			prometheus.GaugeValue,
+			resultValue(site.SummarizedCheckResult),
+			siteID,
+			teamID,
+			site.URL,
+		)
+
+		for j := range site.Checks {
+			check := &site.Checks[j]
+
+			if !check.Enabled {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.checkResult,
+				prometheus.GaugeValue,
+				resultValue(check.LatestRunResult),
+				siteID,
+				teamID,
+				site.URL,
+				string(check.Type),
+			)
+
+			if !check.LatestRunEndedAt.IsZero() {
+				ch <- prometheus.MustNewConstMetric(
+					c.checkLastRun,
+					prometheus.GaugeValue,
+					float64(check.LatestRunEndedAt.Unix()),
+					site.URL,
+					string(check.Type),
+				)
+			}
+		}
+
+		if validUntil, ok := snap.certificates[site.ID]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.certificateExpiry,
+				prometheus.GaugeValue,
+				time.Until(validUntil).Hours()/24,
+				siteID,
+				teamID,
+				site.URL,
+			)
+		}
+	}
+}
+
+// snapshot returns the cached snapshot if it's still fresh or fetches a new
+// one from the API, along with the time it took to fetch it.
+func (c *Collector) snapshot() (*snapshot, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && c.last.err == nil && time.Since(c.last.fetchedAt) < c.opts.CacheTTL {
+		return c.last, 0
+	}
+
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+	defer cancel()
+
+	c.last = c.fetch(ctx)
+
+	return c.last, time.Since(start)
+}
+
+// fetch queries the API for the data published by the collector.
+func (c *Collector) fetch(ctx context.Context) *snapshot {
+	snap := &snapshot{
+		fetchedAt: time.Now(),
+	}
+
+	sites, err := c.client.Sites.ListAll(ctx)
+	if err != nil {
+		snap.err = fmt.Errorf("could not list sites: %w", err)
+
+		return snap
+	}
+
+	snap.sites = sites
+
+	if !c.opts.Certificates {
+		return snap
+	}
+
+	snap.certificates = make(map[int]time.Time, len(sites))
+
+	for i := range sites {
+		if !hasCheck(&sites[i], ohdear.CheckTypeCertificateHealth) {
+			continue
+		}
+
+		health, _, err := c.client.CertificateHealth.Get(ctx, uint(sites[i].ID))
+		if err != nil {
+			snap.err = fmt.Errorf("could not get certificate health for site %d: %w", sites[i].ID, err)
+
+			return snap
+		}
+
+		if !health.CertificateDetails.ValidUntil.IsZero() {
+			snap.certificates[sites[i].ID] = health.CertificateDetails.ValidUntil.Time
+		}
+	}
+
+	return snap
+}
+
+// hasCheck returns true if the given site has an enabled check of the given
+// type.
+func hasCheck(site *ohdear.Site, checkType ohdear.CheckType) bool {
+	for i := range site.Checks {
+		if site.Checks[i].Type == checkType && site.Checks[i].Enabled {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resultValue converts a check result to the value published by the
+// collector.
+func resultValue(result ohdear.CheckResult) float64 {
+	switch result {
+	case ohdear.CheckResultSucceeded:
+		return ResultSucceeded
+	case ohdear.CheckResultWarning:
+		return ResultWarning
+	case ohdear.CheckResultFailed, ohdear.CheckResultErroredOrTimedOut:
+		return ResultFailed
+	default:
+		return ResultUnknown
+	}
+}
@@ -0,0 +1,81 @@
+package exporter_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/exporter"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	site := srv.AddSite(&ohdear.Site{
+		URL:    "https://example.com",
+		TeamID: 1,
+		Checks: []ohdear.Check{
+			{Type: "uptime", Enabled: true, LatestRunResult: "succeeded"},
+			{Type: "certificate_health", Enabled: true, LatestRunResult: "failed"},
+		},
+	})
+
+	// A site with the same URL in another team is published separately.
+	srv.AddSite(&ohdear.Site{
+		URL:    "https://example.com",
+		TeamID: 2,
+		Checks: []ohdear.Check{
+			{Type: "uptime", Enabled: true, LatestRunResult: "succeeded"},
+		},
+	})
+
+	srv.SetCertificateHealth(site.ID, &ohdear.CertificateHealth{
+		CertificateDetails: ohdear.CertificateDetails{
+			ValidUntil: jsonutil.Time{Time: time.Now().Add(240 * time.Hour)},
+		},
+	})
+
+	collector, err := exporter.New(srv.Client(), &exporter.Options{
+		Certificates: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	want := `
+# HELP ohdear_check_result Latest result of a check: 1 succeeded, 0.5 warning, 0 failed, -1 unknown.
+# TYPE ohdear_check_result gauge
+ohdear_check_result{check_type="certificate_health",site="https://example.com",site_id="1",team_id="1"} 0
+ohdear_check_result{check_type="uptime",site="https://example.com",site_id="1",team_id="1"} 1
+ohdear_check_result{check_type="uptime",site="https://example.com",site_id="4",team_id="2"} 1
+# HELP ohdear_site_summarized_result Summarized result of all checks of a site: 1 succeeded, 0.5 warning, 0 failed, -1 unknown.
+# TYPE ohdear_site_summarized_result gauge
+ohdear_site_summarized_result{site="https://example.com",site_id="1",team_id="1"} 0
+ohdear_site_summarized_result{site="https://example.com",site_id="4",team_id="2"} 1
+# HELP ohdear_up Whether or not the last query to the Oh Dear API was successful.
+# TYPE ohdear_up gauge
+ohdear_up 1
+`
+
+	err = testutil.CollectAndCompare(
+		collector,
+		strings.NewReader(want),
+		"ohdear_check_result",
+		"ohdear_site_summarized_result",
+		"ohdear_up",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if got := testutil.CollectAndCount(collector, "ohdear_certificate_expiry_days"); got != 1 {
+		t.Errorf("got %d certificate expiry metrics, want 1", got)
+	}
+}
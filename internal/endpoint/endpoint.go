@@ -4,4 +4,29 @@ package endpoint
 const (
 	// Sites is the endpoint for the sites service.
 	Sites string = "/sites"
+
+	// Checks is the endpoint for the checks service.
+	Checks string = "/checks"
+
+	// CertificateHealth is the endpoint for the certificate health service.
+	CertificateHealth string = "/certificate-health"
+
+	// StatusPages is the endpoint for the status pages service.
+	StatusPages string = "/status-pages"
+
+	// StatusPageUpdates is the endpoint for the updates of status pages.
+	StatusPageUpdates string = "/status-page-updates"
+
+	// MaintenancePeriods is the endpoint for the maintenance periods service.
+	MaintenancePeriods string = "/maintenance-periods"
+
+	// CronChecks is the endpoint for the cron checks service.
+	CronChecks string = "/cron-checks"
+
+	// NotificationDestinations is the endpoint for the notification
+	// destinations of a site, relative to the site.
+	NotificationDestinations string = "/notification-destinations"
+
+	// Me is the endpoint for the authenticated user and their teams.
+	Me string = "/me"
 )
@@ -0,0 +1,152 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Optional is implemented by field types that can be left out of a JSON
+// object entirely, regardless of their omitempty option.
+type Optional interface {
+	// IsSet returns true if the value should be included in the object.
+	IsSet() bool
+}
+
+// MarshalObject marshals v, which must be a struct without a MarshalJSON
+// method, as a JSON object. Fields implementing Optional are left out when
+// they're not set, and the members of extra are added to the object unless a
+// field with the same name exists.
+func MarshalObject(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	unset := unsetFields(reflect.ValueOf(v))
+	if len(unset) == 0 && len(extra) == 0 {
+		return data, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	for _, name := range unset {
+		delete(object, name)
+	}
+
+	for name, value := range extra {
+		if _, ok := object[name]; !ok {
+			object[name] = value
+		}
+	}
+
+	data, err = json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalObject unmarshals the JSON object in data into v, which must be a
+// pointer to a struct without an UnmarshalJSON method, and returns the members
+// of the object that don't match any field of v. It returns a nil map if
+// every member matches.
+func UnmarshalObject(data []byte, v any) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	known := fieldNames(reflect.TypeOf(v).Elem())
+
+	var extra map[string]json.RawMessage
+
+	for name, value := range object {
+		if known[strings.ToLower(name)] {
+			continue
+		}
+
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+
+		extra[name] = value
+	}
+
+	return extra, nil
+}
+
+// unsetFields returns the JSON names of the fields of the struct v whose
+// values implement Optional and are not set.
+func unsetFields(v reflect.Value) []string {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	var (
+		t     = v.Type()
+		unset []string
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		if optional, ok := v.Field(i).Interface().(Optional); ok && !optional.IsSet() {
+			unset = append(unset, name)
+		}
+	}
+
+	return unset
+}
+
+// fieldNames returns the lowercase JSON names of the fields of the struct
+// type t, matching the case-insensitive way encoding/json maps members to
+// fields.
+func fieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := fieldName(t.Field(i)); ok {
+			names[strings.ToLower(name)] = true
+		}
+	}
+
+	return names
+}
+
+// fieldName returns the JSON name of a struct field, or false if the field is
+// not marshaled.
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
@@ -3,13 +3,63 @@ package jsonutil
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// Layouts used by the Oh Dear API.
+const (
+	// LayoutDateTime is the layout used by most endpoints. It has no
+	// timezone, so times are interpreted in the location set by SetLocation.
+	LayoutDateTime string = "2006-01-02 15:04:05"
+
+	// LayoutDate is the layout used by endpoints returning dates only.
+	LayoutDate string = "2006-01-02"
+
+	// LayoutRFC3339 is the layout used by endpoints returning ISO-8601
+	// timestamps with an offset.
+	LayoutRFC3339 string = time.RFC3339
+)
+
+// _layouts holds the layouts accepted when unmarshaling, in the order they're
+// tried.
+var _layouts = []string{
+	LayoutDateTime,
+	time.RFC3339Nano,
+	LayoutDate,
+}
+
+// _location is the location of times without an offset.
+var _location atomic.Pointer[time.Location]
+
+// SetLocation sets the location used to interpret and format times using
+// layouts without an offset, such as LayoutDateTime. It affects every Time in
+// the process, so it's meant to be called once at startup. A nil location
+// resets it to UTC, the default.
+func SetLocation(loc *time.Location) {
+	_location.Store(loc)
+}
+
+// Location returns the location set by SetLocation.
+func Location() *time.Location {
+	if loc := _location.Load(); loc != nil {
+		return loc
+	}
+
+	return time.UTC
+}
+
 // Time is a wrapper around time.Time that allows us to marshal/unmarshal Time
 // the format used by the Oh Dear API.
 type Time struct {
 	time.Time
+
+	// Layout is the layout used to marshal the time, so it's sent back in the
+	// format the endpoint expects. When unmarshaling, it's set to the layout
+	// the time was parsed with.
+	//
+	// This field is optional. It defaults to LayoutDateTime.
+	Layout string
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -18,25 +68,60 @@ func (t Time) MarshalJSON() ([]byte, error) { //nolint:unparam // required by th
 		return []byte("null"), nil
 	}
 
-	return []byte(t.Time.Format(`"2006-01-02 15:04:05"`)), nil
+	layout := t.Layout
+	if layout == "" {
+		layout = LayoutDateTime
+	}
+
+	tm := t.Time
+	if !hasOffset(layout) {
+		tm = tm.In(Location())
+	}
+
+	return []byte(`"` + tm.Format(layout) + `"`), nil
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface.
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// LayoutDateTime, RFC 3339 and LayoutDate, and treats null and empty strings
+// as the zero time.
 func (t *Time) UnmarshalJSON(data []byte) error {
-	if string(data) == "null" {
+	s := strings.Trim(string(data), "\"")
+
+	if s == "null" || s == "" {
 		t.Time = time.Time{}
 
 		return nil
 	}
 
-	s := strings.Trim(string(data), "\"")
+	var err error
 
-	tt, err := time.Parse("2006-01-02 15:04:05", s)
-	if err != nil {
-		return fmt.Errorf("%w", err)
+	for _, layout := range _layouts {
+		var tt time.Time
+
+		if hasOffset(layout) {
+			tt, err = time.Parse(layout, s)
+		} else {
+			tt, err = time.ParseInLocation(layout, s, Location())
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if layout == time.RFC3339Nano {
+			layout = LayoutRFC3339
+		}
+
+		t.Time = tt
+		t.Layout = layout
+
+		return nil
 	}
 
-	t.Time = tt
+	return fmt.Errorf("%w", err)
+}
 
-	return nil
+// hasOffset returns true if the layout includes a timezone offset.
+func hasOffset(layout string) bool {
+	return strings.Contains(layout, "Z07") || strings.Contains(layout, "-07")
 }
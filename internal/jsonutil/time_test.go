@@ -19,21 +19,48 @@ func TestTime(t *testing.T) {
 	}{
 		{
 			name:          "Zero time",
-			t:             jsonutil.Time{time.Time{}},
+			t:             jsonutil.Time{Time: time.Time{}},
 			marshalJSON:   "null",
 			unmarshalJSON: "null",
 			expectErr:     false,
 		},
 		{
 			name:          "Non-zero time",
-			t:             jsonutil.Time{time.Date(2023, 5, 14, 12, 0, 0, 0, time.UTC)},
+			t:             jsonutil.Time{Time: time.Date(2023, 5, 14, 12, 0, 0, 0, time.UTC)},
 			marshalJSON:   `"2023-05-14 12:00:00"`,
 			unmarshalJSON: `"2023-05-14 12:00:00"`,
 			expectErr:     false,
 		},
+		{
+			name:          "Empty string",
+			t:             jsonutil.Time{Time: time.Time{}},
+			marshalJSON:   "null",
+			unmarshalJSON: `""`,
+			expectErr:     false,
+		},
+		{
+			name: "RFC 3339 with offset",
+			t: jsonutil.Time{
+				Time:   time.Date(2023, 5, 14, 12, 0, 0, 0, time.FixedZone("", 2*60*60)),
+				Layout: jsonutil.LayoutRFC3339,
+			},
+			marshalJSON:   `"2023-05-14T12:00:00+02:00"`,
+			unmarshalJSON: `"2023-05-14T12:00:00+02:00"`,
+			expectErr:     false,
+		},
+		{
+			name: "Date only",
+			t: jsonutil.Time{
+				Time:   time.Date(2023, 5, 14, 0, 0, 0, 0, time.UTC),
+				Layout: jsonutil.LayoutDate,
+			},
+			marshalJSON:   `"2023-05-14"`,
+			unmarshalJSON: `"2023-05-14"`,
+			expectErr:     false,
+		},
 		{
 			name:          "Invalid time format",
-			t:             jsonutil.Time{time.Time{}},
+			t:             jsonutil.Time{Time: time.Time{}},
 			marshalJSON:   "null",
 			unmarshalJSON: `"invalid time"`,
 			expectErr:     true,
@@ -69,3 +96,31 @@ func TestTime(t *testing.T) {
 		})
 	}
 }
+
+// TestSetLocation is not parallel, as the location is shared by every Time.
+func TestSetLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-3", -3*60*60)
+
+	jsonutil.SetLocation(loc)
+	defer jsonutil.SetLocation(nil)
+
+	var tm jsonutil.Time
+	if err := tm.UnmarshalJSON([]byte(`"2023-05-14 12:00:00"`)); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+
+	if want := time.Date(2023, 5, 14, 15, 0, 0, 0, time.UTC); !tm.Equal(want) {
+		t.Errorf("UnmarshalJSON() = %v, want %v", tm.Time, want)
+	}
+
+	tm = jsonutil.Time{Time: time.Date(2023, 5, 14, 15, 0, 0, 0, time.UTC)}
+
+	b, err := tm.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	if want := `"2023-05-14 12:00:00"`; string(b) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", b, want)
+	}
+}
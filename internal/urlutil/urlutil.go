@@ -3,15 +3,21 @@ package urlutil
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
 
 	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"golang.org/x/net/idna"
 )
 
 // ErrInvalidURL is returned when the URL passed to a function is empty or cannot be parsed.
 const ErrInvalidURL xerrors.Error = "invalid URL"
 
+// _idna converts hosts to their ASCII form. Unlike idna.Lookup, it allows
+// underscores, which some hosts in the wild use.
+var _idna = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
 // Validate checks if the given URL is valid for use with the Oh Dear API.
 func Validate(uri string) error {
 	if uri == "" {
@@ -29,3 +35,60 @@ func Validate(uri string) error {
 
 	return nil
 }
+
+// Normalize returns the canonical form of an HTTP or HTTPS URL, so that URLs
+// pointing to the same site compare equal. The scheme and host are lowered,
+// internationalized hosts are converted to punycode, default ports, the
+// trailing dot of the host, trailing slashes and fragments are removed.
+func Normalize(uri string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: URL must start with http:// or https://", ErrInvalidURL)
+	}
+
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if host == "" {
+		return "", fmt.Errorf("%w: URL must have a host", ErrInvalidURL)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+
+		if ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+	} else if host, err = _idna.ToASCII(host); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+
+	if u.User != nil {
+		host = u.User.String() + "@" + host
+	}
+
+	normalized := u.Scheme + "://" + host + strings.TrimRight(u.EscapedPath(), "/")
+
+	if u.RawQuery != "" {
+		normalized += "?" + u.RawQuery
+	}
+
+	return normalized, nil
+}
+
+// SortURL returns the normalized URL without its scheme, which is how Oh Dear
+// computes the sort_url of a site.
+func SortURL(uri string) (string, error) {
+	normalized, err := Normalize(uri)
+	if err != nil {
+		return "", err
+	}
+
+	return normalized[strings.Index(normalized, "://")+len("://"):], nil
+}
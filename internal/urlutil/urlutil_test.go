@@ -55,3 +55,96 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		url         string
+		want        string
+		wantSortURL string
+		wantError   bool
+	}{
+		{
+			name:        "Already normalized",
+			url:         "https://example.com",
+			want:        "https://example.com",
+			wantSortURL: "example.com",
+		},
+		{
+			name:        "Trailing slashes",
+			url:         "https://example.com/blog//",
+			want:        "https://example.com/blog",
+			wantSortURL: "example.com/blog",
+		},
+		{
+			name:        "Scheme and host case",
+			url:         "HTTPS://WWW.Example.COM/Path",
+			want:        "https://www.example.com/Path",
+			wantSortURL: "www.example.com/Path",
+		},
+		{
+			name:        "Default ports",
+			url:         "http://example.com:80/",
+			want:        "http://example.com",
+			wantSortURL: "example.com",
+		},
+		{
+			name:        "Other ports",
+			url:         "http://example.com:443/",
+			want:        "http://example.com:443",
+			wantSortURL: "example.com:443",
+		},
+		{
+			name:        "Internationalized host",
+			url:         "https://Bücher.example/",
+			want:        "https://xn--bcher-kva.example",
+			wantSortURL: "xn--bcher-kva.example",
+		},
+		{
+			name:        "Trailing dot, query and fragment",
+			url:         "https://example.com./search/?q=1#results",
+			want:        "https://example.com/search?q=1",
+			wantSortURL: "example.com/search?q=1",
+		},
+		{
+			name:        "IPv6 host",
+			url:         "https://[2001:DB8::1]:443",
+			want:        "https://[2001:db8::1]",
+			wantSortURL: "[2001:db8::1]",
+		},
+		{
+			name:      "Unsupported scheme",
+			url:       "ftp://example.com",
+			wantError: true,
+		},
+		{
+			name:      "Missing host",
+			url:       "https:///path",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := urlutil.Normalize(tt.url)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Normalize(%q) error = %v, wantError %v", tt.url, err, tt.wantError)
+			}
+
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+
+			sortURL, _ := urlutil.SortURL(tt.url)
+			if sortURL != tt.wantSortURL {
+				t.Errorf("SortURL(%q) = %q, want %q", tt.url, sortURL, tt.wantSortURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package ohdear
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RedactedValue replaces the value of sensitive headers in logs.
+const RedactedValue string = "[REDACTED]"
+
+// LoggingMiddleware returns a Middleware that logs every API call to the given
+// structured logger.
+//
+// Successful calls are logged at the info level and failed ones at the error
+// level, with the method, path, status, duration, number of attempts and
+// remaining rate limit as attributes. Request headers, with sensitive values
+// redacted, are included at the debug level.
+//
+// It's added to the chain automatically when Config.LogHandler is set.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			var (
+				ctx   = req.Context()
+				start = time.Now()
+			)
+
+			resp, err := next.Do(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			if info, ok := CallInfoFromContext(ctx); ok {
+				attrs = append(attrs,
+					slog.String("operation", info.Operation),
+					slog.Int("attempt", info.Attempts()),
+				)
+			}
+
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs, slog.Any("headers", RedactHeader(req.Header)))
+			}
+
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+
+				logger.LogAttrs(ctx, slog.LevelError, "ohdear request failed", attrs...)
+
+				return nil, err
+			}
+
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+			if remaining, convErr := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); convErr == nil {
+				attrs = append(attrs, slog.Int("rate_limit_remaining", remaining))
+			}
+
+			level := slog.LevelInfo
+			if resp.StatusCode >= http.StatusBadRequest {
+				level = slog.LevelError
+			}
+
+			logger.LogAttrs(ctx, level, "ohdear request", attrs...)
+
+			return resp, nil
+		})
+	}
+}
+
+// isSensitiveHeader checks if the value of the given header must never be
+// logged.
+func isSensitiveHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key":
+		return true
+	default:
+		return false
+	}
+}
+
+// RedactHeader returns a copy of h with the values of sensitive headers, such
+// as Authorization, replaced by RedactedValue.
+func RedactHeader(h http.Header) http.Header {
+	redacted := h.Clone()
+
+	for name := range redacted {
+		if isSensitiveHeader(name) {
+			redacted[name] = []string{RedactedValue}
+		}
+	}
+
+	return redacted
+}
+
+// redactDump replaces the values of sensitive headers in a dump produced by
+// the httputil package.
+func redactDump(dump []byte) []byte {
+	head, body, found := bytes.Cut(dump, []byte("\r\n\r\n"))
+
+	lines := bytes.Split(head, []byte("\r\n"))
+
+	// The first line holds the request or status line, not a header.
+	for i := 1; i < len(lines); i++ {
+		name, _, ok := bytes.Cut(lines[i], []byte(":"))
+		if ok && isSensitiveHeader(string(bytes.TrimSpace(name))) {
+			lines[i] = []byte(string(name) + ": " + RedactedValue)
+		}
+	}
+
+	redacted := bytes.Join(lines, []byte("\r\n"))
+
+	if found {
+		redacted = append(append(redacted, "\r\n\r\n"...), body...)
+	}
+
+	return redacted
+}
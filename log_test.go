@@ -0,0 +1,115 @@
+package ohdear_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+const _testKey string = "secret-api-key"
+
+// stubMiddleware returns a Middleware that answers every request with the
+// given status and body instead of calling the API.
+func stubMiddleware(status int, body string) ohdear.Middleware {
+	return func(_ ohdear.Doer) ohdear.Doer {
+		return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"X-Ratelimit-Remaining": []string{"42"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})
+	}
+}
+
+type bufferLogger struct {
+	buf bytes.Buffer
+}
+
+func (l *bufferLogger) Printf(format string, v ...any) {
+	fmt.Fprintf(&l.buf, format, v...)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cfg := ohdear.NewConfig(_testKey, nil)
+	cfg.Middleware = []ohdear.Middleware{
+		ohdear.LoggingMiddleware(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		stubMiddleware(http.StatusOK, `{"id":1}`),
+	}
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, _, err = client.Sites.Get(context.Background(), 1); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if strings.Contains(buf.String(), _testKey) {
+		t.Errorf("log contains the API key: %s", buf.String())
+	}
+
+	var record map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("could not unmarshal log record: %v", err)
+	}
+
+	want := map[string]any{
+		"method":               "GET",
+		"path":                 "/api/sites/1",
+		"operation":            "Sites.Get",
+		"status":               float64(http.StatusOK),
+		"rate_limit_remaining": float64(42),
+	}
+
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("record[%q] = %v, want %v", key, record[key], value)
+		}
+	}
+}
+
+func TestDebugMiddleware(t *testing.T) {
+	t.Parallel()
+
+	logger := &bufferLogger{}
+
+	cfg := ohdear.NewConfig(_testKey, nil)
+	cfg.Middleware = []ohdear.Middleware{
+		ohdear.DebugMiddleware(logger),
+		stubMiddleware(http.StatusOK, `{"id":1}`),
+	}
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, _, err = client.Sites.Get(context.Background(), 1); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	out := logger.buf.String()
+
+	if strings.Contains(out, _testKey) {
+		t.Errorf("debug dump contains the API key: %s", out)
+	}
+
+	if !strings.Contains(out, "Authorization: "+ohdear.RedactedValue) {
+		t.Errorf("debug dump does not contain the redacted Authorization header: %s", out)
+	}
+}
@@ -0,0 +1,97 @@
+package ohdear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+)
+
+// MaintenancePeriodsService handles communication with the
+// /maintenance-periods endpoint of Oh Dear's API. The maintenance periods of a
+// site are listed with SitesService.MaintenancePeriods.
+type MaintenancePeriodsService service
+
+// Add schedules a maintenance period for the site set in period.SiteID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#maintenance-windows
+func (s *MaintenancePeriodsService) Add(ctx context.Context, period *MaintenancePeriod, opts ...RequestOption) (*MaintenancePeriod, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "MaintenancePeriods.Add")
+
+	if period == nil {
+		return nil, nil, ErrNilMaintenancePeriod
+	}
+
+	if period.SiteID == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	if !period.EndsAt.After(period.StartsAt.Time) {
+		return nil, nil, ErrInvalidTimeRange
+	}
+
+	payload, err := json.Marshal(period)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal maintenance period: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.MaintenancePeriods
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var addedPeriod MaintenancePeriod
+	if err := json.Unmarshal(ret.Body, &addedPeriod); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal added maintenance period: %w", err)
+	}
+
+	return &addedPeriod, ret, nil
+}
+
+// Remove removes a maintenance period by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#maintenance-windows
+func (s *MaintenancePeriodsService) Remove(ctx context.Context, id uint, opts ...RequestOption) (*Response, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "MaintenancePeriods.Remove")
+
+	if id == 0 {
+		return nil, ErrInvalidMaintenancePeriodID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.MaintenancePeriods + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
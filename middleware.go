@@ -0,0 +1,80 @@
+package ohdear
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+)
+
+// Doer defines the interface for performing HTTP requests. It's implemented by
+// the innermost layer of the Client, which sends requests to the API, and by
+// every Middleware wrapping it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doers.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to inspect or modify requests before they're sent
+// and responses before they're returned.
+//
+// Middlewares wrap the whole request, including retries made because of rate
+// limiting, and are usually called once per call to Client.Do. They're called
+// a second time if the API rejects the API key and the request is sent again
+// with a refreshed one, and not at all if the response is served from the
+// Cache. Both requests of a call share the CallInfo returned by
+// CallInfoFromContext, so middlewares counting calls can skip the second one.
+type Middleware func(next Doer) Doer
+
+// chain wraps doer with the given middlewares. The first middleware is the
+// outermost one, so it sees requests first and responses last.
+func chain(doer Doer, middlewares ...Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] == nil {
+			continue
+		}
+
+		doer = middlewares[i](doer)
+	}
+
+	return doer
+}
+
+// DebugMiddleware returns a Middleware that dumps every request and response
+// to the given logger. The values of sensitive headers, such as Authorization,
+// are redacted.
+//
+// It's added to the end of the chain automatically when Config.Debug is true,
+// so it logs requests exactly as they're sent to the API.
+func DebugMiddleware(logger Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			dump, err := httputil.DumpRequestOut(req, true)
+			if err != nil {
+				return nil, fmt.Errorf("%w", err)
+			}
+
+			logger.Printf("\n%s", redactDump(dump))
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+
+			dump, err = httputil.DumpResponse(resp, true)
+			if err != nil {
+				return nil, fmt.Errorf("%w", err)
+			}
+
+			logger.Printf("\n%s", redactDump(dump))
+
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ohdear_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	record := func(name string) ohdear.Middleware {
+		return func(next ohdear.Doer) ohdear.Doer {
+			return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+
+				return next.Do(req)
+			})
+		}
+	}
+
+	// Short-circuit the chain so no request reaches the network.
+	stub := func(_ ohdear.Doer) ohdear.Doer {
+		return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, "stub")
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"id":1,"url":"https://example.com"}`)),
+				Request:    req,
+			}, nil
+		})
+	}
+
+	cfg := ohdear.NewConfig("key", nil)
+	cfg.Middleware = []ohdear.Middleware{record("first"), record("second"), stub}
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	site, _, err := client.Sites.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if site.URL != "https://example.com" {
+		t.Errorf("Get() URL = %q, want %q", site.URL, "https://example.com")
+	}
+
+	want := []string{"first", "second", "stub"}
+
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestMiddleware_Operation(t *testing.T) {
+	t.Parallel()
+
+	var operation string
+
+	stub := func(_ ohdear.Doer) ohdear.Doer {
+		return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if info, ok := ohdear.CallInfoFromContext(req.Context()); ok {
+				operation = info.Operation
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+				Request:    req,
+			}, nil
+		})
+	}
+
+	cfg := ohdear.NewConfig("key", nil)
+	cfg.Middleware = []ohdear.Middleware{stub}
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+
+	req, err := client.NewRequest(ctx, http.MethodGet, cfg.BaseURL+"/sites/123/checks/45", http.NoBody)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	if _, err = client.Do(ctx, req); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if want := "GET /sites/{id}/checks/{id}"; operation != want {
+		t.Errorf("Operation = %q, want %q", operation, want)
+	}
+}
@@ -0,0 +1,144 @@
+package ohdear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+)
+
+// NotificationDestinationsService handles communication with the
+// /sites/{id}/notification-destinations endpoint of Oh Dear's API.
+type NotificationDestinationsService service
+
+// NotificationDestination represents a channel the notifications about a site
+// are sent to, such as "mail", "slack" or "webhook". Destination holds the
+// settings of the channel, such as "mail" for mail or "url" for webhooks.
+type NotificationDestination struct {
+	Destination map[string]string `json:"destination,omitempty"`
+	Channel     string            `json:"channel,omitempty"`
+	ID          int               `json:"id,omitempty"`
+}
+
+// List returns the notification destinations of a site.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#notification-destinations
+func (s *NotificationDestinationsService) List(ctx context.Context, siteID uint, opts ...RequestOption) ([]NotificationDestination, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "NotificationDestinations.List")
+
+	if siteID == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(siteID)) + endpoint.NotificationDestinations
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var destinations struct {
+		Data []NotificationDestination `json:"data"`
+	}
+
+	if err := json.Unmarshal(ret.Body, &destinations); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal notification destinations: %w", err)
+	}
+
+	return destinations.Data, ret, nil
+}
+
+// Add adds a notification destination to a site.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#notification-destinations
+func (s *NotificationDestinationsService) Add(ctx context.Context, siteID uint, destination *NotificationDestination, opts ...RequestOption) (*NotificationDestination, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "NotificationDestinations.Add")
+
+	if siteID == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	if destination == nil {
+		return nil, nil, ErrNilNotificationDestination
+	}
+
+	payload, err := json.Marshal(destination)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal notification destination: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(siteID)) + endpoint.NotificationDestinations
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var addedDestination NotificationDestination
+	if err := json.Unmarshal(ret.Body, &addedDestination); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal added notification destination: %w", err)
+	}
+
+	return &addedDestination, ret, nil
+}
+
+// Remove removes a notification destination from a site.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#notification-destinations
+func (s *NotificationDestinationsService) Remove(ctx context.Context, siteID, id uint, opts ...RequestOption) (*Response, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "NotificationDestinations.Remove")
+
+	if siteID == 0 {
+		return nil, ErrInvalidSiteID
+	}
+
+	if id == 0 {
+		return nil, ErrInvalidNotificationDestinationID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(siteID)) + endpoint.NotificationDestinations + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,105 @@
+package ohdear
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Nullable is an optional field that distinguishes between a value that is
+// not set, which is left out of requests, an explicit null, which clears the
+// field, and an actual value.
+//
+// The zero value is not set. Use NewNullable and Null to create set values.
+type Nullable[T any] struct {
+	// value is the value of the field, if it's valid.
+	value T
+
+	// set is true if the field holds a value or an explicit null.
+	set bool
+
+	// valid is true if the field holds a value.
+	valid bool
+}
+
+// NewNullable returns a Nullable holding v.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{
+		value: v,
+		set:   true,
+		valid: true,
+	}
+}
+
+// Null returns a Nullable holding an explicit null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{
+		set: true,
+	}
+}
+
+// IsSet returns true if n holds a value or an explicit null.
+func (n Nullable[T]) IsSet() bool {
+	return n.set
+}
+
+// IsNull returns true if n holds an explicit null.
+func (n Nullable[T]) IsNull() bool {
+	return n.set && !n.valid
+}
+
+// Get returns the value held by n, or false if n is not set or null.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.value, n.valid
+}
+
+// Value returns the value held by n, or the zero value of T if n is not set or
+// null.
+func (n Nullable[T]) Value() T {
+	return n.value
+}
+
+// MarshalJSON implements the json.Marshaler interface. Values that are not set
+// are marshaled as null, but left out of the objects of this package.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.valid {
+		return []byte("null"), nil
+	}
+
+	data, err := json.Marshal(n.value)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*n = Null[T]()
+
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	*n = NewNullable(v)
+
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (n Nullable[T]) String() string {
+	switch {
+	case !n.set:
+		return "<unset>"
+	case !n.valid:
+		return "<null>"
+	default:
+		return fmt.Sprint(n.value)
+	}
+}
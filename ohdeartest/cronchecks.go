@@ -0,0 +1,157 @@
+package ohdeartest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+// _pingURL is the base URL of the ping URLs of cron checks.
+const _pingURL string = "https://ping.ohdear.app/"
+
+// cronCheck is a cron check along with the site it belongs to, which the API
+// doesn't return.
+type cronCheck struct {
+	ohdear.CronCheck
+
+	siteID int
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b[:])
+
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// validateCronCheck returns the validation errors of a cron check.
+func validateCronCheck(check *ohdear.CronCheck) validationErrors {
+	errs := validationErrors{}
+
+	if check.Name == "" {
+		errs.add("name", "The name field is required.")
+	}
+
+	switch check.Type {
+	case ohdear.CronCheckTypeSimple:
+		if check.FrequencyInMinutes < 1 {
+			errs.add("frequency_in_minutes", "The frequency in minutes field is required when type is simple.")
+		}
+	case ohdear.CronCheckTypeCron:
+		if check.CronExpression == "" {
+			errs.add("cron_expression", "The cron expression field is required when type is cron.")
+		}
+	default:
+		errs.add("type", "The selected type is invalid.")
+	}
+
+	return errs
+}
+
+func (s *Server) listCronChecks(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	checks := make([]ohdear.CronCheck, 0)
+
+	for _, check := range s.cronChecks {
+		if check.siteID == ids[0] {
+			checks = append(checks, check.CronCheck)
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].ID < checks[j].ID
+	})
+
+	writeJSON(w, http.StatusOK, &page[ohdear.CronCheck]{
+		Data: checks,
+	})
+}
+
+func (s *Server) addCronCheck(w http.ResponseWriter, r *http.Request, ids []int) {
+	var check ohdear.CronCheck
+	if !decode(w, r, &check) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	if validateCronCheck(&check).write(w) {
+		return
+	}
+
+	check.ID = s.nextID()
+	check.UUID = newUUID()
+	check.PingURL = _pingURL + check.UUID
+
+	s.cronChecks[check.ID] = &cronCheck{CronCheck: check, siteID: ids[0]}
+
+	writeJSON(w, http.StatusCreated, &check)
+}
+
+func (s *Server) updateCronCheck(w http.ResponseWriter, r *http.Request, ids []int) {
+	var check ohdear.CronCheck
+	if !decode(w, r, &check) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.cronChecks[ids[0]]
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	if validateCronCheck(&check).write(w) {
+		return
+	}
+
+	check.ID = stored.ID
+	check.UUID = stored.UUID
+	check.PingURL = stored.PingURL
+	stored.CronCheck = check
+
+	writeJSON(w, http.StatusOK, &check)
+}
+
+func (s *Server) removeCronCheck(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cronChecks[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	delete(s.cronChecks, ids[0])
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package ohdeartest
+
+import (
+	"net/http"
+	"sort"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+// destination is a notification destination along with the site it belongs
+// to, which the API doesn't return.
+type destination struct {
+	ohdear.NotificationDestination
+
+	siteID int
+}
+
+func (s *Server) listDestinations(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	destinations := make([]ohdear.NotificationDestination, 0)
+
+	for _, dest := range s.destinations {
+		if dest.siteID == ids[0] {
+			destinations = append(destinations, dest.NotificationDestination)
+		}
+	}
+
+	sort.Slice(destinations, func(i, j int) bool {
+		return destinations[i].ID < destinations[j].ID
+	})
+
+	writeJSON(w, http.StatusOK, &page[ohdear.NotificationDestination]{
+		Data: destinations,
+	})
+}
+
+func (s *Server) addDestination(w http.ResponseWriter, r *http.Request, ids []int) {
+	var dest ohdear.NotificationDestination
+	if !decode(w, r, &dest) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	errs := validationErrors{}
+
+	if dest.Channel == "" {
+		errs.add("channel", "The channel field is required.")
+	}
+
+	if len(dest.Destination) == 0 {
+		errs.add("destination", "The destination field is required.")
+	}
+
+	if errs.write(w) {
+		return
+	}
+
+	dest.ID = s.nextID()
+	s.destinations[dest.ID] = &destination{NotificationDestination: dest, siteID: ids[0]}
+
+	writeJSON(w, http.StatusCreated, &dest)
+}
+
+func (s *Server) removeDestination(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dest, ok := s.destinations[ids[1]]
+	if !ok || dest.siteID != ids[0] {
+		writeNotFound(w)
+
+		return
+	}
+
+	delete(s.destinations, ids[1])
+
+	w.WriteHeader(http.StatusNoContent)
+}
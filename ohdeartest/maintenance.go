@@ -0,0 +1,183 @@
+package ohdeartest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+)
+
+// DefaultMaintenanceDuration is how long maintenance started without a
+// duration lasts.
+const DefaultMaintenanceDuration time.Duration = time.Hour
+
+// MaintenancePeriod represents a maintenance period of a site.
+type MaintenancePeriod struct {
+	StartsAt jsonutil.Time `json:"starts_at"`
+	EndsAt   jsonutil.Time `json:"ends_at"`
+	ID       int           `json:"id"`
+	SiteID   int           `json:"site_id"`
+}
+
+// AddMaintenancePeriod stores a maintenance period and returns it with its ID
+// assigned.
+func (s *Server) AddMaintenancePeriod(period MaintenancePeriod) MaintenancePeriod {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	period.ID = s.nextID()
+	s.maintenancePeriods[period.ID] = &period
+
+	return period
+}
+
+// MaintenancePeriods returns every maintenance period of a site, sorted by
+// start time.
+func (s *Server) MaintenancePeriods(siteID int) []MaintenancePeriod {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.maintenancePeriodsOf(siteID)
+}
+
+// maintenancePeriodsOf returns every maintenance period of a site, sorted by
+// start time. The caller must hold s.mu.
+func (s *Server) maintenancePeriodsOf(siteID int) []MaintenancePeriod {
+	periods := make([]MaintenancePeriod, 0)
+
+	for _, period := range s.maintenancePeriods {
+		if period.SiteID == siteID {
+			periods = append(periods, *period)
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].StartsAt.Before(periods[j].StartsAt.Time)
+	})
+
+	return periods
+}
+
+func (s *Server) listMaintenancePeriods(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &page[MaintenancePeriod]{
+		Data: s.maintenancePeriodsOf(ids[0]),
+	})
+}
+
+func (s *Server) addMaintenancePeriod(w http.ResponseWriter, r *http.Request, _ []int) {
+	var period MaintenancePeriod
+	if !decode(w, r, &period) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := validationErrors{}
+
+	if _, ok := s.sites[period.SiteID]; !ok {
+		errs.add("site_id", "The selected site id is invalid.")
+	}
+
+	if period.StartsAt.IsZero() {
+		errs.add("starts_at", "The starts at field is required.")
+	}
+
+	if period.EndsAt.IsZero() {
+		errs.add("ends_at", "The ends at field is required.")
+	} else if !period.EndsAt.After(period.StartsAt.Time) {
+		errs.add("ends_at", "The ends at must be a date after starts at.")
+	}
+
+	if errs.write(w) {
+		return
+	}
+
+	period.ID = s.nextID()
+	s.maintenancePeriods[period.ID] = &period
+
+	writeJSON(w, http.StatusCreated, &period)
+}
+
+func (s *Server) removeMaintenancePeriod(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.maintenancePeriods[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	delete(s.maintenancePeriods, ids[0])
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) startMaintenance(w http.ResponseWriter, r *http.Request, ids []int) {
+	var payload struct {
+		StopAfterSeconds int `json:"stop_maintenance_after_seconds"`
+	}
+
+	if r.ContentLength != 0 && !decode(w, r, &payload) {
+		return
+	}
+
+	duration := DefaultMaintenanceDuration
+	if payload.StopAfterSeconds > 0 {
+		duration = time.Duration(payload.StopAfterSeconds) * time.Second
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	period := &MaintenancePeriod{
+		StartsAt: jsonutil.Time{Time: now},
+		EndsAt:   jsonutil.Time{Time: now.Add(duration)},
+		ID:       s.nextID(),
+		SiteID:   ids[0],
+	}
+
+	s.maintenancePeriods[period.ID] = period
+
+	writeJSON(w, http.StatusOK, period)
+}
+
+func (s *Server) stopMaintenance(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for _, period := range s.maintenancePeriods {
+		if period.SiteID == ids[0] && !period.StartsAt.After(now) && period.EndsAt.After(now) {
+			period.EndsAt = jsonutil.Time{Time: now}
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,559 @@
+// Package ohdeartest provides an in-memory fake of the Oh Dear API for use in
+// tests.
+//
+// The fake server keeps sites, checks, maintenance periods, cron checks,
+// notification destinations, status pages and uptime data in memory,
+// supports pagination, returns validation errors the same way the real API
+// does, and can simulate rate limiting and arbitrary failures.
+//
+//	srv := ohdeartest.NewServer(nil)
+//	defer srv.Close()
+//
+//	client := srv.Client()
+//	site, _, err := client.Sites.Add(ctx, &ohdear.Site{URL: "https://example.com", TeamID: 1})
+package ohdeartest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+// DefaultKey is the API key accepted by the fake server by default.
+const DefaultKey string = "ohdeartest"
+
+// DefaultTeamName is the name of the team the API key belongs to when no
+// teams are configured.
+const DefaultTeamName string = "ohdeartest"
+
+// Default values for the Options struct.
+const (
+	DefaultPerPage         int           = 15
+	DefaultRateLimitWindow time.Duration = time.Minute
+)
+
+// Options holds the configuration for a Server.
+type Options struct {
+	// Key is the API key accepted by the server. Requests using any other key
+	// are rejected with 401 Unauthorized.
+	//
+	// This field is optional. It defaults to DefaultKey.
+	Key string
+
+	// PerPage is the number of items returned in each page of paginated
+	// endpoints, unless the request sets page[size].
+	//
+	// This field is optional. It defaults to DefaultPerPage.
+	PerPage int
+
+	// RateLimit is the number of requests accepted in each RateLimitWindow
+	// before the server responds with 429 Too Many Requests.
+	//
+	// This field is optional. Rate limiting is disabled if it's zero.
+	RateLimit int
+
+	// RateLimitWindow is the length of the rate limiting window.
+	//
+	// This field is optional. It defaults to DefaultRateLimitWindow.
+	RateLimitWindow time.Duration
+
+	// Teams are the teams the API key belongs to.
+	//
+	// This field is optional. It defaults to a single team with ID 1 named
+	// DefaultTeamName.
+	Teams []ohdear.Team
+}
+
+// Failure describes requests the server should fail on purpose.
+type Failure struct {
+	// Method is the HTTP method of the requests to fail. Empty matches every
+	// method.
+	Method string
+
+	// Path is the prefix of the path of the requests to fail, relative to the
+	// base URL, such as "/sites". Empty matches every path.
+	Path string
+
+	// Body is the response body. It defaults to a JSON error message.
+	Body string
+
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Times is the number of requests to fail. Values less than one fail a
+	// single request.
+	Times int
+}
+
+// matches checks if the failure applies to the given request.
+func (f *Failure) matches(r *http.Request, path string) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+
+	return strings.HasPrefix(path, f.Path)
+}
+
+// Server is an in-memory fake of the Oh Dear API.
+type Server struct {
+	// srv is the underlying HTTP test server.
+	srv *httptest.Server
+
+	// URL is the base URL of the fake API, suitable for ohdear.Config.BaseURL.
+	URL string
+
+	// opts holds the configuration of the server.
+	opts Options
+
+	// State of the fake API.
+	sites              map[int]*ohdear.Site
+	certificates       map[int]*ohdear.CertificateHealth
+	uptime             map[int][]Uptime
+	downtime           map[int][]Downtime
+	maintenancePeriods map[int]*MaintenancePeriod
+	statusPages        map[int]*StatusPage
+	statusPageUpdates  map[int]*StatusPageUpdate
+	cronChecks         map[int]*cronCheck
+	destinations       map[int]*destination
+	failures           []*Failure
+	lastID             int
+
+	// Rate limiting state.
+	windowStart time.Time
+	windowCount int
+
+	// mu protects the state of the server.
+	mu sync.Mutex
+}
+
+// NewServer starts and returns a new fake Oh Dear API server. The caller
+// should call Close when finished, to shut it down.
+func NewServer(opts *Options) *Server {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Key == "" {
+		o.Key = DefaultKey
+	}
+
+	if o.PerPage < 1 {
+		o.PerPage = DefaultPerPage
+	}
+
+	if o.RateLimitWindow < 1 {
+		o.RateLimitWindow = DefaultRateLimitWindow
+	}
+
+	if len(o.Teams) == 0 {
+		o.Teams = []ohdear.Team{{ID: 1, Name: DefaultTeamName}}
+	}
+
+	s := &Server{
+		opts:               o,
+		sites:              make(map[int]*ohdear.Site),
+		certificates:       make(map[int]*ohdear.CertificateHealth),
+		uptime:             make(map[int][]Uptime),
+		downtime:           make(map[int][]Downtime),
+		maintenancePeriods: make(map[int]*MaintenancePeriod),
+		statusPages:        make(map[int]*StatusPage),
+		statusPageUpdates:  make(map[int]*StatusPageUpdate),
+		cronChecks:         make(map[int]*cronCheck),
+		destinations:       make(map[int]*destination),
+	}
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL + "/api"
+
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests have
+// completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a new *ohdear.Client configured to use the server.
+func (s *Server) Client() *ohdear.Client {
+	client, err := s.ClientWithConfig(ohdear.NewConfig(s.opts.Key, nil))
+	if err != nil {
+		// The configuration is fully controlled by the server, so this
+		// should never happen.
+		panic(err)
+	}
+
+	return client
+}
+
+// ClientWithConfig returns a new *ohdear.Client using the given configuration,
+// with its base URL and API key pointed at the server. If cfg.Credentials is
+// set, it takes precedence over the server's API key.
+func (s *Server) ClientWithConfig(cfg *ohdear.Config) (*ohdear.Client, error) {
+	cfg.BaseURL = s.URL
+	cfg.Key = s.opts.Key
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // returned as-is for callers to inspect
+	}
+
+	return client, nil
+}
+
+// Fail makes the server fail requests matching f.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Times < 1 {
+		f.Times = 1
+	}
+
+	s.failures = append(s.failures, &f)
+}
+
+// route maps a method and path pattern to a handler. Pattern segments equal
+// to "{id}" match numeric IDs, which are passed to the handler in order.
+type route struct {
+	handler func(w http.ResponseWriter, r *http.Request, ids []int)
+	method  string
+	pattern string
+}
+
+// routes returns the routes handled by the server.
+func (s *Server) routes() []route {
+	return []route{
+		{s.listSites, http.MethodGet, "/sites"},
+		{s.addSite, http.MethodPost, "/sites"},
+		{s.getSite, http.MethodGet, "/sites/{id}"},
+		{s.updateSite, http.MethodPut, "/sites/{id}"},
+		{s.removeSite, http.MethodDelete, "/sites/{id}"},
+		{s.getUptime, http.MethodGet, "/sites/{id}/uptime"},
+		{s.getDowntime, http.MethodGet, "/sites/{id}/downtime"},
+		{s.listMaintenancePeriods, http.MethodGet, "/sites/{id}/maintenance-periods"},
+		{s.startMaintenance, http.MethodPost, "/sites/{id}/start-maintenance"},
+		{s.stopMaintenance, http.MethodPost, "/sites/{id}/stop-maintenance"},
+		{s.enableCheck, http.MethodPost, "/checks/{id}/enable"},
+		{s.disableCheck, http.MethodPost, "/checks/{id}/disable"},
+		{s.requestCheckRun, http.MethodPost, "/checks/{id}/request-run"},
+		{s.getCertificateHealth, http.MethodGet, "/certificate-health/{id}"},
+		{s.addMaintenancePeriod, http.MethodPost, "/maintenance-periods"},
+		{s.removeMaintenancePeriod, http.MethodDelete, "/maintenance-periods/{id}"},
+		{s.listStatusPages, http.MethodGet, "/status-pages"},
+		{s.addStatusPage, http.MethodPost, "/status-pages"},
+		{s.getStatusPage, http.MethodGet, "/status-pages/{id}"},
+		{s.updateStatusPage, http.MethodPut, "/status-pages/{id}"},
+		{s.removeStatusPage, http.MethodDelete, "/status-pages/{id}"},
+		{s.listStatusPageUpdates, http.MethodGet, "/status-pages/{id}/updates"},
+		{s.addStatusPageUpdate, http.MethodPost, "/status-page-updates"},
+		{s.removeStatusPageUpdate, http.MethodDelete, "/status-page-updates/{id}"},
+		{s.listCronChecks, http.MethodGet, "/sites/{id}/cron-checks"},
+		{s.addCronCheck, http.MethodPost, "/sites/{id}/cron-checks"},
+		{s.updateCronCheck, http.MethodPut, "/cron-checks/{id}"},
+		{s.removeCronCheck, http.MethodDelete, "/cron-checks/{id}"},
+		{s.listDestinations, http.MethodGet, "/sites/{id}/notification-destinations"},
+		{s.addDestination, http.MethodPost, "/sites/{id}/notification-destinations"},
+		{s.removeDestination, http.MethodDelete, "/sites/{id}/notification-destinations/{id}"},
+		{s.getMe, http.MethodGet, "/me"},
+	}
+}
+
+// serveHTTP authenticates, rate limits and routes every request.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api"), "/")
+
+	if r.Header.Get("Authorization") != "Bearer "+s.opts.Key {
+		writeMessage(w, http.StatusUnauthorized, "Unauthenticated.")
+
+		return
+	}
+
+	if !s.allow(w) {
+		writeMessage(w, http.StatusTooManyRequests, "Too Many Attempts.")
+
+		return
+	}
+
+	if f := s.failure(r, path); f != nil {
+		if f.Body == "" {
+			writeMessage(w, f.Status, http.StatusText(f.Status))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(f.Status)
+		_, _ = w.Write([]byte(f.Body))
+
+		return
+	}
+
+	methodAllowed := true
+
+	for _, rt := range s.routes() {
+		ids, ok := matchPattern(rt.pattern, path)
+		if !ok {
+			continue
+		}
+
+		if rt.method != r.Method {
+			methodAllowed = false
+
+			continue
+		}
+
+		rt.handler(w, r, ids)
+
+		return
+	}
+
+	if !methodAllowed {
+		writeMessage(w, http.StatusMethodNotAllowed, "The method is not supported for this route.")
+
+		return
+	}
+
+	writeMessage(w, http.StatusNotFound, "Not Found.")
+}
+
+// allow applies the rate limit, setting the rate limit headers on w, and
+// reports whether the request may proceed.
+func (s *Server) allow(w http.ResponseWriter) bool {
+	if s.opts.RateLimit < 1 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(s.windowStart) >= s.opts.RateLimitWindow {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+
+	s.windowCount++
+
+	remaining := s.opts.RateLimit - s.windowCount
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.opts.RateLimit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+	if s.windowCount <= s.opts.RateLimit {
+		return true
+	}
+
+	retryAfter := s.windowStart.Add(s.opts.RateLimitWindow).Sub(now)
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+
+	return false
+}
+
+// failure returns the injected failure matching the request, if any, and
+// consumes one of its uses.
+func (s *Server) failure(r *http.Request, path string) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.failures {
+		if !f.matches(r, path) {
+			continue
+		}
+
+		f.Times--
+
+		if f.Times < 1 {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+		}
+
+		return f
+	}
+
+	return nil
+}
+
+// nextID returns a new unique ID. The caller must hold s.mu.
+func (s *Server) nextID() int {
+	s.lastID++
+
+	return s.lastID
+}
+
+// matchPattern matches path against pattern, returning the IDs captured by
+// "{id}" segments.
+func matchPattern(pattern, path string) ([]int, bool) {
+	var (
+		want = strings.Split(strings.Trim(pattern, "/"), "/")
+		got  = strings.Split(strings.Trim(path, "/"), "/")
+		ids  []int
+	)
+
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	for i := range want {
+		if want[i] != "{id}" {
+			if want[i] != got[i] {
+				return nil, false
+			}
+
+			continue
+		}
+
+		id, err := strconv.Atoi(got[i])
+		if err != nil || id < 1 {
+			return nil, false
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, true
+}
+
+// page is a page of a paginated response.
+type page[T any] struct {
+	Data  []T          `json:"data"`
+	Links ohdear.Links `json:"links"`
+	Meta  pageMeta     `json:"meta"`
+}
+
+// pageMeta contains pagination metadata, including fields not modeled by the
+// client.
+type pageMeta struct {
+	ohdear.Meta
+	PerPage int `json:"per_page"`
+}
+
+// paginate returns the page of items requested by r.
+func paginate[T any](r *http.Request, baseURL string, items []T, perPage int) *page[T] {
+	if size, err := strconv.Atoi(r.URL.Query().Get("page[size]")); err == nil && size > 0 {
+		perPage = size
+	}
+
+	number, err := strconv.Atoi(r.URL.Query().Get("page[number]"))
+	if err != nil || number < 1 {
+		number = 1
+	}
+
+	last := (len(items) + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+
+	var (
+		start = (number - 1) * perPage
+		end   = start + perPage
+	)
+
+	if start > len(items) {
+		start = len(items)
+	}
+
+	if end > len(items) {
+		end = len(items)
+	}
+
+	link := func(n int) string {
+		return baseURL + strings.TrimPrefix(r.URL.Path, "/api") + "?page[number]=" + strconv.Itoa(n)
+	}
+
+	p := &page[T]{
+		Data: items[start:end],
+		Links: ohdear.Links{
+			First: link(1),
+			Last:  link(last),
+		},
+		Meta: pageMeta{
+			Meta: ohdear.Meta{
+				CurrentPage: number,
+				LastPage:    last,
+				Pages:       len(items),
+			},
+			PerPage: perPage,
+		},
+	}
+
+	if p.Data == nil {
+		p.Data = []T{}
+	}
+
+	if number > 1 {
+		p.Links.Prev = link(number - 1)
+	}
+
+	if number < last {
+		p.Links.Next = link(number + 1)
+	}
+
+	return p
+}
+
+// validationErrors collects validation errors by field name.
+type validationErrors map[string][]string
+
+// add records a validation error for the given field.
+func (v validationErrors) add(field, message string) {
+	v[field] = append(v[field], message)
+}
+
+// write writes the validation errors as a 422 Unprocessable Entity response
+// and reports whether there were any errors to write.
+func (v validationErrors) write(w http.ResponseWriter) bool {
+	if len(v) == 0 {
+		return false
+	}
+
+	writeJSON(w, http.StatusUnprocessableEntity, &ohdear.APIError{
+		Message: "The given data was invalid.",
+		Errors:  v,
+	})
+
+	return true
+}
+
+// decode decodes the JSON body of r into v, writing a 400 Bad Request
+// response and returning false if it's invalid.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeMessage(w, http.StatusBadRequest, "The request body is not valid JSON.")
+
+		return false
+	}
+
+	return true
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeMessage writes a JSON error message with the given status code.
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, &ohdear.APIError{
+		Message: message,
+	})
+}
+
+// writeNotFound writes a 404 Not Found response for a missing resource.
+func writeNotFound(w http.ResponseWriter) {
+	writeMessage(w, http.StatusNotFound, "Not Found.")
+}
@@ -0,0 +1,179 @@
+package ohdeartest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+func TestServer_Validation(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	// The client validates the URL itself, so go through Do to reach the
+	// server's validation.
+	client := srv.Client()
+
+	req, err := client.NewRequest(
+		context.Background(),
+		http.MethodPost,
+		srv.URL+"/sites",
+		strings.NewReader(`{"url":"not a url"}`),
+	)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	_, err = client.Do(context.Background(), req)
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Do() error = %v, want *ohdear.APIError", err)
+	}
+
+	if apiErr.Status != http.StatusUnprocessableEntity {
+		t.Errorf("Status = %d, want %d", apiErr.Status, http.StatusUnprocessableEntity)
+	}
+
+	for _, field := range []string{"url", "team_id"} {
+		if len(apiErr.Errors[field]) == 0 {
+			t.Errorf("Errors[%q] is empty, want a validation error", field)
+		}
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(&ohdeartest.Options{
+		PerPage: 2,
+	})
+	defer srv.Close()
+
+	for i := 0; i < 5; i++ {
+		srv.AddSite(&ohdear.Site{
+			URL:    "https://example" + strconv.Itoa(i) + ".com",
+			TeamID: 1,
+		})
+	}
+
+	client := srv.Client()
+
+	first, pagination, _, err := client.Sites.List(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(first.Data) != 2 || !pagination.HasNextPage() || pagination.HasPrevPage() {
+		t.Errorf("List(1) = %d sites, next %v, prev %v", len(first.Data), pagination.HasNextPage(), pagination.HasPrevPage())
+	}
+
+	if pagination.Meta.LastPage != 3 || pagination.Meta.Pages != 5 {
+		t.Errorf("Meta = %+v, want last page 3 and 5 items", pagination.Meta)
+	}
+
+	sites, err := client.Sites.ListAll(context.Background())
+	if err != nil {
+		t.Fatalf("ListAll() error = %v", err)
+	}
+
+	if len(sites) != 5 {
+		t.Errorf("ListAll() = %d sites, want 5", len(sites))
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(&ohdeartest.Options{
+		RateLimit:       2,
+		RateLimitWindow: time.Hour,
+	})
+	defer srv.Close()
+
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+
+	for i, status := range want {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/sites", http.NoBody)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+ohdeartest.DefaultKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode != status {
+			t.Errorf("request %d: status = %d, want %d", i, resp.StatusCode, status)
+		}
+
+		if status == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Errorf("request %d: Retry-After header is missing", i)
+		}
+	}
+}
+
+func TestServer_Fail(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	site := srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+
+	srv.Fail(ohdeartest.Failure{
+		Method: http.MethodGet,
+		Path:   "/sites",
+		Status: http.StatusInternalServerError,
+	})
+
+	client := srv.Client()
+
+	_, _, err := client.Sites.Get(context.Background(), uint(site.ID))
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusInternalServerError {
+		t.Fatalf("Get() error = %v, want a 500 *ohdear.APIError", err)
+	}
+
+	// The failure is consumed by the first request.
+	if _, _, err = client.Sites.Get(context.Background(), uint(site.ID)); err != nil {
+		t.Errorf("Get() error = %v after the failure was consumed", err)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	cfg := ohdear.NewConfig("wrong", nil)
+	cfg.BaseURL = srv.URL
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, _, _, err = client.Sites.List(context.Background(), 1)
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("List() error = %v, want a 401 *ohdear.APIError", err)
+	}
+}
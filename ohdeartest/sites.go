@@ -0,0 +1,491 @@
+package ohdeartest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
+)
+
+// checkLabel returns the label of the given check type.
+func checkLabel(checkType ohdear.CheckType) string {
+	switch checkType {
+	case ohdear.CheckTypeUptime:
+		return "Uptime"
+	case ohdear.CheckTypePerformance:
+		return "Performance"
+	case ohdear.CheckTypeBrokenLinks:
+		return "Broken links"
+	case ohdear.CheckTypeMixedContent:
+		return "Mixed content"
+	case ohdear.CheckTypeCertificateHealth:
+		return "Certificate health"
+	case ohdear.CheckTypeCertificateTransparency:
+		return "Certificate transparency"
+	case ohdear.CheckTypeDNS:
+		return "DNS records"
+	default:
+		return string(checkType)
+	}
+}
+
+// defaultChecks returns the check types enabled by default for a site.
+func defaultChecks(usesHTTPS bool) []ohdear.CheckType {
+	checks := []ohdear.CheckType{ohdear.CheckTypeUptime, ohdear.CheckTypePerformance, ohdear.CheckTypeBrokenLinks, ohdear.CheckTypeDNS}
+
+	if usesHTTPS {
+		checks = append(checks, ohdear.CheckTypeMixedContent, ohdear.CheckTypeCertificateHealth, ohdear.CheckTypeCertificateTransparency)
+	}
+
+	return checks
+}
+
+// AddSite adds a site directly to the server's state, bypassing validation,
+// and returns it with its IDs filled in. The checks of the site are kept as
+// given, or the default checks are enabled if it has none.
+func (s *Server) AddSite(site *ohdear.Site) *ohdear.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.storeSite(site, nil)
+}
+
+// Site returns a copy of the site with the given ID.
+func (s *Server) Site(id int) (*ohdear.Site, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.sites[id]
+	if !ok {
+		return nil, false
+	}
+
+	return copySite(site), true
+}
+
+// Sites returns a copy of every site, sorted by ID.
+func (s *Server) Sites() []*ohdear.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedSites()
+}
+
+// SetCheckResult sets the latest result of the check of the given type on a
+// site and recomputes the summarized result of the site.
+func (s *Server) SetCheckResult(siteID int, checkType ohdear.CheckType, result ohdear.CheckResult) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.sites[siteID]
+	if !ok {
+		return false
+	}
+
+	for i := range site.Checks {
+		if site.Checks[i].Type != checkType {
+			continue
+		}
+
+		site.Checks[i].LatestRunResult = result
+		site.Checks[i].LatestRunEndedAt = jsonutil.Time{Time: time.Now().UTC().Truncate(time.Second)}
+		site.SummarizedCheckResult = summarize(site.Checks)
+
+		return true
+	}
+
+	return false
+}
+
+// SetCertificateHealth sets the certificate health returned for a site.
+func (s *Server) SetCertificateHealth(siteID int, health *ohdear.CertificateHealth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.certificates[siteID] = health
+}
+
+// storeSite assigns IDs to site and its checks and stores a copy of it. If
+// checkTypes is not empty, it replaces the checks of the site. If the site ends
+// up without checks, the default checks are enabled. The caller must hold
+// s.mu.
+func (s *Server) storeSite(site *ohdear.Site, checkTypes []ohdear.CheckType) *ohdear.Site {
+	stored := copySite(site)
+	now := jsonutil.Time{Time: time.Now().UTC().Truncate(time.Second)}
+
+	stored.ID = s.nextID()
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	stored.UsesHTTPS = strings.HasPrefix(stored.URL, "https://")
+	stored.SortURL = sortURL(stored.URL)
+
+	if stored.Label == "" {
+		stored.Label = stored.SortURL
+	}
+
+	if len(checkTypes) == 0 && len(stored.Checks) == 0 {
+		checkTypes = defaultChecks(stored.UsesHTTPS)
+	}
+
+	if len(checkTypes) > 0 {
+		stored.Checks = make([]ohdear.Check, 0, len(checkTypes))
+
+		for _, checkType := range checkTypes {
+			stored.Checks = append(stored.Checks, ohdear.Check{
+				Type:    checkType,
+				Enabled: true,
+			})
+		}
+	}
+
+	for i := range stored.Checks {
+		check := &stored.Checks[i]
+		check.ID = s.nextID()
+
+		if check.Label == "" {
+			check.Label = checkLabel(check.Type)
+		}
+
+		if check.LatestRunResult == "" {
+			check.LatestRunResult = ohdear.CheckResultPending
+		}
+	}
+
+	stored.SummarizedCheckResult = summarize(stored.Checks)
+
+	s.sites[stored.ID] = stored
+
+	return copySite(stored)
+}
+
+// sortedSites returns a copy of every site, sorted by ID. The caller must hold
+// s.mu.
+func (s *Server) sortedSites() []*ohdear.Site {
+	sites := make([]*ohdear.Site, 0, len(s.sites))
+
+	for _, site := range s.sites {
+		sites = append(sites, copySite(site))
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		return sites[i].ID < sites[j].ID
+	})
+
+	return sites
+}
+
+// findCheck returns the site and check with the given check ID. The caller
+// must hold s.mu.
+func (s *Server) findCheck(id int) (*ohdear.Site, *ohdear.Check) {
+	for _, site := range s.sites {
+		for i := range site.Checks {
+			if site.Checks[i].ID == id {
+				return site, &site.Checks[i]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *Server) listSites(w http.ResponseWriter, r *http.Request, _ []int) {
+	s.mu.Lock()
+	sites := s.sortedSites()
+	s.mu.Unlock()
+
+	if teamID := r.URL.Query().Get("filter[team_id]"); teamID != "" {
+		filtered := sites[:0]
+
+		for _, site := range sites {
+			if teamID == strconv.Itoa(site.TeamID) {
+				filtered = append(filtered, site)
+			}
+		}
+
+		sites = filtered
+	}
+
+	writeJSON(w, http.StatusOK, paginate(r, s.URL, sites, s.opts.PerPage))
+}
+
+func (s *Server) addSite(w http.ResponseWriter, r *http.Request, _ []int) {
+	var payload map[string]json.RawMessage
+	if !decode(w, r, &payload) {
+		return
+	}
+
+	// The API accepts a list of check types on creation, which doesn't fit
+	// the Checks field of ohdear.Site.
+	var checks []ohdear.CheckType
+
+	if raw, ok := payload["checks"]; ok {
+		delete(payload, "checks")
+
+		if err := json.Unmarshal(raw, &checks); err != nil {
+			checks = nil
+		}
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	var site ohdear.Site
+	if err = json.Unmarshal(raw, &site); err != nil {
+		writeMessage(w, http.StatusBadRequest, "The request body is not valid JSON.")
+
+		return
+	}
+
+	errs := validationErrors{}
+
+	if site.URL == "" {
+		errs.add("url", "The url field is required.")
+	} else if err = urlutil.Validate(site.URL); err != nil {
+		errs.add("url", "The url format is invalid.")
+	}
+
+	if site.TeamID == 0 {
+		errs.add("team_id", "The team id field is required.")
+	}
+
+	if errs.write(w) {
+		return
+	}
+
+	s.mu.Lock()
+	stored := s.storeSite(&site, checks)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, stored)
+}
+
+func (s *Server) getSite(w http.ResponseWriter, _ *http.Request, ids []int) {
+	site, ok := s.Site(ids[0])
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, site)
+}
+
+func (s *Server) updateSite(w http.ResponseWriter, r *http.Request, ids []int) {
+	var payload map[string]json.RawMessage
+	if !decode(w, r, &payload) {
+		return
+	}
+
+	// Fields managed by the server can't be changed.
+	for _, field := range []string{"id", "team_id", "checks", "created_at", "updated_at", "sort_url"} {
+		delete(payload, field)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.sites[ids[0]]
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	updated := copySite(site)
+	if err = json.Unmarshal(raw, updated); err != nil {
+		writeMessage(w, http.StatusBadRequest, "The request body is not valid JSON.")
+
+		return
+	}
+
+	errs := validationErrors{}
+
+	if err = urlutil.Validate(updated.URL); err != nil {
+		errs.add("url", "The url format is invalid.")
+	}
+
+	if errs.write(w) {
+		return
+	}
+
+	updated.UpdatedAt = jsonutil.Time{Time: time.Now().UTC().Truncate(time.Second)}
+	updated.UsesHTTPS = strings.HasPrefix(updated.URL, "https://")
+	updated.SortURL = sortURL(updated.URL)
+
+	s.sites[updated.ID] = updated
+
+	writeJSON(w, http.StatusOK, copySite(updated))
+}
+
+func (s *Server) removeSite(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	delete(s.sites, ids[0])
+	delete(s.certificates, ids[0])
+	delete(s.uptime, ids[0])
+	delete(s.downtime, ids[0])
+
+	for id, check := range s.cronChecks {
+		if check.siteID == ids[0] {
+			delete(s.cronChecks, id)
+		}
+	}
+
+	for id, dest := range s.destinations {
+		if dest.siteID == ids[0] {
+			delete(s.destinations, id)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) enableCheck(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.setCheckEnabled(w, ids[0], true)
+}
+
+func (s *Server) disableCheck(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.setCheckEnabled(w, ids[0], false)
+}
+
+// setCheckEnabled enables or disables a check and writes it as the response.
+func (s *Server) setCheckEnabled(w http.ResponseWriter, id int, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, check := s.findCheck(id)
+	if check == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	check.Enabled = enabled
+	site.SummarizedCheckResult = summarize(site.Checks)
+
+	writeJSON(w, http.StatusOK, check)
+}
+
+func (s *Server) requestCheckRun(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, check := s.findCheck(ids[0])
+	if check == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	if !check.Enabled {
+		writeMessage(w, http.StatusUnprocessableEntity, "The check is not enabled.")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, check)
+}
+
+func (s *Server) getCertificateHealth(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.sites[ids[0]]
+	if !ok || !site.UsesHTTPS {
+		writeNotFound(w)
+
+		return
+	}
+
+	health, ok := s.certificates[ids[0]]
+	if !ok {
+		health = &ohdear.CertificateHealth{}
+	}
+
+	writeJSON(w, http.StatusOK, health)
+}
+
+// summarize returns the summarized result of the enabled checks of a site,
+// which is the worst of their results.
+func summarize(checks []ohdear.Check) ohdear.CheckResult {
+	rank := map[ohdear.CheckResult]int{
+		ohdear.CheckResultSucceeded:         1,
+		ohdear.CheckResultPending:           2,
+		ohdear.CheckResultWarning:           3,
+		ohdear.CheckResultFailed:            4,
+		ohdear.CheckResultErroredOrTimedOut: 5,
+	}
+
+	summary := ohdear.CheckResultSucceeded
+
+	for i := range checks {
+		if !checks[i].Enabled {
+			continue
+		}
+
+		if rank[checks[i].LatestRunResult] > rank[summary] {
+			summary = checks[i].LatestRunResult
+		}
+	}
+
+	return summary
+}
+
+// sortURL returns the normalized URL without its scheme, the way Oh Dear
+// sorts sites.
+func sortURL(uri string) string {
+	sorted, err := urlutil.SortURL(uri)
+	if err != nil {
+		return uri
+	}
+
+	return sorted
+}
+
+// copySite returns a deep copy of site.
+func copySite(site *ohdear.Site) *ohdear.Site {
+	c := *site
+	c.Checks = append([]ohdear.Check(nil), site.Checks...)
+	c.Tags = append([]string(nil), site.Tags...)
+
+	if site.Uptime != nil {
+		uptime := *site.Uptime
+		uptime.ExpectedStatusCodes = append([]string(nil), site.Uptime.ExpectedStatusCodes...)
+		uptime.Headers = append([]ohdear.UptimeHeader(nil), site.Uptime.Headers...)
+		uptime.Payload = append([]ohdear.UptimePayloadField(nil), site.Uptime.Payload...)
+		c.Uptime = &uptime
+	}
+
+	if site.Extra != nil {
+		c.Extra = make(map[string]json.RawMessage, len(site.Extra))
+		for name, value := range site.Extra {
+			c.Extra[name] = value
+		}
+	}
+
+	return &c
+}
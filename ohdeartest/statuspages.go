@@ -0,0 +1,252 @@
+package ohdeartest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+)
+
+// StatusPage represents a status page.
+type StatusPage struct {
+	Title            string `json:"title"`
+	Slug             string `json:"slug,omitempty"`
+	SummarizedStatus string `json:"summarized_status,omitempty"`
+	SiteIDs          []int  `json:"site_ids,omitempty"`
+	ID               int    `json:"id"`
+	TeamID           int    `json:"team_id"`
+}
+
+// StatusPageUpdate represents an update posted to a status page.
+type StatusPageUpdate struct {
+	Time         jsonutil.Time `json:"time"`
+	Title        string        `json:"title"`
+	Text         string        `json:"text"`
+	Severity     string        `json:"severity"`
+	ID           int           `json:"id"`
+	StatusPageID int           `json:"status_page_id"`
+	Pinned       bool          `json:"pinned"`
+}
+
+// StatusPageUpdates returns every update posted to a status page, newest
+// first.
+func (s *Server) StatusPageUpdates(statusPageID int) []StatusPageUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.statusPageUpdatesOf(statusPageID)
+}
+
+// statusPageUpdatesOf returns every update posted to a status page, newest
+// first. The caller must hold s.mu.
+func (s *Server) statusPageUpdatesOf(statusPageID int) []StatusPageUpdate {
+	updates := make([]StatusPageUpdate, 0)
+
+	for _, update := range s.statusPageUpdates {
+		if update.StatusPageID == statusPageID {
+			updates = append(updates, *update)
+		}
+	}
+
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].Time.Equal(updates[j].Time.Time) {
+			return updates[i].ID > updates[j].ID
+		}
+
+		return updates[i].Time.After(updates[j].Time.Time)
+	})
+
+	return updates
+}
+
+func (s *Server) listStatusPages(w http.ResponseWriter, r *http.Request, _ []int) {
+	s.mu.Lock()
+
+	pages := make([]StatusPage, 0, len(s.statusPages))
+	for _, statusPage := range s.statusPages {
+		pages = append(pages, *statusPage)
+	}
+
+	s.mu.Unlock()
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].ID < pages[j].ID
+	})
+
+	writeJSON(w, http.StatusOK, paginate(r, s.URL, pages, s.opts.PerPage))
+}
+
+func (s *Server) addStatusPage(w http.ResponseWriter, r *http.Request, _ []int) {
+	var statusPage StatusPage
+	if !decode(w, r, &statusPage) {
+		return
+	}
+
+	errs := validationErrors{}
+
+	if statusPage.Title == "" {
+		errs.add("title", "The title field is required.")
+	}
+
+	if statusPage.TeamID == 0 {
+		errs.add("team_id", "The team id field is required.")
+	}
+
+	if errs.write(w) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statusPage.ID = s.nextID()
+	statusPage.SummarizedStatus = "up"
+
+	if statusPage.Slug == "" {
+		statusPage.Slug = "status-page-" + strconv.Itoa(statusPage.ID)
+	}
+
+	s.statusPages[statusPage.ID] = &statusPage
+
+	writeJSON(w, http.StatusCreated, &statusPage)
+}
+
+func (s *Server) getStatusPage(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statusPage, ok := s.statusPages[ids[0]]
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statusPage)
+}
+
+func (s *Server) updateStatusPage(w http.ResponseWriter, r *http.Request, ids []int) {
+	var payload StatusPage
+	if !decode(w, r, &payload) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statusPage, ok := s.statusPages[ids[0]]
+	if !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	// Only the fields sent are changed, and the team can't be.
+	if payload.Title != "" {
+		statusPage.Title = payload.Title
+	}
+
+	if payload.Slug != "" {
+		statusPage.Slug = payload.Slug
+	}
+
+	if payload.SiteIDs != nil {
+		statusPage.SiteIDs = payload.SiteIDs
+	}
+
+	writeJSON(w, http.StatusOK, statusPage)
+}
+
+func (s *Server) removeStatusPage(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.statusPages[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	delete(s.statusPages, ids[0])
+
+	for id, update := range s.statusPageUpdates {
+		if update.StatusPageID == ids[0] {
+			delete(s.statusPageUpdates, id)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listStatusPageUpdates(w http.ResponseWriter, r *http.Request, ids []int) {
+	s.mu.Lock()
+
+	if _, ok := s.statusPages[ids[0]]; !ok {
+		s.mu.Unlock()
+		writeNotFound(w)
+
+		return
+	}
+
+	updates := s.statusPageUpdatesOf(ids[0])
+
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, paginate(r, s.URL, updates, s.opts.PerPage))
+}
+
+func (s *Server) addStatusPageUpdate(w http.ResponseWriter, r *http.Request, _ []int) {
+	var update StatusPageUpdate
+	if !decode(w, r, &update) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := validationErrors{}
+
+	if _, ok := s.statusPages[update.StatusPageID]; !ok {
+		errs.add("status_page_id", "The selected status page id is invalid.")
+	}
+
+	if update.Title == "" {
+		errs.add("title", "The title field is required.")
+	}
+
+	switch update.Severity {
+	case "info", "warning", "high", "resolved", "scheduled":
+	default:
+		errs.add("severity", "The selected severity is invalid.")
+	}
+
+	if errs.write(w) {
+		return
+	}
+
+	if update.Time.IsZero() {
+		update.Time = jsonutil.Time{Time: time.Now().UTC().Truncate(time.Second)}
+	}
+
+	update.ID = s.nextID()
+	s.statusPageUpdates[update.ID] = &update
+
+	writeJSON(w, http.StatusCreated, &update)
+}
+
+func (s *Server) removeStatusPageUpdate(w http.ResponseWriter, _ *http.Request, ids []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.statusPageUpdates[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	delete(s.statusPageUpdates, ids[0])
+
+	w.WriteHeader(http.StatusNoContent)
+}
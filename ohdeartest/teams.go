@@ -0,0 +1,19 @@
+package ohdeartest
+
+import (
+	"net/http"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+)
+
+// _userID is the ID of the owner of the API key.
+const _userID int = 1
+
+func (s *Server) getMe(w http.ResponseWriter, _ *http.Request, _ []int) {
+	writeJSON(w, http.StatusOK, &ohdear.User{
+		ID:    _userID,
+		Name:  "Oh Dear Test",
+		Email: "ohdeartest@example.com",
+		Teams: s.opts.Teams,
+	})
+}
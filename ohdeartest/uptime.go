@@ -0,0 +1,138 @@
+package ohdeartest
+
+import (
+	"net/http"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+)
+
+// _filterLayout is the layout of the time filters accepted by the uptime and
+// downtime endpoints.
+const _filterLayout string = "20060102150405"
+
+// Uptime represents the uptime of a site during a period of time.
+type Uptime struct {
+	Datetime         jsonutil.Time `json:"datetime"`
+	UptimePercentage float64       `json:"uptime_percentage"`
+}
+
+// Downtime represents a period of time during which a site was down.
+type Downtime struct {
+	StartedAt jsonutil.Time `json:"started_at"`
+	EndedAt   jsonutil.Time `json:"ended_at"`
+	ID        int           `json:"id"`
+}
+
+// SetUptime sets the uptime entries returned for a site.
+func (s *Server) SetUptime(siteID int, entries []Uptime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.uptime[siteID] = append([]Uptime(nil), entries...)
+}
+
+// SetDowntime sets the downtime periods returned for a site. Periods without
+// an ID are assigned one.
+func (s *Server) SetDowntime(siteID int, periods []Downtime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := make([]Downtime, len(periods))
+
+	for i := range periods {
+		stored[i] = periods[i]
+
+		if stored[i].ID == 0 {
+			stored[i].ID = s.nextID()
+		}
+	}
+
+	s.downtime[siteID] = stored
+}
+
+// timeRange parses the filter[started_at] and filter[ended_at] query
+// parameters, writing a validation error if they're invalid.
+func timeRange(w http.ResponseWriter, r *http.Request) (start, end time.Time, ok bool) {
+	var (
+		query = r.URL.Query()
+		errs  = validationErrors{}
+		err   error
+	)
+
+	start, err = time.Parse(_filterLayout, query.Get("filter[started_at]"))
+	if err != nil {
+		errs.add("filter.started_at", "The filter.started_at does not match the format YmdHis.")
+	}
+
+	end, err = time.Parse(_filterLayout, query.Get("filter[ended_at]"))
+	if err != nil {
+		errs.add("filter.ended_at", "The filter.ended_at does not match the format YmdHis.")
+	}
+
+	if errs.write(w) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
+
+func (s *Server) getUptime(w http.ResponseWriter, r *http.Request, ids []int) {
+	start, end, ok := timeRange(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok = s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	entries := make([]Uptime, 0, len(s.uptime[ids[0]]))
+
+	for _, entry := range s.uptime[ids[0]] {
+		if !entry.Datetime.Before(start) && !entry.Datetime.After(end) {
+			entries = append(entries, entry)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) getDowntime(w http.ResponseWriter, r *http.Request, ids []int) {
+	start, end, ok := timeRange(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok = s.sites[ids[0]]; !ok {
+		writeNotFound(w)
+
+		return
+	}
+
+	periods := make([]Downtime, 0, len(s.downtime[ids[0]]))
+
+	for _, period := range s.downtime[ids[0]] {
+		// Ongoing downtime has no end yet.
+		endedAt := period.EndedAt.Time
+		if endedAt.IsZero() {
+			endedAt = end
+		}
+
+		if period.StartedAt.Before(end) && endedAt.After(start) {
+			periods = append(periods, period)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, &page[Downtime]{
+		Data: periods,
+	})
+}
@@ -0,0 +1,139 @@
+package ohdear
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// requestOptionsKey is the context key for requestOptions.
+type requestOptionsKey struct{}
+
+// RequestOption overrides the configuration of the client for a single call.
+// Every service method accepts a list of them.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the overrides for a single call.
+type requestOptions struct {
+	// header holds additional headers for the request.
+	header http.Header
+
+	// query holds additional query parameters for the request.
+	query url.Values
+
+	// retry overrides the retry policy of the client.
+	retry *RetryPolicy
+
+	// timeout overrides the timeout of the client.
+	timeout time.Duration
+
+	// idempotent marks the call as safe to retry regardless of its method.
+	idempotent bool
+
+	// noCache makes the call bypass the cache of the client.
+	noCache bool
+}
+
+// WithTimeout overrides Config.Timeout for the call, including retries.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetryPolicy overrides the retry policy of the client for the call.
+func WithRetryPolicy(policy *RetryPolicy) RequestOption {
+	return func(o *requestOptions) {
+		o.retry = policy
+	}
+}
+
+// WithHeader sets a header on the request, replacing any value set by the
+// client.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+
+		o.header.Set(key, value)
+	}
+}
+
+// WithQuery adds a query parameter to the request.
+func WithQuery(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+
+		o.query.Add(key, value)
+	}
+}
+
+// WithIdempotent marks the call as safe to send more than once, so it's
+// retried on any retryable status code even if its method is not idempotent,
+// such as POST.
+func WithIdempotent() RequestOption {
+	return func(o *requestOptions) {
+		o.idempotent = true
+	}
+}
+
+// withoutCache makes the call go to the API even if a Cache is configured,
+// for lookups that must see changes made moments ago.
+func withoutCache() RequestOption {
+	return func(o *requestOptions) {
+		o.noCache = true
+	}
+}
+
+// newRequestOptions returns the requestOptions resulting from opts.
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	return o
+}
+
+// apply sets the headers and query parameters of the options on req.
+func (o *requestOptions) apply(req *http.Request) {
+	for key, values := range o.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	if len(o.query) == 0 {
+		return
+	}
+
+	query := req.URL.Query()
+
+	for key, values := range o.query {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+
+	req.URL.RawQuery = query.Encode()
+}
+
+// withRequestOptions returns a copy of ctx holding o.
+func withRequestOptions(ctx context.Context, o *requestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey{}, o)
+}
+
+// requestOptionsFromContext returns the requestOptions stored in ctx, or empty
+// options if there are none.
+func requestOptionsFromContext(ctx context.Context) *requestOptions {
+	if o, ok := ctx.Value(requestOptionsKey{}).(*requestOptions); ok {
+		return o
+	}
+
+	return &requestOptions{}
+}
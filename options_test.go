@@ -0,0 +1,259 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+// fastRetries is a retry policy that doesn't slow tests down.
+var fastRetries = &ohdear.RetryPolicy{
+	MaxRetries: 3,
+	MinDelay:   time.Millisecond,
+	MaxDelay:   time.Millisecond,
+}
+
+// recordingMiddleware returns a Middleware that stores the last request sent
+// through it, along with the number of attempts made to send it.
+func recordingMiddleware(last **http.Request, attempts *int) ohdear.Middleware {
+	return func(next ohdear.Doer) ohdear.Doer {
+		return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+
+			*last = req
+
+			if info, ok := ohdear.CallInfoFromContext(req.Context()); ok {
+				*attempts = info.Attempts()
+			}
+
+			return resp, err
+		})
+	}
+}
+
+func TestRequestOptions_Retry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = context.Background()
+		last     *http.Request
+		attempts int
+	)
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	cfg := ohdear.NewConfig("", nil)
+	cfg.Middleware = []ohdear.Middleware{recordingMiddleware(&last, &attempts)}
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	srv.Fail(ohdeartest.Failure{Method: http.MethodGet, Path: "/sites", Status: http.StatusServiceUnavailable, Times: 2})
+
+	if _, _, _, err = client.Sites.List(ctx, 1, ohdear.WithRetryPolicy(fastRetries)); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("List() attempts = %d, want 3", attempts)
+	}
+
+	site := &ohdear.Site{URL: "https://example.com", TeamID: 1}
+
+	// POST requests are not retried unless they're marked as idempotent.
+	srv.Fail(ohdeartest.Failure{Method: http.MethodPost, Path: "/sites", Status: http.StatusServiceUnavailable})
+
+	_, _, err = client.Sites.Add(ctx, site, ohdear.WithRetryPolicy(fastRetries))
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable || attempts != 1 {
+		t.Errorf("Add() error = %v after %d attempts, want a 503 *ohdear.APIError after 1 attempt", err, attempts)
+	}
+
+	srv.Fail(ohdeartest.Failure{Method: http.MethodPost, Path: "/sites", Status: http.StatusServiceUnavailable})
+
+	added, _, err := client.Sites.Add(ctx, site, ohdear.WithRetryPolicy(fastRetries), ohdear.WithIdempotent())
+	if err != nil {
+		t.Fatalf("Add() with WithIdempotent() error = %v", err)
+	}
+
+	if added.URL != site.URL || attempts != 2 {
+		t.Errorf("Add() = %+v after %d attempts, want the site after 2 attempts", added, attempts)
+	}
+}
+
+func TestConfig_NoRetries(t *testing.T) {
+	t.Parallel()
+
+	var (
+		last     *http.Request
+		attempts int
+	)
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	cfg := ohdear.NewConfig("", nil)
+	cfg.MaxRetries = -1
+	cfg.Middleware = []ohdear.Middleware{recordingMiddleware(&last, &attempts)}
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	srv.Fail(ohdeartest.Failure{Method: http.MethodGet, Path: "/sites", Status: http.StatusServiceUnavailable})
+
+	_, _, _, err = client.Sites.List(context.Background(), 1)
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable || attempts != 1 {
+		t.Errorf("List() error = %v after %d attempts, want a 503 *ohdear.APIError after 1 attempt", err, attempts)
+	}
+}
+
+func TestConfig_DefaultRetries(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	cfg := &ohdear.Config{Application: ohdear.DefaultApplication()}
+
+	if _, err := srv.ClientWithConfig(cfg); err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	if cfg.MaxRetries != ohdear.DefaultMaxRetries {
+		t.Errorf("MaxRetries = %d, want %d", cfg.MaxRetries, ohdear.DefaultMaxRetries)
+	}
+}
+
+func TestSetAPILocation(t *testing.T) {
+	t.Parallel()
+
+	if err := ohdear.SetAPILocation(nil); err != nil {
+		t.Fatalf("SetAPILocation() error = %v", err)
+	}
+
+	if err := ohdear.SetAPILocation(time.UTC); !errors.Is(err, ohdear.ErrAPILocationSet) {
+		t.Errorf("SetAPILocation() error = %v, want %v", err, ohdear.ErrAPILocationSet)
+	}
+}
+
+func TestRequestOptions_Request(t *testing.T) {
+	t.Parallel()
+
+	var (
+		last     *http.Request
+		attempts int
+	)
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	cfg := ohdear.NewConfig("", nil)
+	cfg.Middleware = []ohdear.Middleware{recordingMiddleware(&last, &attempts)}
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	_, _, _, err = client.Sites.List(
+		context.Background(),
+		2,
+		ohdear.WithHeader("X-Request-Id", "abc"),
+		ohdear.WithQuery("filter[team_id]", "1"),
+	)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if got := last.Header.Get("X-Request-Id"); got != "abc" {
+		t.Errorf("X-Request-Id header = %q, want %q", got, "abc")
+	}
+
+	query := last.URL.Query()
+	if query.Get("filter[team_id]") != "1" || query.Get("page[number]") != "2" {
+		t.Errorf("query = %v, want filter[team_id]=1 and page[number]=2", query)
+	}
+}
+
+func TestRequestOptions_Timeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := ohdear.NewConfig(_testKey, nil)
+	cfg.Middleware = []ohdear.Middleware{
+		func(_ ohdear.Doer) ohdear.Doer {
+			return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+
+				return nil, req.Context().Err()
+			})
+		},
+	}
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	start := time.Now()
+
+	_, _, err = client.Sites.Get(context.Background(), 1, ohdear.WithTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get() took %v, want it to stop after the timeout", elapsed)
+	}
+}
+
+func TestRequestOptions_LongTimeout(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("skipping slow test in short mode")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(12 * time.Second):
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"url":"https://example.com"}`)
+	}))
+	defer srv.Close()
+
+	cfg := ohdear.NewConfig(_testKey, nil)
+	cfg.BaseURL = srv.URL
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	site, _, err := client.Sites.Get(context.Background(), 1, ohdear.WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if site.ID != 1 {
+		t.Errorf("Get() = %+v, want site 1", site)
+	}
+}
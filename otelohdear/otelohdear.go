@@ -0,0 +1,168 @@
+// Package otelohdear provides [OpenTelemetry] instrumentation for the ohdear
+// package.
+//
+// It's implemented as an ohdear.Middleware producing one span per API call,
+// named after the service method that made it, and recording request latency
+// and error counts.
+//
+// [OpenTelemetry]: https://opentelemetry.io
+package otelohdear
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/build"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for tracers and meters.
+const ScopeName string = "git.sr.ht/~jamesponddotco/ohdear-go/otelohdear"
+
+// SpanPrefix is prepended to the operation name of every span.
+const SpanPrefix string = "ohdear."
+
+// Attribute keys used by the instrumentation.
+const (
+	AttributeOperation  attribute.Key = "ohdear.operation"
+	AttributeRetryCount attribute.Key = "ohdear.retry_count"
+	AttributeMethod     attribute.Key = "http.method"
+	AttributeURL        attribute.Key = "http.url"
+	AttributeStatusCode attribute.Key = "http.status_code"
+)
+
+// Options holds the configuration for the instrumentation.
+type Options struct {
+	// TracerProvider is used to create the tracer.
+	//
+	// This field is optional. It defaults to the global TracerProvider.
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider is used to create the meter.
+	//
+	// This field is optional. It defaults to the global MeterProvider.
+	MeterProvider metric.MeterProvider
+}
+
+// instrumentation holds the instruments used by the middleware.
+type instrumentation struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// Middleware returns an ohdear.Middleware that traces every API call and
+// records its metrics. Add it to ohdear.Config.Middleware to enable it.
+func Middleware(opts *Options) (ohdear.Middleware, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.TracerProvider == nil {
+		o.TracerProvider = otel.GetTracerProvider()
+	}
+
+	if o.MeterProvider == nil {
+		o.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := o.MeterProvider.Meter(ScopeName, metric.WithInstrumentationVersion(build.Version))
+
+	duration, err := meter.Float64Histogram(
+		"ohdear.client.duration",
+		metric.WithDescription("Duration of calls to the Oh Dear API, including retries."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create duration histogram: %w", err)
+	}
+
+	errs, err := meter.Int64Counter(
+		"ohdear.client.errors",
+		metric.WithDescription("Number of calls to the Oh Dear API that failed."),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create errors counter: %w", err)
+	}
+
+	inst := &instrumentation{
+		tracer:   o.TracerProvider.Tracer(ScopeName, trace.WithInstrumentationVersion(build.Version)),
+		duration: duration,
+		errors:   errs,
+	}
+
+	return inst.middleware, nil
+}
+
+// middleware implements ohdear.Middleware.
+func (inst *instrumentation) middleware(next ohdear.Doer) ohdear.Doer {
+	return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+		// Clients always set CallInfo. The method alone is used for other
+		// callers, as the raw path would create a series per resource.
+		operation := req.Method
+
+		info, ok := ohdear.CallInfoFromContext(req.Context())
+		if ok {
+			operation = info.Operation
+		}
+
+		ctx, span := inst.tracer.Start(
+			req.Context(),
+			SpanPrefix+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				AttributeOperation.String(operation),
+				AttributeMethod.String(req.Method),
+				AttributeURL.String(req.URL.String()),
+			),
+		)
+		defer span.End()
+
+		start := time.Now()
+
+		resp, err := next.Do(req.WithContext(ctx))
+
+		var (
+			elapsed = time.Since(start).Seconds()
+			status  = "error"
+		)
+
+		if ok && info.Attempts() > 1 {
+			span.SetAttributes(AttributeRetryCount.Int(info.Attempts() - 1))
+		}
+
+		if resp != nil {
+			status = strconv.Itoa(resp.StatusCode)
+
+			span.SetAttributes(AttributeStatusCode.Int(resp.StatusCode))
+		}
+
+		attrs := metric.WithAttributes(
+			AttributeOperation.String(operation),
+			AttributeStatusCode.String(status),
+		)
+
+		inst.duration.Record(ctx, elapsed, attrs)
+
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			inst.errors.Add(ctx, 1, attrs)
+		case resp.StatusCode >= http.StatusBadRequest:
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			inst.errors.Add(ctx, 1, attrs)
+		}
+
+		return resp, err //nolint:wrapcheck // errors are returned as-is to the caller
+	})
+}
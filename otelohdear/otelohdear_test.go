@@ -0,0 +1,87 @@
+package otelohdear_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/otelohdear"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus codes.Code
+	}{
+		{
+			name:       "Successful call",
+			status:     http.StatusOK,
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "Failed call",
+			status:     http.StatusNotFound,
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+
+			mw, err := otelohdear.Middleware(&otelohdear.Options{
+				TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+			})
+			if err != nil {
+				t.Fatalf("Middleware() error = %v", err)
+			}
+
+			stub := func(_ ohdear.Doer) ohdear.Doer {
+				return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: tt.status,
+						Header:     http.Header{},
+						Body:       io.NopCloser(strings.NewReader(`{}`)),
+						Request:    req,
+					}, nil
+				})
+			}
+
+			cfg := ohdear.NewConfig("key", nil)
+			cfg.Middleware = []ohdear.Middleware{mw, stub}
+
+			client, err := ohdear.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			_, _, _ = client.Sites.Get(context.Background(), 1)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+
+			if got := spans[0].Name(); got != "ohdear.Sites.Get" {
+				t.Errorf("span name = %q, want %q", got, "ohdear.Sites.Get")
+			}
+
+			if got := spans[0].Status().Code; got != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package ohdear
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"git.sr.ht/~jamesponddotco/httpx-go"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// ErrAccountNameRequired is returned when a client is added to a
+	// ClientPool without a name.
+	ErrAccountNameRequired xerrors.Error = "account name required"
+
+	// ErrDuplicateAccount is returned when a client is added to a ClientPool
+	// under a name that is already in use.
+	ErrDuplicateAccount xerrors.Error = "account already exists in pool"
+)
+
+// PoolOptions holds the configuration for a ClientPool.
+type PoolOptions struct {
+	// Transport is shared by every client in the pool that doesn't set its
+	// own, so connections to the API are reused across accounts.
+	//
+	// This field is optional. It defaults to a transport tuned for the Oh
+	// Dear API.
+	Transport http.RoundTripper
+
+	// RateLimiter is shared by every client in the pool that doesn't set its
+	// own, so all accounts draw from the same request budget.
+	//
+	// This field is optional. If it's nil, clients that don't set their own
+	// RateLimiter are not rate limited.
+	RateLimiter *rate.Limiter
+}
+
+// ClientPool holds named clients for several Oh Dear accounts, such as one
+// per business unit, and runs operations across all of them.
+//
+// Every client in the pool interprets timestamps in the timezone set once by
+// SetAPILocation, so all accounts must use the same timezone.
+type ClientPool struct {
+	// transport and limiter are shared by the clients in the pool.
+	transport http.RoundTripper
+	limiter   *rate.Limiter
+
+	// clients maps account names to their clients.
+	clients map[string]*Client
+
+	// mu protects clients.
+	mu sync.RWMutex
+}
+
+// NewClientPool returns a new, empty ClientPool.
+func NewClientPool(opts *PoolOptions) *ClientPool {
+	var o PoolOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Transport == nil {
+		o.Transport = httpx.DefaultTransport()
+	}
+
+	return &ClientPool{
+		transport: o.Transport,
+		limiter:   o.RateLimiter,
+		clients:   make(map[string]*Client),
+	}
+}
+
+// Add creates a client for the given account and adds it to the pool. Unless
+// cfg sets its own, the client uses the transport and rate limiter shared by
+// the pool.
+func (p *ClientPool) Add(name string, cfg *Config) (*Client, error) {
+	if name == "" {
+		return nil, ErrAccountNameRequired
+	}
+
+	if cfg == nil {
+		return nil, ErrConfigRequired
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.clients[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateAccount, name)
+	}
+
+	if cfg.Transport == nil {
+		cfg.Transport = p.transport
+	}
+
+	if cfg.RateLimiter == nil {
+		cfg.RateLimiter = p.limiter
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", name, err)
+	}
+
+	p.clients[name] = client
+
+	return client, nil
+}
+
+// Remove removes the client for the given account from the pool.
+func (p *ClientPool) Remove(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.clients, name)
+}
+
+// Client returns the client for the given account.
+func (p *ClientPool) Client(name string) (*Client, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	client, ok := p.clients[name]
+
+	return client, ok
+}
+
+// Names returns the names of the accounts in the pool, sorted
+// alphabetically.
+func (p *ClientPool) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.clients))
+	for name := range p.clients {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ForEach calls fn concurrently for every account in the pool and waits for
+// all calls to return. Errors are wrapped with the name of the account that
+// returned them and joined together.
+func (p *ClientPool) ForEach(ctx context.Context, fn func(ctx context.Context, name string, client *Client) error) error {
+	if ctx == nil {
+		return ErrNilContext
+	}
+
+	p.mu.RLock()
+
+	clients := make(map[string]*Client, len(p.clients))
+	for name, client := range p.clients {
+		clients[name] = client
+	}
+
+	p.mu.RUnlock()
+
+	var (
+		errs []error
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+	)
+
+	for name, client := range clients {
+		wg.Add(1)
+
+		go func(name string, client *Client) {
+			defer wg.Done()
+
+			if err := fn(ctx, name, client); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("account %s: %w", name, err))
+				mu.Unlock()
+			}
+		}(name, client)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// AccountSite is a Site tagged with the name of the account it belongs to.
+type AccountSite struct {
+	// Account is the name of the account in the ClientPool.
+	Account string
+
+	Site
+}
+
+// ListSites returns every site of every account in the pool, sorted by
+// account name and site ID.
+//
+// If some accounts fail, the sites of the remaining accounts are returned
+// along with an error describing the failures.
+func (p *ClientPool) ListSites(ctx context.Context) ([]AccountSite, error) {
+	var (
+		sites []AccountSite
+		mu    sync.Mutex
+	)
+
+	err := p.ForEach(ctx, func(ctx context.Context, name string, client *Client) error {
+		ret, err := client.Sites.ListAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for i := range ret {
+			sites = append(sites, AccountSite{
+				Account: name,
+				Site:    ret[i],
+			})
+		}
+
+		return nil
+	})
+
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Account != sites[j].Account {
+			return sites[i].Account < sites[j].Account
+		}
+
+		return sites[i].ID < sites[j].ID
+	})
+
+	return sites, err
+}
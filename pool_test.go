@@ -0,0 +1,84 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+func TestClientPool_ListSites(t *testing.T) {
+	t.Parallel()
+
+	var (
+		marketing = ohdeartest.NewServer(&ohdeartest.Options{Key: "marketing"})
+		sales     = ohdeartest.NewServer(&ohdeartest.Options{Key: "sales", PerPage: 1})
+		support   = ohdeartest.NewServer(&ohdeartest.Options{Key: "support"})
+	)
+
+	defer marketing.Close()
+	defer sales.Close()
+	defer support.Close()
+
+	marketing.AddSite(&ohdear.Site{URL: "https://marketing.example.com", TeamID: 1})
+	sales.AddSite(&ohdear.Site{URL: "https://sales.example.com", TeamID: 2})
+	sales.AddSite(&ohdear.Site{URL: "https://shop.example.com", TeamID: 2})
+	support.AddSite(&ohdear.Site{URL: "https://support.example.com", TeamID: 3})
+
+	support.Fail(ohdeartest.Failure{
+		Method: http.MethodGet,
+		Path:   "/sites",
+		Status: http.StatusForbidden,
+	})
+
+	pool := ohdear.NewClientPool(nil)
+
+	for name, srv := range map[string]*ohdeartest.Server{
+		"marketing": marketing,
+		"sales":     sales,
+		"support":   support,
+	} {
+		cfg := ohdear.NewConfig(name, nil)
+		cfg.BaseURL = srv.URL
+
+		if _, err := pool.Add(name, cfg); err != nil {
+			t.Fatalf("Add(%q) error = %v", name, err)
+		}
+	}
+
+	if _, err := pool.Add("sales", ohdear.NewConfig("sales", nil)); !errors.Is(err, ohdear.ErrDuplicateAccount) {
+		t.Errorf("Add() of existing account error = %v, want %v", err, ohdear.ErrDuplicateAccount)
+	}
+
+	sites, err := pool.ListSites(context.Background())
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden {
+		t.Errorf("ListSites() error = %v, want a 403 *ohdear.APIError", err)
+	}
+
+	want := []string{
+		"marketing https://marketing.example.com",
+		"sales https://sales.example.com",
+		"sales https://shop.example.com",
+	}
+
+	if len(sites) != len(want) {
+		t.Fatalf("ListSites() returned %d sites, want %d", len(sites), len(want))
+	}
+
+	for i, site := range sites {
+		if got := site.Account + " " + site.URL; got != want[i] {
+			t.Errorf("sites[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+
+	pool.Remove("support")
+
+	if _, err = pool.ListSites(context.Background()); err != nil {
+		t.Errorf("ListSites() after Remove() error = %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// matches checks if a request matches a recorded one. The scheme and host are
+// ignored so golden files keep working against a different base URL, and JSON
+// bodies are compared regardless of formatting.
+func matches(recorded, req *Request) bool {
+	if recorded.Method != req.Method || requestURI(recorded.URL) != requestURI(req.URL) {
+		return false
+	}
+
+	return normalizeBody(recorded.Body) == normalizeBody(req.Body)
+}
+
+// sameEndpoint checks if two URLs point to the same path, ignoring the query.
+func sameEndpoint(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	return ua.Path == ub.Path
+}
+
+// requestURI returns the path and query of a URL.
+func requestURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+
+	return u.RequestURI()
+}
+
+// normalizeBody returns body indented consistently if it's JSON, or as-is
+// otherwise.
+func normalizeBody(body string) string {
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return body
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// diffRequests returns a line diff between a recorded request and the one
+// that failed to match it, with removed lines prefixed by "-" and added ones
+// by "+".
+func diffRequests(recorded, req *Request) string {
+	return diffLines(describe(recorded), describe(req))
+}
+
+// describe returns the lines compared by diffRequests.
+func describe(req *Request) []string {
+	lines := []string{req.Method + " " + requestURI(req.URL)}
+
+	if req.Body != "" {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(normalizeBody(req.Body), "\n")...)
+	}
+
+	return lines
+}
+
+// diffLines returns a line diff between a and b based on their longest
+// common subsequence.
+func diffLines(a, b []string) string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var (
+		out  strings.Builder
+		i, j int
+	)
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,342 @@
+// Package recorder implements an http.RoundTripper that records interactions
+// with the Oh Dear API to golden files and replays them offline.
+//
+// In ModeRecord, requests are sent to the API and every interaction is kept
+// in memory until Save writes them to disk, with the API key and other
+// sensitive headers scrubbed. In ModeReplay, responses are served from the
+// golden file and requests that don't match a recorded interaction fail with
+// a readable diff.
+//
+//	rec, err := recorder.New(&recorder.Options{
+//		Mode: recorder.ModeReplay,
+//		Path: "testdata/sites.json",
+//	})
+//
+//	cfg := ohdear.NewConfig("key", nil)
+//	cfg.Transport = rec
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrPathRequired is returned when a Recorder is created without a path.
+	ErrPathRequired xerrors.Error = "golden file path required"
+
+	// ErrNoMatch is returned in replay mode when a request doesn't match any
+	// recorded interaction.
+	ErrNoMatch xerrors.Error = "no recorded interaction matches the request"
+
+	// ErrNotRecording is returned when Save is called on a Recorder that is
+	// not in record mode.
+	ErrNotRecording xerrors.Error = "recorder is not in record mode"
+)
+
+// Mode defines whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the golden file without touching the
+	// network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests to the API and records the interactions.
+	ModeRecord
+)
+
+// Options holds the configuration for a Recorder.
+type Options struct {
+	// Transport is used to send requests in record mode.
+	//
+	// This field is optional. It defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Scrub is called on every interaction before it's recorded, after
+	// sensitive headers are redacted. Use it to remove other secrets, such as
+	// tokens in response bodies.
+	//
+	// This field is optional.
+	Scrub func(*Interaction)
+
+	// Path is the path of the golden file.
+	Path string
+
+	// Mode defines whether interactions are recorded or replayed.
+	Mode Mode
+}
+
+// Cassette is the content of a golden file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Header http.Header `json:"header,omitempty"`
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	Status int         `json:"status"`
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions.
+type Recorder struct {
+	// opts holds the configuration of the recorder.
+	opts Options
+
+	// cassette holds the interactions recorded or loaded from disk.
+	cassette Cassette
+
+	// used marks the interactions already replayed.
+	used []bool
+
+	// mu protects cassette and used.
+	mu sync.Mutex
+}
+
+// Compile-time check to ensure Recorder implements http.RoundTripper.
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// New returns a new Recorder. In replay mode, the golden file is loaded
+// immediately.
+func New(opts *Options) (*Recorder, error) {
+	if opts == nil || opts.Path == "" {
+		return nil, ErrPathRequired
+	}
+
+	r := &Recorder{
+		opts: *opts,
+	}
+
+	if r.opts.Transport == nil {
+		r.opts.Transport = http.DefaultTransport
+	}
+
+	if r.opts.Mode != ModeReplay {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(r.opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read golden file: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("could not unmarshal golden file: %w", err)
+	}
+
+	r.used = make([]bool, len(r.cassette.Interactions))
+
+	return r, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.opts.Mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	return r.record(req, recorded)
+}
+
+// Save writes the recorded interactions to the golden file.
+func (r *Recorder) Save() error {
+	if r.opts.Mode != ModeRecord {
+		return ErrNotRecording
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(&r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal golden file: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(r.opts.Path), 0o755); err != nil {
+		return fmt.Errorf("could not create golden file directory: %w", err)
+	}
+
+	if err = os.WriteFile(r.opts.Path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("could not write golden file: %w", err)
+	}
+
+	return nil
+}
+
+// Interactions returns the recorded or loaded interactions.
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*Interaction(nil), r.cassette.Interactions...)
+}
+
+// record sends the request and records the interaction.
+func (r *Recorder) record(req *http.Request, recorded *Request) (*http.Response, error) {
+	resp, err := r.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := &Interaction{
+		Request: *recorded,
+		Response: Response{
+			Header: ohdear.RedactHeader(resp.Header),
+			Body:   string(body),
+			Status: resp.StatusCode,
+		},
+	}
+
+	if r.opts.Scrub != nil {
+		r.opts.Scrub(interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves the response of the first unused interaction matching the
+// request.
+func (r *Recorder) replay(req *http.Request, recorded *Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	closest := -1
+
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] && matches(&interaction.Request, recorded) {
+			r.used[i] = true
+
+			return &http.Response{
+				Status:     fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+				StatusCode: interaction.Response.Status,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     interaction.Response.Header.Clone(),
+				Body:       io.NopCloser(strings.NewReader(interaction.Response.Body)),
+				Request:    req,
+			}, nil
+		}
+
+		closest = r.closer(closest, i, recorded)
+	}
+
+	if closest < 0 {
+		return nil, fmt.Errorf("%w: %s %s; the golden file is empty", ErrNoMatch, recorded.Method, recorded.URL)
+	}
+
+	note := "closest recorded interaction differs"
+	if r.used[closest] {
+		note = "closest recorded interaction was already replayed"
+	}
+
+	return nil, fmt.Errorf(
+		"%w: %s %s; %s:\n%s",
+		ErrNoMatch,
+		recorded.Method,
+		recorded.URL,
+		note,
+		diffRequests(&r.cassette.Interactions[closest].Request, recorded),
+	)
+}
+
+// closer returns whichever of the interactions at indexes current and
+// candidate is closer to the request, preferring unused interactions to the
+// same endpoint. A negative current means there is no candidate yet.
+func (r *Recorder) closer(current, candidate int, req *Request) int {
+	if current < 0 {
+		return candidate
+	}
+
+	score := func(i int) int {
+		var (
+			interaction = r.cassette.Interactions[i]
+			total       int
+		)
+
+		if interaction.Request.Method == req.Method && sameEndpoint(interaction.Request.URL, req.URL) {
+			total += 2
+		}
+
+		if !r.used[i] {
+			total++
+		}
+
+		return total
+	}
+
+	if score(candidate) > score(current) {
+		return candidate
+	}
+
+	return current
+}
+
+// newRequest returns the recorded form of req, restoring its body so it can
+// still be sent.
+func newRequest(req *http.Request) (*Request, error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return &Request{
+		Header: ohdear.RedactHeader(req.Header),
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Body:   string(body),
+	}, nil
+}
@@ -0,0 +1,98 @@
+package recorder_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+	"git.sr.ht/~jamesponddotco/ohdear-go/recorder"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "sites.json")
+		srv  = ohdeartest.NewServer(nil)
+	)
+
+	defer srv.Close()
+
+	srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+
+	rec, err := recorder.New(&recorder.Options{
+		Mode: recorder.ModeRecord,
+		Path: path,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cfg := ohdear.NewConfig("", nil)
+	cfg.Transport = rec
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	if _, _, err = client.Sites.Get(ctx, 1); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if err = rec.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read golden file: %v", err)
+	}
+
+	if strings.Contains(string(data), ohdeartest.DefaultKey) {
+		t.Errorf("golden file contains the API key:\n%s", data)
+	}
+
+	// Replay against a closed server to make sure nothing touches the network.
+	srv.Close()
+
+	replay, err := recorder.New(&recorder.Options{
+		Mode: recorder.ModeReplay,
+		Path: path,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cfg = ohdear.NewConfig("", nil)
+	cfg.Transport = replay
+
+	client, err = srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	site, _, err := client.Sites.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("replayed Get() error = %v", err)
+	}
+
+	if site.URL != "https://example.com" {
+		t.Errorf("replayed Get() URL = %q, want %q", site.URL, "https://example.com")
+	}
+
+	_, _, err = client.Sites.Get(ctx, 2)
+	if !errors.Is(err, recorder.ErrNoMatch) {
+		t.Fatalf("Get(2) error = %v, want %v", err, recorder.ErrNoMatch)
+	}
+
+	if !strings.Contains(err.Error(), "- GET /api/sites/1") || !strings.Contains(err.Error(), "+ GET /api/sites/2") {
+		t.Errorf("Get(2) error does not contain a diff:\n%v", err)
+	}
+}
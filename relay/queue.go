@@ -0,0 +1,253 @@
+package relay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrQueueDirRequired is returned when a DiskQueue is created without a
+// directory.
+const ErrQueueDirRequired xerrors.Error = "queue directory required"
+
+// _deliveryExt is the extension of the files holding deliveries in a
+// DiskQueue.
+const _deliveryExt string = ".json"
+
+// Delivery is an event waiting to be delivered to a destination.
+type Delivery struct {
+	// QueuedAt is when the delivery was queued. Deliveries to the same
+	// destination are made in this order.
+	QueuedAt time.Time `json:"queued_at"`
+
+	// NextAttempt is when the delivery should be attempted next.
+	NextAttempt time.Time `json:"next_attempt"`
+
+	// Event is the event to deliver.
+	Event *webhook.Event `json:"event"`
+
+	// LastError is the error of the last failed attempt.
+	LastError string `json:"last_error,omitempty"`
+
+	// Destination is the name of the destination to deliver the event to.
+	Destination string `json:"destination"`
+
+	// Attempts is the number of failed attempts so far.
+	Attempts int `json:"attempts"`
+}
+
+// ID returns the identifier of the delivery, unique for each event and
+// destination.
+func (d *Delivery) ID() string {
+	return d.Event.ID + ":" + d.Destination
+}
+
+// Queue stores deliveries until they succeed or are given up on.
+type Queue interface {
+	// Put stores a delivery, replacing any delivery with the same ID.
+	Put(ctx context.Context, d *Delivery) error
+
+	// List returns every stored delivery, sorted by next attempt.
+	List(ctx context.Context) ([]*Delivery, error)
+
+	// Remove removes the delivery with the given ID. Removing a delivery that
+	// doesn't exist is not an error.
+	Remove(ctx context.Context, id string) error
+}
+
+// Compile-time checks to ensure the queues implement Queue.
+var (
+	_ Queue = (*MemoryQueue)(nil)
+	_ Queue = (*DiskQueue)(nil)
+)
+
+// MemoryQueue is a Queue that keeps deliveries in memory. Pending deliveries
+// are lost on restart.
+type MemoryQueue struct {
+	// deliveries holds the deliveries, keyed by ID.
+	deliveries map[string]*Delivery
+
+	// mu protects deliveries.
+	mu sync.Mutex
+}
+
+// NewMemoryQueue returns a new, empty MemoryQueue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		deliveries: make(map[string]*Delivery),
+	}
+}
+
+// Put implements the Queue interface.
+func (q *MemoryQueue) Put(_ context.Context, d *Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stored := *d
+	q.deliveries[d.ID()] = &stored
+
+	return nil
+}
+
+// List implements the Queue interface.
+func (q *MemoryQueue) List(_ context.Context) ([]*Delivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	deliveries := make([]*Delivery, 0, len(q.deliveries))
+
+	for _, d := range q.deliveries {
+		stored := *d
+		deliveries = append(deliveries, &stored)
+	}
+
+	sortDeliveries(deliveries)
+
+	return deliveries, nil
+}
+
+// Remove implements the Queue interface.
+func (q *MemoryQueue) Remove(_ context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.deliveries, id)
+
+	return nil
+}
+
+// DiskQueue is a Queue that stores each delivery as a JSON file in a
+// directory, so pending deliveries survive restarts.
+type DiskQueue struct {
+	// dir is the directory where deliveries are stored.
+	dir string
+}
+
+// NewDiskQueue returns a new DiskQueue storing deliveries in dir, creating the
+// directory if it doesn't exist.
+func NewDiskQueue(dir string) (*DiskQueue, error) {
+	if dir == "" {
+		return nil, ErrQueueDirRequired
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create queue directory: %w", err)
+	}
+
+	return &DiskQueue{
+		dir: dir,
+	}, nil
+}
+
+// Put implements the Queue interface.
+func (q *DiskQueue) Put(_ context.Context, d *Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("could not marshal delivery: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial
+	// delivery behind.
+	tmp, err := os.CreateTemp(q.dir, ".delivery-*")
+	if err != nil {
+		return fmt.Errorf("could not create delivery: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("could not write delivery: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("could not write delivery: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), q.path(d.ID())); err != nil {
+		return fmt.Errorf("could not store delivery: %w", err)
+	}
+
+	return nil
+}
+
+// List implements the Queue interface.
+func (q *DiskQueue) List(_ context.Context) ([]*Delivery, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read queue directory: %w", err)
+	}
+
+	deliveries := make([]*Delivery, 0, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != _deliveryExt {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(q.dir, name))
+		if err != nil {
+			// The delivery may have been removed since the directory was
+			// read.
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("could not read delivery: %w", err)
+		}
+
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("could not unmarshal delivery %s: %w", name, err)
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	sortDeliveries(deliveries)
+
+	return deliveries, nil
+}
+
+// Remove implements the Queue interface.
+func (q *DiskQueue) Remove(_ context.Context, id string) error {
+	if err := os.Remove(q.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove delivery: %w", err)
+	}
+
+	return nil
+}
+
+// path returns the path of the file holding the delivery with the given ID.
+func (q *DiskQueue) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return filepath.Join(q.dir, hex.EncodeToString(sum[:])+_deliveryExt)
+}
+
+// sortDeliveries sorts deliveries by next attempt, then by ID.
+func sortDeliveries(deliveries []*Delivery) {
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttempt.Equal(deliveries[j].NextAttempt) {
+			return deliveries[i].NextAttempt.Before(deliveries[j].NextAttempt)
+		}
+
+		return deliveries[i].ID() < deliveries[j].ID()
+	})
+}
@@ -0,0 +1,587 @@
+// Package relay forwards Oh Dear webhooks to multiple destinations, such as
+// generic webhooks, Slack, Microsoft Teams or files, routing them by the tags
+// of the site and the type of check.
+//
+// Events are stored in a Queue before they're delivered, so a destination
+// being down doesn't lose them, and every destination retries failed
+// deliveries according to its own policy. Webhooks retried by Oh Dear are
+// only delivered once within a deduplication window.
+//
+//	r, err := relay.New(&relay.Options{
+//		Destinations: []relay.Destination{
+//			{Name: "ops", Sink: &relay.SlackSink{URL: slackURL}},
+//			{Name: "audit", Sink: relay.NewWriterSink(os.Stdout)},
+//		},
+//		Rules: []relay.Rule{
+//			{Destinations: []string{"ops"}, Tags: []string{"production"}},
+//			{Destinations: []string{"audit"}},
+//		},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	go r.Run(ctx)
+//
+//	http.Handle("/webhook", r.Handler(secret))
+package relay
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrNoDestinations is returned when a Relay is created without
+	// destinations.
+	ErrNoDestinations xerrors.Error = "at least one destination is required"
+
+	// ErrDestinationNameRequired is returned when a destination has no name.
+	ErrDestinationNameRequired xerrors.Error = "destination name cannot be empty"
+
+	// ErrDuplicateDestination is returned when two destinations share a name.
+	ErrDuplicateDestination xerrors.Error = "duplicate destination name"
+
+	// ErrSinkRequired is returned when a destination has no sink.
+	ErrSinkRequired xerrors.Error = "destination sink cannot be nil"
+
+	// ErrUnknownDestination is returned when a rule refers to a destination
+	// that doesn't exist.
+	ErrUnknownDestination xerrors.Error = "unknown destination"
+)
+
+// Default values for the Options and RetryPolicy structs.
+const (
+	DefaultDedupWindow   time.Duration = 10 * time.Minute
+	DefaultMaxAttempts   int           = 5
+	DefaultMinRetryDelay time.Duration = 5 * time.Second
+	DefaultMaxRetryDelay time.Duration = 10 * time.Minute
+)
+
+// _idleWait is how long Run waits for new events when the queue is empty or
+// cannot be read.
+const _idleWait time.Duration = time.Minute
+
+// RetryPolicy defines how often and how fast failed deliveries to a
+// destination are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts made before a delivery is given
+	// up on.
+	//
+	// This field is optional. It defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// MinDelay is the delay before the first retry, which doubles with every
+	// retry after it.
+	//
+	// This field is optional. It defaults to DefaultMinRetryDelay.
+	MinDelay time.Duration
+
+	// MaxDelay is the maximum delay between retries.
+	//
+	// This field is optional. It defaults to DefaultMaxRetryDelay.
+	MaxDelay time.Duration
+}
+
+// maxAttempts returns the number of attempts allowed by the policy.
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return DefaultMaxAttempts
+	}
+
+	return p.MaxAttempts
+}
+
+// delay returns how long to wait after the given number of failed attempts.
+func (p *RetryPolicy) delay(attempts int) time.Duration {
+	var (
+		minDelay = DefaultMinRetryDelay
+		maxDelay = DefaultMaxRetryDelay
+	)
+
+	if p != nil && p.MinDelay > 0 {
+		minDelay = p.MinDelay
+	}
+
+	if p != nil && p.MaxDelay > 0 {
+		maxDelay = p.MaxDelay
+	}
+
+	return min(minDelay<<min(max(attempts-1, 0), 16), maxDelay)
+}
+
+// Destination is a named sink events can be routed to.
+type Destination struct {
+	// Retry is the retry policy for failed deliveries.
+	//
+	// This field is optional. The defaults of RetryPolicy are used if it's
+	// nil.
+	Retry *RetryPolicy
+
+	// Sink delivers the events.
+	Sink Sink
+
+	// Name identifies the destination in rules.
+	Name string
+}
+
+// Rule routes the events matching all of its conditions to destinations.
+// Empty conditions match every event.
+type Rule struct {
+	// Destinations are the names of the destinations matching events are
+	// sent to.
+	Destinations []string
+
+	// Tags matches events about sites with any of the tags.
+	Tags []string
+
+	// CheckTypes matches events about any of the check types.
+	CheckTypes []ohdear.CheckType
+
+	// Results matches events implying any of the check results.
+	Results []ohdear.CheckResult
+}
+
+// Matches returns true if the event matches every condition of the rule.
+func (r *Rule) Matches(event *webhook.Event) bool {
+	if len(r.Tags) > 0 && !anyTag(event.Site, r.Tags) {
+		return false
+	}
+
+	if len(r.CheckTypes) > 0 && !contains(r.CheckTypes, event.CheckType) {
+		return false
+	}
+
+	if len(r.Results) > 0 && !contains(r.Results, event.Result) {
+		return false
+	}
+
+	return true
+}
+
+// Options holds the configuration for a Relay.
+type Options struct {
+	// Queue stores deliveries until they succeed.
+	//
+	// This field is optional. It defaults to a MemoryQueue. Use a DiskQueue
+	// to keep pending deliveries across restarts.
+	Queue Queue
+
+	// Logger logs deliveries and failures.
+	//
+	// This field is optional. It defaults to slog.Default.
+	Logger *slog.Logger
+
+	// Destinations are the destinations events can be sent to.
+	Destinations []Destination
+
+	// Rules route events to destinations. Events are sent to the
+	// destinations of every rule they match.
+	//
+	// This field is optional. Every event is sent to every destination if
+	// it's empty.
+	Rules []Rule
+
+	// DedupWindow is how long the ID of an event is remembered so that
+	// deliveries of the same webhook retried by Oh Dear are ignored. A
+	// negative value disables deduplication.
+	//
+	// This field is optional. It defaults to DefaultDedupWindow.
+	DedupWindow time.Duration
+}
+
+// Relay routes Oh Dear webhooks to destinations. It's safe for concurrent
+// use.
+type Relay struct {
+	queue        Queue
+	logger       *slog.Logger
+	destinations map[string]*Destination
+	seen         map[string]time.Time
+	wake         map[string]chan struct{}
+	order        []string
+	rules        []Rule
+	dedupWindow  time.Duration
+
+	// mu protects seen.
+	mu sync.Mutex
+}
+
+// New returns a new Relay with the given options.
+func New(opts *Options) (*Relay, error) {
+	if opts == nil || len(opts.Destinations) == 0 {
+		return nil, ErrNoDestinations
+	}
+
+	r := &Relay{
+		queue:        opts.Queue,
+		logger:       opts.Logger,
+		destinations: make(map[string]*Destination, len(opts.Destinations)),
+		seen:         make(map[string]time.Time),
+		wake:         make(map[string]chan struct{}, len(opts.Destinations)),
+		order:        make([]string, 0, len(opts.Destinations)),
+		rules:        append([]Rule(nil), opts.Rules...),
+		dedupWindow:  opts.DedupWindow,
+	}
+
+	if r.queue == nil {
+		r.queue = NewMemoryQueue()
+	}
+
+	if r.logger == nil {
+		r.logger = slog.Default()
+	}
+
+	if r.dedupWindow == 0 {
+		r.dedupWindow = DefaultDedupWindow
+	}
+
+	for i := range opts.Destinations {
+		dest := opts.Destinations[i]
+
+		switch {
+		case dest.Name == "":
+			return nil, ErrDestinationNameRequired
+		case dest.Sink == nil:
+			return nil, fmt.Errorf("%w: %s", ErrSinkRequired, dest.Name)
+		}
+
+		if _, ok := r.destinations[dest.Name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateDestination, dest.Name)
+		}
+
+		r.destinations[dest.Name] = &dest
+		r.wake[dest.Name] = make(chan struct{}, 1)
+		r.order = append(r.order, dest.Name)
+	}
+
+	for _, rule := range r.rules {
+		for _, name := range rule.Destinations {
+			if _, ok := r.destinations[name]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownDestination, name)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// Handler returns an http.Handler that verifies the webhooks sent by Oh Dear
+// with the given secret and passes them to Handle.
+func (r *Relay) Handler(secret string) http.Handler {
+	return webhook.Handler(secret, r.Handle)
+}
+
+// Handle queues an event for delivery to the destinations it's routed to.
+// Events already handled within the deduplication window are ignored.
+func (r *Relay) Handle(ctx context.Context, event *webhook.Event) error {
+	now := time.Now()
+
+	if r.duplicate(event.ID, now) {
+		r.logger.DebugContext(ctx, "ignoring duplicate event", slog.String("event", event.ID))
+
+		return nil
+	}
+
+	names := r.Route(event)
+
+	for i, name := range names {
+		d := &Delivery{
+			QueuedAt:    now,
+			NextAttempt: now,
+			Event:       event,
+			Destination: name,
+		}
+
+		if err := r.queue.Put(ctx, d); err != nil {
+			// Remove the deliveries already queued and forget the event, so
+			// the webhook retried by Oh Dear is queued once for every
+			// destination.
+			r.unqueue(ctx, event, names[:i])
+			r.forget(event.ID)
+
+			return fmt.Errorf("could not queue delivery: %w", err)
+		}
+	}
+
+	for _, name := range names {
+		select {
+		case r.wake[name] <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// unqueue removes the deliveries of event to the given destinations from the
+// queue. Failures are logged, as the event may then be delivered twice.
+func (r *Relay) unqueue(ctx context.Context, event *webhook.Event, names []string) {
+	for _, name := range names {
+		d := &Delivery{
+			Event:       event,
+			Destination: name,
+		}
+
+		if err := r.queue.Remove(ctx, d.ID()); err != nil {
+			r.logger.ErrorContext(ctx, "could not remove delivery", slog.String("delivery", d.ID()), slog.Any("error", err))
+		}
+	}
+}
+
+// Route returns the names of the destinations an event is sent to, in the
+// order the destinations were given.
+func (r *Relay) Route(event *webhook.Event) []string {
+	if len(r.rules) == 0 {
+		return append([]string(nil), r.order...)
+	}
+
+	matched := make(map[string]bool)
+
+	for i := range r.rules {
+		if !r.rules[i].Matches(event) {
+			continue
+		}
+
+		for _, name := range r.rules[i].Destinations {
+			matched[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(matched))
+
+	for _, name := range r.order {
+		if matched[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Run delivers queued events until ctx is done. Every destination has its
+// own worker delivering its events one at a time in the order they were
+// queued, so a slow destination doesn't hold back the others, and an event
+// being retried holds back the later events for the same destination.
+func (r *Relay) Run(ctx context.Context) error {
+	r.dropUnknown(ctx)
+
+	var wg sync.WaitGroup
+
+	for _, name := range r.order {
+		wg.Add(1)
+
+		go func(name string) {
+			defer wg.Done()
+
+			r.work(ctx, name)
+		}(name)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// work delivers the events queued for a destination until ctx is done.
+func (r *Relay) work(ctx context.Context, name string) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		wait := r.deliverDue(ctx, name)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake[name]:
+		case <-timer.C:
+		}
+	}
+}
+
+// deliverDue delivers the deliveries to a destination in order, until one is
+// not due yet or fails, and returns how long to wait until the next one is
+// due.
+func (r *Relay) deliverDue(ctx context.Context, name string) time.Duration {
+	deliveries, err := r.queue.List(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not list deliveries", slog.String("destination", name), slog.Any("error", err))
+
+		return _idleWait
+	}
+
+	pending := make([]*Delivery, 0, len(deliveries))
+
+	for _, d := range deliveries {
+		if d.Destination == name {
+			pending = append(pending, d)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].QueuedAt.Before(pending[j].QueuedAt)
+	})
+
+	for _, d := range pending {
+		if wait := time.Until(d.NextAttempt); wait > 0 {
+			return wait
+		}
+
+		if !r.deliver(ctx, d) {
+			return max(time.Until(d.NextAttempt), 0)
+		}
+	}
+
+	return _idleWait
+}
+
+// dropUnknown removes the deliveries to destinations that were removed from
+// the configuration since they were queued.
+func (r *Relay) dropUnknown(ctx context.Context) {
+	deliveries, err := r.queue.List(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not list deliveries", slog.Any("error", err))
+
+		return
+	}
+
+	for _, d := range deliveries {
+		if _, ok := r.destinations[d.Destination]; ok {
+			continue
+		}
+
+		logger := r.logger.With(
+			slog.String("event", d.Event.ID),
+			slog.String("destination", d.Destination),
+		)
+
+		logger.WarnContext(ctx, "dropping delivery to unknown destination")
+		r.remove(ctx, logger, d)
+	}
+}
+
+// deliver attempts a single delivery, removing it from the queue if it
+// succeeds or is given up on, and rescheduling it otherwise. It returns true
+// if the delivery was removed from the queue.
+func (r *Relay) deliver(ctx context.Context, d *Delivery) bool {
+	logger := r.logger.With(
+		slog.String("event", d.Event.ID),
+		slog.String("type", d.Event.Type),
+		slog.String("destination", d.Destination),
+	)
+
+	dest := r.destinations[d.Destination]
+
+	err := dest.Sink.Send(ctx, d.Event)
+	if ctx.Err() != nil {
+		// Shutting down; the delivery stays queued as it was.
+		return false
+	}
+
+	if err == nil {
+		logger.DebugContext(ctx, "delivered event", slog.Int("attempt", d.Attempts+1))
+		r.remove(ctx, logger, d)
+
+		return true
+	}
+
+	d.Attempts++
+	d.LastError = err.Error()
+
+	if d.Attempts >= dest.Retry.maxAttempts() {
+		logger.ErrorContext(ctx, "giving up on delivery", slog.Int("attempts", d.Attempts), slog.Any("error", err))
+		r.remove(ctx, logger, d)
+
+		return true
+	}
+
+	d.NextAttempt = time.Now().Add(dest.Retry.delay(d.Attempts))
+
+	logger.WarnContext(ctx, "delivery failed", slog.Int("attempt", d.Attempts), slog.Time("next_attempt", d.NextAttempt), slog.Any("error", err))
+
+	if err := r.queue.Put(ctx, d); err != nil {
+		logger.ErrorContext(ctx, "could not reschedule delivery", slog.Any("error", err))
+	}
+
+	return false
+}
+
+// remove removes a delivery from the queue, logging any error.
+func (r *Relay) remove(ctx context.Context, logger *slog.Logger, d *Delivery) {
+	if err := r.queue.Remove(ctx, d.ID()); err != nil {
+		logger.ErrorContext(ctx, "could not remove delivery", slog.Any("error", err))
+	}
+}
+
+// duplicate returns true if the event with the given ID was seen within the
+// deduplication window, and records it as seen otherwise.
+func (r *Relay) duplicate(id string, now time.Time) bool {
+	if r.dedupWindow < 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for seenID, at := range r.seen {
+		if now.Sub(at) >= r.dedupWindow {
+			delete(r.seen, seenID)
+		}
+	}
+
+	if _, ok := r.seen[id]; ok {
+		return true
+	}
+
+	r.seen[id] = now
+
+	return false
+}
+
+// forget removes the event with the given ID from the seen events.
+func (r *Relay) forget(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.seen, id)
+}
+
+// anyTag returns true if the site has any of the tags.
+func anyTag(site webhook.Site, tags []string) bool {
+	for _, tag := range tags {
+		if site.HasTag(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains returns true if v is in values.
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,560 @@
+package relay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/relay"
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+)
+
+// _discard is a logger that discards every record.
+var _discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fastRetries is a retry policy that doesn't slow tests down.
+var fastRetries = &relay.RetryPolicy{
+	MaxAttempts: 3,
+	MinDelay:    time.Millisecond,
+	MaxDelay:    time.Millisecond,
+}
+
+// testEvent returns an event about the uptime check of a site with the given
+// tags.
+func testEvent(id string, result ohdear.CheckResult, tags ...string) *webhook.Event {
+	return &webhook.Event{
+		ID:        id,
+		Type:      "uptimeCheckFailedNotification",
+		CheckType: ohdear.CheckTypeUptime,
+		Result:    result,
+		Summary:   "Example: Uptime check failed",
+		Site: webhook.Site{
+			URL:  "https://example.com",
+			Tags: tags,
+			ID:   1,
+		},
+	}
+}
+
+// recordingSink is a Sink that records the events it receives and fails the
+// first failures deliveries.
+type recordingSink struct {
+	events   []*webhook.Event
+	failures int
+	mu       sync.Mutex
+}
+
+func (s *recordingSink) Send(_ context.Context, event *webhook.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+
+		return errors.New("sink unavailable")
+	}
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func (s *recordingSink) received() []*webhook.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*webhook.Event(nil), s.events...)
+}
+
+// waitFor polls cond until it's true, failing the test if it takes too long.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	sink := relay.NewWriterSink(io.Discard)
+
+	tests := []struct {
+		name    string
+		opts    *relay.Options
+		wantErr error
+	}{
+		{
+			name:    "No destinations",
+			opts:    &relay.Options{},
+			wantErr: relay.ErrNoDestinations,
+		},
+		{
+			name: "Missing name",
+			opts: &relay.Options{
+				Destinations: []relay.Destination{{Sink: sink}},
+			},
+			wantErr: relay.ErrDestinationNameRequired,
+		},
+		{
+			name: "Missing sink",
+			opts: &relay.Options{
+				Destinations: []relay.Destination{{Name: "a"}},
+			},
+			wantErr: relay.ErrSinkRequired,
+		},
+		{
+			name: "Duplicate destination",
+			opts: &relay.Options{
+				Destinations: []relay.Destination{{Name: "a", Sink: sink}, {Name: "a", Sink: sink}},
+			},
+			wantErr: relay.ErrDuplicateDestination,
+		},
+		{
+			name: "Unknown destination in rule",
+			opts: &relay.Options{
+				Destinations: []relay.Destination{{Name: "a", Sink: sink}},
+				Rules:        []relay.Rule{{Destinations: []string{"b"}}},
+			},
+			wantErr: relay.ErrUnknownDestination,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := relay.New(tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRelay_Route(t *testing.T) {
+	t.Parallel()
+
+	sink := relay.NewWriterSink(io.Discard)
+
+	r, err := relay.New(&relay.Options{
+		Destinations: []relay.Destination{
+			{Name: "ops", Sink: sink},
+			{Name: "oncall", Sink: sink},
+			{Name: "audit", Sink: sink},
+		},
+		Rules: []relay.Rule{
+			{Destinations: []string{"ops"}, Tags: []string{"production"}},
+			{
+				Destinations: []string{"oncall"},
+				Tags:         []string{"production"},
+				CheckTypes:   []ohdear.CheckType{ohdear.CheckTypeUptime},
+				Results:      []ohdear.CheckResult{ohdear.CheckResultFailed},
+			},
+			{Destinations: []string{"audit"}},
+		},
+		Logger: _discard,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		event *webhook.Event
+		want  []string
+	}{
+		{
+			name:  "Production failure",
+			event: testEvent("1", ohdear.CheckResultFailed, "Production"),
+			want:  []string{"ops", "oncall", "audit"},
+		},
+		{
+			name:  "Production recovery",
+			event: testEvent("2", ohdear.CheckResultSucceeded, "production"),
+			want:  []string{"ops", "audit"},
+		},
+		{
+			name:  "Staging failure",
+			event: testEvent("3", ohdear.CheckResultFailed, "staging"),
+			want:  []string{"audit"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := r.Route(tt.event); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelay_Run(t *testing.T) {
+	t.Parallel()
+
+	var (
+		flaky    = &recordingSink{failures: 2}
+		broken   = &recordingSink{failures: 100}
+		ctx, end = context.WithCancel(context.Background())
+		queue    = relay.NewMemoryQueue()
+	)
+
+	defer end()
+
+	r, err := relay.New(&relay.Options{
+		Destinations: []relay.Destination{
+			{Name: "flaky", Sink: flaky, Retry: fastRetries},
+			{Name: "broken", Sink: broken, Retry: fastRetries},
+		},
+		Queue:  queue,
+		Logger: _discard,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	done := make(chan error)
+
+	go func() {
+		done <- r.Run(ctx)
+	}()
+
+	event := testEvent("1", ohdear.CheckResultFailed)
+
+	// The second event is a webhook retried by Oh Dear, so it's ignored.
+	for i := 0; i < 2; i++ {
+		if err := r.Handle(ctx, event); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	waitFor(t, func() bool {
+		deliveries, _ := queue.List(ctx)
+
+		return len(flaky.received()) == 1 && len(deliveries) == 0
+	})
+
+	if got := flaky.received(); got[0].ID != event.ID {
+		t.Errorf("flaky sink received %q, want %q", got[0].ID, event.ID)
+	}
+
+	if got := broken.received(); len(got) != 0 {
+		t.Errorf("broken sink received %d events, want 0", len(got))
+	}
+
+	end()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+// failingQueue is a MemoryQueue that fails to store deliveries to the
+// destination named in fail.
+type failingQueue struct {
+	*relay.MemoryQueue
+
+	fail string
+}
+
+func (q *failingQueue) Put(ctx context.Context, d *relay.Delivery) error {
+	if d.Destination == q.fail {
+		return errors.New("disk full")
+	}
+
+	return q.MemoryQueue.Put(ctx, d)
+}
+
+func TestRelay_Handle_QueueError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = context.Background()
+		queue = &failingQueue{MemoryQueue: relay.NewMemoryQueue(), fail: "second"}
+	)
+
+	r, err := relay.New(&relay.Options{
+		Destinations: []relay.Destination{
+			{Name: "first", Sink: &recordingSink{}},
+			{Name: "second", Sink: &recordingSink{}},
+		},
+		Queue:  queue,
+		Logger: _discard,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	event := testEvent("1", ohdear.CheckResultFailed)
+
+	if err = r.Handle(ctx, event); err == nil {
+		t.Fatal("Handle() error = nil, want an error")
+	}
+
+	if deliveries, _ := queue.List(ctx); len(deliveries) != 0 {
+		t.Errorf("got %d queued deliveries after a failed Handle(), want 0", len(deliveries))
+	}
+
+	// The webhook retried by Oh Dear is queued for every destination.
+	queue.fail = ""
+
+	if err = r.Handle(ctx, event); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	if deliveries, _ := queue.List(ctx); len(deliveries) != 2 {
+		t.Errorf("got %d queued deliveries, want 2", len(deliveries))
+	}
+}
+
+// blockingSink is a Sink that blocks until the delivery is canceled.
+type blockingSink struct{}
+
+func (blockingSink) Send(ctx context.Context, _ *webhook.Event) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestRelay_Run_Order(t *testing.T) {
+	t.Parallel()
+
+	var (
+		flaky    = &recordingSink{failures: 1}
+		ctx, end = context.WithCancel(context.Background())
+	)
+
+	defer end()
+
+	r, err := relay.New(&relay.Options{
+		Destinations: []relay.Destination{
+			{Name: "stuck", Sink: blockingSink{}},
+			{Name: "flaky", Sink: flaky, Retry: fastRetries},
+		},
+		Logger: _discard,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	want := []string{"1", "2", "3"}
+
+	// The events are queued before Run starts, so the first attempt to
+	// deliver the first event fails while the others are already due.
+	for _, id := range want {
+		if err = r.Handle(ctx, testEvent(id, ohdear.CheckResultFailed)); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	done := make(chan error)
+
+	go func() {
+		done <- r.Run(ctx)
+	}()
+
+	// The stuck destination never returns, but doesn't hold back the others.
+	waitFor(t, func() bool {
+		return len(flaky.received()) == len(want)
+	})
+
+	var got []string
+
+	for _, event := range flaky.received() {
+		got = append(got, event.ID)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flaky sink received %v, want %v", got, want)
+	}
+
+	end()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func TestSinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		sink     func(url string) relay.Sink
+		wantBody string
+	}{
+		{
+			name: "HTTP",
+			sink: func(url string) relay.Sink {
+				return &relay.HTTPSink{URL: url, Header: http.Header{"Authorization": {"Bearer token"}}}
+			},
+			wantBody: `"check_type":"uptime"`,
+		},
+		{
+			name: "Slack",
+			sink: func(url string) relay.Sink {
+				return &relay.SlackSink{URL: url}
+			},
+			wantBody: `{"text":":rotating_light: Example: Uptime check failed\n<https://example.com>"}`,
+		},
+		{
+			name: "Teams",
+			sink: func(url string) relay.Sink {
+				return &relay.TeamsSink{URL: url}
+			},
+			wantBody: `"@type":"MessageCard"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				body   []byte
+				header http.Header
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				header = r.Header
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			event := testEvent("1", ohdear.CheckResultFailed)
+
+			if err := tt.sink(srv.URL).Send(context.Background(), event); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", body, tt.wantBody)
+			}
+
+			if header.Get("Content-Type") != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestSinks_Status(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	sink := &relay.HTTPSink{URL: srv.URL}
+
+	if err := sink.Send(context.Background(), testEvent("1", "")); !errors.Is(err, relay.ErrUnexpectedStatus) {
+		t.Errorf("Send() error = %v, want %v", err, relay.ErrUnexpectedStatus)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	sink := relay.NewWriterSink(&buf)
+
+	for _, id := range []string{"1", "2"} {
+		if err := sink.Send(context.Background(), testEvent(id, ohdear.CheckResultFailed)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d lines, want 2", len(lines))
+	}
+
+	var event webhook.Event
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil || event.ID != "2" {
+		t.Errorf("second line = %s, want event 2 (error: %v)", lines[1], err)
+	}
+}
+
+func TestDiskQueue(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		dir = t.TempDir()
+	)
+
+	queue, err := relay.NewDiskQueue(dir)
+	if err != nil {
+		t.Fatalf("NewDiskQueue() error = %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	deliveries := []*relay.Delivery{
+		{Event: testEvent("1", ohdear.CheckResultFailed), Destination: "ops", NextAttempt: now.Add(time.Minute)},
+		{Event: testEvent("2", ohdear.CheckResultFailed), Destination: "ops", NextAttempt: now},
+	}
+
+	for _, d := range deliveries {
+		if err = queue.Put(ctx, d); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	// Deliveries survive the queue being reopened, as after a restart.
+	reopened, err := relay.NewDiskQueue(dir)
+	if err != nil {
+		t.Fatalf("NewDiskQueue() error = %v", err)
+	}
+
+	got, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0].Event.ID != "2" || got[1].Event.ID != "1" {
+		t.Fatalf("List() = %d deliveries, want deliveries 2 and 1 in order", len(got))
+	}
+
+	if err = reopened.Remove(ctx, got[0].ID()); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	if got, _ = queue.List(ctx); len(got) != 1 || got[0].Event.ID != "1" {
+		t.Errorf("List() after Remove() = %d deliveries, want delivery 1", len(got))
+	}
+
+	if _, err = relay.NewDiskQueue(""); !errors.Is(err, relay.ErrQueueDirRequired) {
+		t.Errorf("NewDiskQueue(\"\") error = %v, want %v", err, relay.ErrQueueDirRequired)
+	}
+}
@@ -0,0 +1,241 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+// ErrUnexpectedStatus is returned when a sink responds with an unsuccessful
+// status code.
+const ErrUnexpectedStatus xerrors.Error = "unexpected status code"
+
+// DefaultSinkTimeout is the time limit for delivering an event to a sink over
+// HTTP when no client is given.
+const DefaultSinkTimeout time.Duration = 30 * time.Second
+
+// Sink delivers events to a destination.
+type Sink interface {
+	// Send delivers an event. Errors are retried according to the retry
+	// policy of the destination.
+	Send(ctx context.Context, event *webhook.Event) error
+}
+
+// Compile-time checks to ensure the sinks implement Sink.
+var (
+	_ Sink = (*HTTPSink)(nil)
+	_ Sink = (*SlackSink)(nil)
+	_ Sink = (*TeamsSink)(nil)
+	_ Sink = (*WriterSink)(nil)
+)
+
+// HTTPSink posts events as JSON to a generic webhook.
+type HTTPSink struct {
+	// Header holds additional headers sent with every request, such as
+	// Authorization.
+	//
+	// This field is optional.
+	Header http.Header
+
+	// Client is the HTTP client used to send requests.
+	//
+	// This field is optional. It defaults to a client with a timeout of
+	// DefaultSinkTimeout.
+	Client *http.Client
+
+	// URL is the URL events are posted to.
+	URL string
+}
+
+// Send implements the Sink interface.
+func (s *HTTPSink) Send(ctx context.Context, event *webhook.Event) error {
+	return postJSON(ctx, s.Client, s.URL, s.Header, event)
+}
+
+// SlackSink posts events to Slack-compatible incoming webhooks, which are
+// also accepted by Mattermost and Rocket.Chat.
+type SlackSink struct {
+	// Client is the HTTP client used to send requests.
+	//
+	// This field is optional. It defaults to a client with a timeout of
+	// DefaultSinkTimeout.
+	Client *http.Client
+
+	// URL is the URL of the incoming webhook.
+	URL string
+}
+
+// Send implements the Sink interface.
+func (s *SlackSink) Send(ctx context.Context, event *webhook.Event) error {
+	text := emoji(event.Result) + " " + event.Summary
+	if event.Site.URL != "" {
+		text += "\n<" + event.Site.URL + ">"
+	}
+
+	payload := map[string]string{
+		"text": text,
+	}
+
+	return postJSON(ctx, s.Client, s.URL, nil, payload)
+}
+
+// TeamsSink posts events to Microsoft Teams incoming webhooks as message
+// cards.
+type TeamsSink struct {
+	// Client is the HTTP client used to send requests.
+	//
+	// This field is optional. It defaults to a client with a timeout of
+	// DefaultSinkTimeout.
+	Client *http.Client
+
+	// URL is the URL of the incoming webhook.
+	URL string
+}
+
+// teamsFact is a name and value pair shown in a Teams message card.
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Send implements the Sink interface.
+func (s *TeamsSink) Send(ctx context.Context, event *webhook.Event) error {
+	facts := []teamsFact{
+		{Name: "Site", Value: event.Site.URL},
+	}
+
+	if event.CheckType != "" {
+		facts = append(facts, teamsFact{Name: "Check", Value: string(event.CheckType)})
+	}
+
+	if event.Result != "" {
+		facts = append(facts, teamsFact{Name: "Result", Value: string(event.Result)})
+	}
+
+	card := map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    event.Summary,
+		"title":      event.Summary,
+		"themeColor": color(event.Result),
+		"sections": []map[string]any{
+			{"facts": facts},
+		},
+	}
+
+	return postJSON(ctx, s.Client, s.URL, nil, card)
+}
+
+// WriterSink writes events as JSON lines to an io.Writer, such as a file or
+// os.Stdout. It's safe for concurrent use.
+type WriterSink struct {
+	// w is where events are written.
+	w io.Writer
+
+	// mu serializes writes.
+	mu sync.Mutex
+}
+
+// NewWriterSink returns a new WriterSink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		w: w,
+	}
+}
+
+// Send implements the Sink interface.
+func (s *WriterSink) Send(_ context.Context, event *webhook.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+
+	return nil
+}
+
+// postJSON posts v as JSON to uri, returning an error if the response status
+// is not successful.
+func postJSON(ctx context.Context, client *http.Client, uri string, header http.Header, v any) error {
+	var body bytes.Buffer
+
+	// Slack uses angle brackets for links, so they must not be escaped.
+	enc := json.NewEncoder(&body)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("could not marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, &body)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: DefaultSinkTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// emoji returns the Slack emoji code representing a check result.
+func emoji(result ohdear.CheckResult) string {
+	switch result {
+	case ohdear.CheckResultSucceeded:
+		return ":white_check_mark:"
+	case ohdear.CheckResultWarning:
+		return ":warning:"
+	case ohdear.CheckResultFailed, ohdear.CheckResultErroredOrTimedOut:
+		return ":rotating_light:"
+	default:
+		return ":information_source:"
+	}
+}
+
+// color returns the hex color representing a check result in Teams cards.
+func color(result ohdear.CheckResult) string {
+	switch result {
+	case ohdear.CheckResultSucceeded:
+		return "2EB67D"
+	case ohdear.CheckResultWarning:
+		return "ECB22E"
+	case ohdear.CheckResultFailed, ohdear.CheckResultErroredOrTimedOut:
+		return "E01E5A"
+	default:
+		return "808080"
+	}
+}
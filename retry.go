@@ -0,0 +1,129 @@
+package ohdear
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default values for the RetryPolicy struct.
+const (
+	DefaultMinRetryDelay time.Duration = 1 * time.Second
+	DefaultMaxRetryDelay time.Duration = 30 * time.Second
+)
+
+// _jitterFraction is the fraction of the retry delay randomly added or
+// removed to keep clients from retrying in lockstep.
+const _jitterFraction float64 = 0.25
+
+// RetryPolicy defines when and how often requests are retried.
+//
+// Requests using idempotent methods, such as GET, PUT and DELETE, are retried
+// on any of the retryable status codes. Other requests, such as POST, are only
+// retried on 429 Too Many Requests, as the API didn't process them, unless
+// the call is marked with WithIdempotent.
+type RetryPolicy struct {
+	// StatusCodes are the status codes that make a request be retried.
+	//
+	// This field is optional. It defaults to DefaultRetryableStatusCodes.
+	StatusCodes []int
+
+	// MaxRetries is the maximum number of times a request is retried. Zero
+	// disables retries.
+	MaxRetries int
+
+	// MinDelay is the delay before the first retry, which doubles with every
+	// retry after it. A Retry-After header sent by the API takes precedence.
+	//
+	// This field is optional. It defaults to DefaultMinRetryDelay.
+	MinDelay time.Duration
+
+	// MaxDelay is the maximum delay between retries.
+	//
+	// This field is optional. It defaults to DefaultMaxRetryDelay.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryableStatusCodes returns the status codes retried by default.
+func DefaultRetryableStatusCodes() []int {
+	return []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+}
+
+// DefaultRetryPolicy returns a new RetryPolicy with default values.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		StatusCodes: DefaultRetryableStatusCodes(),
+		MaxRetries:  DefaultMaxRetries,
+		MinDelay:    DefaultMinRetryDelay,
+		MaxDelay:    DefaultMaxRetryDelay,
+	}
+}
+
+// shouldRetry returns true if a response with the given status code should be
+// retried.
+func (p *RetryPolicy) shouldRetry(status int, idempotent bool) bool {
+	if status == http.StatusTooManyRequests {
+		return p.retryable(status)
+	}
+
+	return idempotent && p.retryable(status)
+}
+
+// retryable returns true if the status code is one of the retryable status
+// codes.
+func (p *RetryPolicy) retryable(status int) bool {
+	codes := p.StatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryableStatusCodes()
+	}
+
+	for _, code := range codes {
+		if code == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns how long to wait before the given retry, counting from zero.
+func (p *RetryPolicy) delay(resp *http.Response, retry int) time.Duration {
+	var (
+		minDelay = p.MinDelay
+		maxDelay = p.MaxDelay
+	)
+
+	if minDelay < 1 {
+		minDelay = DefaultMinRetryDelay
+	}
+
+	if maxDelay < 1 {
+		maxDelay = DefaultMaxRetryDelay
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return min(time.Duration(seconds)*time.Second, maxDelay)
+	}
+
+	delay := minDelay << min(retry, 16)
+	delay += time.Duration((rand.Float64()*2 - 1) * _jitterFraction * float64(delay)) //nolint:gosec // jitter doesn't need a secure source
+
+	return max(minDelay, min(delay, maxDelay))
+}
+
+// isIdempotent returns true if requests using the given method can be safely
+// sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,13 +1,13 @@
 package ohdear
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 
-	"git.sr.ht/~jamesponddotco/httpx-go"
 	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
 	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
 	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
@@ -22,52 +22,203 @@ type Sites struct {
 }
 
 type Site struct {
-	CreatedAt                            jsonutil.Time `json:"created_at,omitempty"`
-	UpdatedAt                            jsonutil.Time `json:"updated_at,omitempty"`
-	LatestRunDate                        jsonutil.Time `json:"latest_run_date,omitempty"`
-	GroupName                            *string       `json:"group_name,omitempty"`
-	HTTPClientHeaders                    *string       `json:"http_client_headers,omitempty"`
-	MarkedForDeletionAt                  *string       `json:"marked_for_deletion_at,omitempty"`
-	BrokenLinksWhitelistedURLs           *string       `json:"broken_links_whitelisted_urls,omitempty"`
-	Notes                                *string       `json:"notes,omitempty"`
-	FriendlyName                         *string       `json:"friendly_name,omitempty"`
-	Label                                string        `json:"label,omitempty"`
-	SortURL                              string        `json:"sort_url,omitempty"`
-	URL                                  string        `json:"url,omitempty"`
-	SummarizedCheckResult                string        `json:"summarized_check_result,omitempty"`
-	Checks                               []Check       `json:"checks,omitempty"`
-	Tags                                 []string      `json:"tags,omitempty"`
-	UptimeCheckPayload                   []string      `json:"uptime_check_payload,omitempty"`
-	ID                                   int           `json:"id,omitempty"`
-	TeamID                               int           `json:"team_id,omitempty"`
-	UsesHTTPS                            bool          `json:"uses_https,omitempty"`
-	BrokenLinksCheckIncludeExternalLinks bool          `json:"broken_links_check_include_external_links,omitempty"`
+	// Extra holds the fields returned by the API that are not known to this
+	// package, so they're sent back unchanged when the site is updated.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// Uptime holds the settings of the uptime check of the site. It's nil if
+	// the API returned none, and only the settings that are set are sent.
+	Uptime *UptimeSettings `json:"-"`
+
+	// HTTPClientHeaders holds the headers sent by the uptime check, one per
+	// line as "Name: Value". It's only sent if Uptime sets no headers, and
+	// it's never set when a site is decoded.
+	//
+	// Deprecated: Use Uptime.Headers instead.
+	HTTPClientHeaders Nullable[string] `json:"-"`
+
+	// UptimeCheckPayload holds the fields sent as the body of the uptime
+	// check request, as "key=value". It's only sent if Uptime sets no
+	// payload, and it's never set when a site is decoded.
+	//
+	// Deprecated: Use Uptime.Payload instead.
+	UptimeCheckPayload []string `json:"-"`
+
+	CreatedAt                            jsonutil.Time    `json:"created_at,omitempty"`
+	UpdatedAt                            jsonutil.Time    `json:"updated_at,omitempty"`
+	LatestRunDate                        jsonutil.Time    `json:"latest_run_date,omitempty"`
+	GroupName                            Nullable[string] `json:"group_name,omitempty"`
+	MarkedForDeletionAt                  Nullable[string] `json:"marked_for_deletion_at,omitempty"`
+	BrokenLinksWhitelistedURLs           Nullable[string] `json:"broken_links_whitelisted_urls,omitempty"`
+	Notes                                Nullable[string] `json:"notes,omitempty"`
+	FriendlyName                         Nullable[string] `json:"friendly_name,omitempty"`
+	Label                                string           `json:"label,omitempty"`
+	SortURL                              string           `json:"sort_url,omitempty"`
+	URL                                  string           `json:"url,omitempty"`
+	SummarizedCheckResult                CheckResult      `json:"summarized_check_result,omitempty"`
+	Checks                               []Check          `json:"checks,omitempty"`
+	Tags                                 []string         `json:"tags,omitempty"`
+	ID                                   int              `json:"id,omitempty"`
+	TeamID                               int              `json:"team_id,omitempty"`
+	UsesHTTPS                            bool             `json:"uses_https,omitempty"`
+	BrokenLinksCheckIncludeExternalLinks bool             `json:"broken_links_check_include_external_links,omitempty"`
 }
 
 type Check struct {
+	// Extra holds the fields returned by the API that are not known to this
+	// package.
+	Extra map[string]json.RawMessage `json:"-"`
+
 	LatestRunEndedAt jsonutil.Time `json:"latest_run_ended_at,omitempty"`
-	Type             string        `json:"type,omitempty"`
+	Type             CheckType     `json:"type,omitempty"`
 	Label            string        `json:"label,omitempty"`
-	LatestRunResult  string        `json:"latest_run_result,omitempty"`
+	LatestRunResult  CheckResult   `json:"latest_run_result,omitempty"`
 	Summary          string        `json:"summary,omitempty"`
 	ID               int           `json:"id,omitempty"`
 	Enabled          bool          `json:"enabled,omitempty"`
 }
 
+// MarshalJSON implements the json.Marshaler interface. Nullable fields that
+// are not set are left out, and the fields in Extra and Uptime are included
+// at the top level of the object, the way the API expects them.
+func (s Site) MarshalJSON() ([]byte, error) {
+	type site Site
+
+	extra := s.Extra
+
+	if uptime := s.uptimeSettings(); uptime != nil {
+		fields, err := uptime.fields()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := fields[_uptimeHeadersField]; !ok && s.HTTPClientHeaders.IsNull() {
+			fields[_uptimeHeadersField] = json.RawMessage("null")
+		}
+
+		extra = make(map[string]json.RawMessage, len(s.Extra)+len(fields))
+
+		for name, value := range s.Extra {
+			extra[name] = value
+		}
+
+		for name, value := range fields {
+			extra[name] = value
+		}
+	}
+
+	data, err := jsonutil.MarshalObject(site(s), extra)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return data, nil
+}
+
+// uptimeSettings returns the uptime check settings sent for the site, which
+// are the ones in Uptime completed with the deprecated HTTPClientHeaders and
+// UptimeCheckPayload fields, or nil if there are none.
+func (s *Site) uptimeSettings() *UptimeSettings {
+	if s.Uptime == nil && !s.HTTPClientHeaders.IsSet() && len(s.UptimeCheckPayload) == 0 {
+		return nil
+	}
+
+	uptime := &UptimeSettings{}
+	if s.Uptime != nil {
+		uptime = s.Uptime.clone()
+	}
+
+	if len(uptime.Headers) == 0 {
+		uptime.Headers = parseHeaderLines(s.HTTPClientHeaders.Value())
+	}
+
+	if len(uptime.Payload) == 0 {
+		uptime.Payload = parsePayloadFields(s.UptimeCheckPayload)
+	}
+
+	return uptime
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Uptime check
+// settings are stored in Uptime, and other fields not known to this package
+// in Extra.
+func (s *Site) UnmarshalJSON(data []byte) error {
+	type site Site
+
+	extra, err := jsonutil.UnmarshalObject(data, (*site)(s))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	s.Uptime, s.Extra = splitUptimeSettings(s.Uptime, extra)
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The fields in Extra are
+// included.
+func (c Check) MarshalJSON() ([]byte, error) {
+	type check Check
+
+	data, err := jsonutil.MarshalObject(check(c), c.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Fields not known
+// to this package are stored in Extra.
+func (c *Check) UnmarshalJSON(data []byte) error {
+	type check Check
+
+	extra, err := jsonutil.UnmarshalObject(data, (*check)(c))
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	c.Extra = extra
+
+	return nil
+}
+
+// IsHealthy returns true if every enabled check of the site succeeded in its
+// latest run.
+func (s *Site) IsHealthy() bool {
+	return s.SummarizedCheckResult == CheckResultSucceeded
+}
+
+// FailingChecks returns the enabled checks of the site whose latest run
+// failed or couldn't complete.
+func (s *Site) FailingChecks() []Check {
+	var failing []Check
+
+	for i := range s.Checks {
+		if s.Checks[i].Enabled && s.Checks[i].LatestRunResult.IsFailure() {
+			failing = append(failing, s.Checks[i])
+		}
+	}
+
+	return failing
+}
+
 // List returns a list of all sites in your account.
 //
 // [API Reference].
 //
 // [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#get-all-sites-in-your-account
-func (s *SitesService) List(ctx context.Context, page uint) (*Sites, *Pagination, *Response, error) {
+func (s *SitesService) List(ctx context.Context, page uint, opts ...RequestOption) (*Sites, *Pagination, *Response, error) {
 	if ctx == nil {
 		return nil, nil, nil, ErrNilContext
 	}
 
-	path := DefaultBaseURL + endpoint.Sites
+	ctx = withOperation(ctx, "Sites.List")
+
+	path := s.client.cfg.BaseURL + endpoint.Sites
 
 	if page > 1 {
-		path += "?page=[number]=" + strconv.Itoa(int(page))
+		path += "?page[number]=" + strconv.Itoa(int(page))
 	}
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
@@ -75,7 +226,7 @@ func (s *SitesService) List(ctx context.Context, page uint) (*Sites, *Pagination
 		return nil, nil, nil, err
 	}
 
-	ret, err := s.client.Do(ctx, req)
+	ret, err := s.client.Do(ctx, req, opts...)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -88,28 +239,56 @@ func (s *SitesService) List(ctx context.Context, page uint) (*Sites, *Pagination
 	return &sites, &sites.Pagination, ret, nil
 }
 
+// ListAll returns every site in your account, following pagination until the
+// last page is reached.
+func (s *SitesService) ListAll(ctx context.Context, opts ...RequestOption) ([]Site, error) {
+	var (
+		sites []Site
+		page  uint = 1
+	)
+
+	for {
+		ret, pagination, _, err := s.List(ctx, page, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		sites = append(sites, ret.Data...)
+
+		if !pagination.HasNextPage() {
+			break
+		}
+
+		page++
+	}
+
+	return sites, nil
+}
+
 // Get returns a single site by ID.
 //
 // [API Reference].
 //
 // [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#get-a-specific-site-via-the-api
-func (s *SitesService) Get(ctx context.Context, id uint) (*Site, *Response, error) {
+func (s *SitesService) Get(ctx context.Context, id uint, opts ...RequestOption) (*Site, *Response, error) {
 	if ctx == nil {
 		return nil, nil, ErrNilContext
 	}
 
+	ctx = withOperation(ctx, "Sites.Get")
+
 	if id == 0 {
 		return nil, nil, ErrInvalidSiteID
 	}
 
-	path := DefaultBaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id))
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id))
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ret, err := s.client.Do(ctx, req)
+	ret, err := s.client.Do(ctx, req, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -127,11 +306,13 @@ func (s *SitesService) Get(ctx context.Context, id uint) (*Site, *Response, erro
 // [API Reference].
 //
 // [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#add-a-site-through-the-api
-func (s *SitesService) Add(ctx context.Context, site *Site) (*Site, *Response, error) {
+func (s *SitesService) Add(ctx context.Context, site *Site, opts ...RequestOption) (*Site, *Response, error) {
 	if ctx == nil {
 		return nil, nil, ErrNilContext
 	}
 
+	ctx = withOperation(ctx, "Sites.Add")
+
 	if site == nil {
 		return nil, nil, ErrNilSite
 	}
@@ -144,19 +325,25 @@ func (s *SitesService) Add(ctx context.Context, site *Site) (*Site, *Response, e
 		return nil, nil, ErrInvalidTeamID
 	}
 
-	payload, err := httpx.WriteJSON(site)
+	if site.Uptime != nil {
+		if err := site.Uptime.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	payload, err := json.Marshal(site)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w", err)
+		return nil, nil, fmt.Errorf("could not marshal site: %w", err)
 	}
 
-	path := DefaultBaseURL + endpoint.Sites
+	path := s.client.cfg.BaseURL + endpoint.Sites
 
-	req, err := s.client.NewRequest(ctx, http.MethodPost, path, payload)
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, bytes.NewReader(payload))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ret, err := s.client.Do(ctx, req)
+	ret, err := s.client.Do(ctx, req, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -169,31 +356,358 @@ func (s *SitesService) Add(ctx context.Context, site *Site) (*Site, *Response, e
 	return &addedSite, ret, nil
 }
 
+// Ensure makes sure a site with the URL and team of site exists, without
+// adding a duplicate when it's called more than once. URLs are compared in
+// their normalized form, so https://Example.com/ matches https://example.com.
+//
+// If no such site exists, site is added. If one does, it's updated with the
+// fields set in site that differ from it, ignoring the URL, the team and the
+// fields set by the API, such as timestamps, and returned as-is if there are
+// none. The returned Response is the one of the last request made, so a
+// status of 201 Created tells that the site was added.
+//
+// Sites are looked up by listing the sites of the team, bypassing the Cache
+// of the client, if any, so sites added moments ago are found.
+func (s *SitesService) Ensure(ctx context.Context, site *Site, opts ...RequestOption) (*Site, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	if site == nil {
+		return nil, nil, ErrNilSite
+	}
+
+	normalized, err := urlutil.Normalize(site.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w", err)
+	}
+
+	if site.TeamID == 0 {
+		return nil, nil, ErrInvalidTeamID
+	}
+
+	existing, ret, err := s.find(ctx, normalized, site.TeamID, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if existing == nil {
+		return s.Add(ctx, site, opts...)
+	}
+
+	changes, err := siteChanges(existing, site)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if changes == nil {
+		return existing, ret, nil
+	}
+
+	return s.Update(ctx, uint(existing.ID), changes, opts...)
+}
+
+// find returns the site of a team with the given normalized URL, or nil if
+// there's none, and the response of the last page listed.
+func (s *SitesService) find(ctx context.Context, normalized string, teamID int, opts ...RequestOption) (*Site, *Response, error) {
+	opts = append(opts[:len(opts):len(opts)], WithQuery("filter[team_id]", strconv.Itoa(teamID)), withoutCache())
+
+	for page := uint(1); ; page++ {
+		sites, pagination, ret, err := s.List(ctx, page, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i := range sites.Data {
+			site := &sites.Data[i]
+
+			if site.TeamID != teamID {
+				continue
+			}
+
+			if url, err := urlutil.Normalize(site.URL); err == nil && url == normalized {
+				return site, ret, nil
+			}
+		}
+
+		if !pagination.HasNextPage() {
+			return nil, ret, nil
+		}
+	}
+}
+
+// _managedSiteFields are the fields of a site object that identify the site
+// or are set by the API, which Ensure doesn't compare.
+var _managedSiteFields = map[string]bool{
+	"id":                      true,
+	"team_id":                 true,
+	"url":                     true,
+	"sort_url":                true,
+	"label":                   true,
+	"uses_https":              true,
+	"checks":                  true,
+	"summarized_check_result": true,
+	"created_at":              true,
+	"updated_at":              true,
+	"latest_run_date":         true,
+	"marked_for_deletion_at":  true,
+}
+
+// siteChanges returns the fields of desired that differ from existing, other
+// than the ones in _managedSiteFields, as a site to update existing with, or
+// nil if there are none.
+func siteChanges(existing, desired *Site) (*Site, error) {
+	have, err := siteFields(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	want, err := siteFields(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range want {
+		if _managedSiteFields[key] {
+			continue
+		}
+
+		if !bytes.Equal(value, have[key]) {
+			changes := *desired
+			changes.URL = ""
+			changes.TeamID = 0
+
+			return &changes, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// siteFields returns the JSON fields sent to the API for site.
+func siteFields(site *Site) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(site)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal site: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("could not unmarshal site: %w", err)
+	}
+
+	return fields, nil
+}
+
+// Update updates the settings of a site by ID. Fields left empty in site are
+// not changed.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#updating-a-site
+func (s *SitesService) Update(ctx context.Context, id uint, site *Site, opts ...RequestOption) (*Site, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Sites.Update")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	if site == nil {
+		return nil, nil, ErrNilSite
+	}
+
+	if site.URL != "" {
+		if err := urlutil.Validate(site.URL); err != nil {
+			return nil, nil, fmt.Errorf("%w", err)
+		}
+	}
+
+	if site.Uptime != nil {
+		if err := site.Uptime.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	payload, err := json.Marshal(site)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal site: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var updatedSite Site
+	if err := json.Unmarshal(ret.Body, &updatedSite); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal updated site: %w", err)
+	}
+
+	return &updatedSite, ret, nil
+}
+
+// UpdateUptimeSettings changes the settings of the uptime check of a site by
+// ID. Settings left empty are not changed, and the settings are validated
+// before they're sent.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#updating-a-site
+func (s *SitesService) UpdateUptimeSettings(ctx context.Context, id uint, settings *UptimeSettings, opts ...RequestOption) (*Site, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Sites.UpdateUptimeSettings")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidSiteID
+	}
+
+	if settings == nil {
+		return nil, nil, ErrNilUptimeSettings
+	}
+
+	if err := settings.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := json.Marshal(settings)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal uptime settings: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var updatedSite Site
+	if err := json.Unmarshal(ret.Body, &updatedSite); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal updated site: %w", err)
+	}
+
+	return &updatedSite, ret, nil
+}
+
 // Remove removes a site from your account.
 //
 // [API Reference].
 //
 // [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#deleting-a-site
-func (s *SitesService) Remove(ctx context.Context, id uint) (*Response, error) {
+func (s *SitesService) Remove(ctx context.Context, id uint, opts ...RequestOption) (*Response, error) {
 	if ctx == nil {
 		return nil, ErrNilContext
 	}
 
+	ctx = withOperation(ctx, "Sites.Remove")
+
 	if id == 0 {
 		return nil, ErrInvalidSiteID
 	}
 
-	path := DefaultBaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id))
+	path := s.client.cfg.BaseURL + endpoint.Sites + "/" + strconv.Itoa(int(id))
 
 	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, http.NoBody)
 	if err != nil {
 		return nil, err
 	}
 
-	ret, err := s.client.Do(ctx, req)
+	ret, err := s.client.Do(ctx, req, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return ret, nil
 }
+
+// AddMany adds several sites to your account concurrently, collecting the
+// result of every site instead of stopping at the first error. See
+// BulkOptions for how the requests are spread across workers.
+func (s *SitesService) AddMany(ctx context.Context, sites []*Site, bulk *BulkOptions, opts ...RequestOption) BulkResults[*Site] {
+	return runBulk(ctx, sites, bulk, func(ctx context.Context, site *Site) (*Site, *Response, error) {
+		return s.Add(ctx, site, opts...)
+	})
+}
+
+// GetMany returns several sites by ID concurrently, collecting the result of
+// every site instead of stopping at the first error. See BulkOptions for how
+// the requests are spread across workers.
+func (s *SitesService) GetMany(ctx context.Context, ids []uint, bulk *BulkOptions, opts ...RequestOption) BulkResults[*Site] {
+	return runBulk(ctx, ids, bulk, func(ctx context.Context, id uint) (*Site, *Response, error) {
+		return s.Get(ctx, id, opts...)
+	})
+}
+
+// RemoveMany removes several sites from your account concurrently, collecting
+// the result of every site instead of stopping at the first error. The value
+// of each result is the ID of the removed site. See BulkOptions for how the
+// requests are spread across workers.
+func (s *SitesService) RemoveMany(ctx context.Context, ids []uint, bulk *BulkOptions, opts ...RequestOption) BulkResults[uint] {
+	return runBulk(ctx, ids, bulk, func(ctx context.Context, id uint) (uint, *Response, error) {
+		ret, err := s.Remove(ctx, id, opts...)
+		if err != nil {
+			return 0, ret, err
+		}
+
+		return id, ret, nil
+	})
+}
+
+// Duplicates returns the groups of sites that share a team and a URL once
+// normalized, such as the ones left behind by scripts that added the same
+// site twice. Sites are kept in their original order, and sites with URLs
+// that can't be normalized are ignored.
+func Duplicates(sites []Site) [][]Site {
+	var (
+		groups [][]Site
+		index  = make(map[string]int, len(sites))
+	)
+
+	for i := range sites {
+		normalized, err := urlutil.Normalize(sites[i].URL)
+		if err != nil {
+			continue
+		}
+
+		key := strconv.Itoa(sites[i].TeamID) + " " + normalized
+
+		j, ok := index[key]
+		if !ok {
+			index[key] = len(groups)
+			groups = append(groups, []Site{sites[i]})
+
+			continue
+		}
+
+		groups[j] = append(groups[j], sites[i])
+	}
+
+	duplicates := groups[:0]
+
+	for _, group := range groups {
+		if len(group) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+
+	return duplicates
+}
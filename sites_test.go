@@ -0,0 +1,488 @@
+package ohdear_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+	"git.sr.ht/~jamesponddotco/ohdear-go/recorder"
+)
+
+func TestSitesService(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		srv = ohdeartest.NewServer(nil)
+	)
+
+	defer srv.Close()
+
+	client := srv.Client()
+
+	added, _, err := client.Sites.Add(ctx, &ohdear.Site{
+		URL:    "https://example.com",
+		TeamID: 1,
+		Tags:   []string{"production"},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if added.ID == 0 || len(added.Checks) == 0 {
+		t.Errorf("Add() = %+v, want an ID and default checks", added)
+	}
+
+	got, _, err := client.Sites.Get(ctx, uint(added.ID))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.URL != added.URL || len(got.Tags) != 1 || got.Tags[0] != "production" {
+		t.Errorf("Get() = %+v, want %+v", got, added)
+	}
+
+	sites, _, _, err := client.Sites.List(ctx, 1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(sites.Data) != 1 {
+		t.Errorf("List() = %d sites, want 1", len(sites.Data))
+	}
+
+	const label = "Example"
+
+	updated, _, err := client.Sites.Update(ctx, uint(added.ID), &ohdear.Site{
+		FriendlyName: ohdear.NewNullable(label),
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if updated.URL != added.URL || updated.FriendlyName.Value() != label {
+		t.Errorf("Update() = %+v, want friendly name %q and URL unchanged", updated, label)
+	}
+
+	if _, err = client.Sites.Remove(ctx, uint(added.ID)); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	_, _, err = client.Sites.Get(ctx, uint(added.ID))
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Errorf("Get() after Remove() error = %v, want a 404 *ohdear.APIError", err)
+	}
+}
+
+func TestSitesService_Add_Validation(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	client := srv.Client()
+
+	tests := []struct {
+		name string
+		site *ohdear.Site
+		want error
+	}{
+		{
+			name: "Nil site",
+			site: nil,
+			want: ohdear.ErrNilSite,
+		},
+		{
+			name: "Missing team",
+			site: &ohdear.Site{URL: "https://example.com"},
+			want: ohdear.ErrInvalidTeamID,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, _, err := client.Sites.Add(context.Background(), tt.site); !errors.Is(err, tt.want) {
+				t.Errorf("Add() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSitesService_Ensure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		srv = ohdeartest.NewServer(nil)
+	)
+
+	defer srv.Close()
+
+	var methods []string
+
+	// Lookups must find the sites added by earlier calls even with a cache.
+	cfg := ohdear.NewConfig("", nil)
+	cfg.Cache = ohdear.NewMemoryCache(0)
+	cfg.Middleware = []ohdear.Middleware{
+		func(next ohdear.Doer) ohdear.Doer {
+			return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				methods = append(methods, req.Method)
+
+				return next.Do(req)
+			})
+		},
+	}
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	other := srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 2})
+
+	added, ret, err := client.Sites.Ensure(ctx, &ohdear.Site{URL: "https://Example.com/", TeamID: 1})
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+
+	if ret.Status != http.StatusCreated || added.ID == other.ID {
+		t.Fatalf("Ensure() = site %d with status %d, want a new site", added.ID, ret.Status)
+	}
+
+	if added.SortURL != "example.com" {
+		t.Errorf("Ensure() sort URL = %q, want %q", added.SortURL, "example.com")
+	}
+
+	tests := []struct {
+		name        string
+		site        *ohdear.Site
+		wantStatus  int
+		wantTags    []string
+		wantMethods []string
+	}{
+		{
+			name:        "Unchanged",
+			site:        &ohdear.Site{URL: "https://example.com:443", TeamID: 1},
+			wantStatus:  http.StatusOK,
+			wantMethods: []string{http.MethodGet},
+		},
+		{
+			name:        "Changed",
+			site:        &ohdear.Site{URL: "HTTPS://EXAMPLE.COM", TeamID: 1, Tags: []string{"production"}},
+			wantStatus:  http.StatusOK,
+			wantTags:    []string{"production"},
+			wantMethods: []string{http.MethodGet, http.MethodPut},
+		},
+		{
+			name:        "Unchanged after update",
+			site:        &ohdear.Site{URL: "https://example.com", TeamID: 1, Tags: []string{"production"}},
+			wantStatus:  http.StatusOK,
+			wantTags:    []string{"production"},
+			wantMethods: []string{http.MethodGet},
+		},
+	}
+
+	for _, tt := range tests {
+		methods = nil
+
+		got, ret, err := client.Sites.Ensure(ctx, tt.site)
+		if err != nil {
+			t.Fatalf("%s: Ensure() error = %v", tt.name, err)
+		}
+
+		if got.ID != added.ID || ret.Status != tt.wantStatus {
+			t.Errorf("%s: Ensure() = site %d with status %d, want site %d with status %d", tt.name, got.ID, ret.Status, added.ID, tt.wantStatus)
+		}
+
+		if len(got.Tags) != len(tt.wantTags) {
+			t.Errorf("%s: Ensure() tags = %v, want %v", tt.name, got.Tags, tt.wantTags)
+		}
+
+		if !reflect.DeepEqual(methods, tt.wantMethods) {
+			t.Errorf("%s: Ensure() sent %v, want %v", tt.name, methods, tt.wantMethods)
+		}
+
+		if got.URL != "https://Example.com/" {
+			t.Errorf("%s: Ensure() changed the URL to %q", tt.name, got.URL)
+		}
+	}
+
+	if got := len(srv.Sites()); got != 2 {
+		t.Errorf("server has %d sites, want 2", got)
+	}
+
+	if _, _, err = client.Sites.Ensure(ctx, &ohdear.Site{URL: "https://example.com"}); !errors.Is(err, ohdear.ErrInvalidTeamID) {
+		t.Errorf("Ensure() without team error = %v, want %v", err, ohdear.ErrInvalidTeamID)
+	}
+
+	if _, _, err = client.Sites.Ensure(ctx, &ohdear.Site{URL: "ftp://example.com", TeamID: 1}); !errors.Is(err, ohdear.ErrInvalidURL) {
+		t.Errorf("Ensure() with invalid URL error = %v, want %v", err, ohdear.ErrInvalidURL)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	t.Parallel()
+
+	sites := []ohdear.Site{
+		{ID: 1, URL: "https://example.com", TeamID: 1},
+		{ID: 2, URL: "https://example.org", TeamID: 1},
+		{ID: 3, URL: "https://EXAMPLE.com:443/", TeamID: 1},
+		{ID: 4, URL: "https://example.com", TeamID: 2},
+		{ID: 5, URL: "http://example.com", TeamID: 1},
+		{ID: 6, URL: "https://bücher.example", TeamID: 1},
+		{ID: 7, URL: "https://xn--bcher-kva.example/", TeamID: 1},
+	}
+
+	got := ohdear.Duplicates(sites)
+
+	if len(got) != 2 {
+		t.Fatalf("Duplicates() returned %d groups, want 2", len(got))
+	}
+
+	for i, want := range [][]int{{1, 3}, {6, 7}} {
+		if len(got[i]) != len(want) || got[i][0].ID != want[0] || got[i][1].ID != want[1] {
+			t.Errorf("Duplicates()[%d] = %+v, want sites %v", i, got[i], want)
+		}
+	}
+}
+
+// fixtureClient returns a client replaying the interactions in the given
+// golden file.
+func fixtureClient(t *testing.T, path string) *ohdear.Client {
+	t.Helper()
+
+	rec, err := recorder.New(&recorder.Options{
+		Mode: recorder.ModeReplay,
+		Path: path,
+	})
+	if err != nil {
+		t.Fatalf("recorder.New() error = %v", err)
+	}
+
+	cfg := ohdear.NewConfig("key", nil)
+	cfg.Transport = rec
+
+	client, err := ohdear.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	return client
+}
+
+func TestSitesService_Fixtures(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx    = context.Background()
+		client = fixtureClient(t, "testdata/sites.json")
+	)
+
+	sites, pagination, _, err := client.Sites.List(ctx, 1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(sites.Data) != 1 || pagination.HasNextPage() || pagination.Meta.Pages != 1 {
+		t.Fatalf("List() = %d sites, pagination %+v", len(sites.Data), pagination)
+	}
+
+	site, _, err := client.Sites.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if site.URL != "https://laravel.com" || site.TeamID != 1 || !site.UsesHTTPS {
+		t.Errorf("Get() = %+v", site)
+	}
+
+	if site.FriendlyName.Value() != "Laravel" || !site.Notes.IsNull() {
+		t.Errorf("Get() FriendlyName = %v, Notes = %v", site.FriendlyName, site.Notes)
+	}
+
+	if want := time.Date(2019, 9, 16, 7, 29, 2, 0, time.UTC); !site.LatestRunDate.Equal(want) {
+		t.Errorf("Get() LatestRunDate = %v, want %v", site.LatestRunDate, want)
+	}
+
+	if len(site.Checks) != 4 || site.Checks[1].LatestRunResult != "failed" || site.Checks[3].Enabled {
+		t.Errorf("Get() Checks = %+v", site.Checks)
+	}
+
+	_, _, err = client.Sites.Get(ctx, 2)
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Message == "" {
+		t.Errorf("Get(2) error = %v, want a 404 *ohdear.APIError with a message", err)
+	}
+}
+
+func TestSite_Health(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		give        string
+		wantFailing []ohdear.CheckType
+		wantHealthy bool
+		wantKnown   bool
+	}{
+		{
+			name:        "healthy",
+			give:        `{"summarized_check_result":"succeeded","checks":[{"type":"uptime","enabled":true,"latest_run_result":"succeeded"}]}`,
+			wantHealthy: true,
+			wantKnown:   true,
+		},
+		{
+			name:        "failing checks",
+			give:        `{"summarized_check_result":"failed","checks":[{"type":"uptime","enabled":true,"latest_run_result":"failed"},{"type":"dns","enabled":true,"latest_run_result":"errored-or-timed-out"},{"type":"broken_links","enabled":false,"latest_run_result":"failed"},{"type":"performance","enabled":true,"latest_run_result":"warning"}]}`,
+			wantFailing: []ohdear.CheckType{ohdear.CheckTypeUptime, ohdear.CheckTypeDNS},
+			wantKnown:   true,
+		},
+		{
+			name: "unknown values",
+			give: `{"summarized_check_result":"on-fire","checks":[{"type":"quantum","enabled":true,"latest_run_result":"on-fire"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var site ohdear.Site
+			if err := json.Unmarshal([]byte(tt.give), &site); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if got := site.IsHealthy(); got != tt.wantHealthy {
+				t.Errorf("IsHealthy() = %t, want %t", got, tt.wantHealthy)
+			}
+
+			failing := site.FailingChecks()
+			if len(failing) != len(tt.wantFailing) {
+				t.Fatalf("FailingChecks() = %+v, want types %v", failing, tt.wantFailing)
+			}
+
+			for i := range failing {
+				if failing[i].Type != tt.wantFailing[i] {
+					t.Errorf("FailingChecks()[%d].Type = %q, want %q", i, failing[i].Type, tt.wantFailing[i])
+				}
+			}
+
+			if got := site.SummarizedCheckResult.Known(); got != tt.wantKnown {
+				t.Errorf("SummarizedCheckResult.Known() = %t, want %t", got, tt.wantKnown)
+			}
+
+			if got := site.Checks[0].Type.Known(); got != tt.wantKnown {
+				t.Errorf("Checks[0].Type.Known() = %t, want %t", got, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestSite_JSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		site *ohdear.Site
+		give string
+		want string
+	}{
+		{
+			name: "Unknown fields are preserved",
+			give: `{"id":1,"url":"https://example.com","future_field":{"a":1},"checks":[{"id":2,"type":"uptime","future_check_field":true}]}`,
+			want: `{"checks":[{"future_check_field":true,"id":2,"latest_run_ended_at":null,"type":"uptime"}],"created_at":null,"future_field":{"a":1},"id":1,"latest_run_date":null,"updated_at":null,"url":"https://example.com"}`,
+		},
+		{
+			name: "Explicit nulls are preserved",
+			give: `{"id":1,"notes":null,"friendly_name":"Example"}`,
+			want: `{"created_at":null,"friendly_name":"Example","id":1,"latest_run_date":null,"notes":null,"updated_at":null}`,
+		},
+		{
+			name: "Nullable fields",
+			site: &ohdear.Site{
+				Notes:        ohdear.Null[string](),
+				FriendlyName: ohdear.NewNullable("Example"),
+			},
+			want: `{"created_at":null,"friendly_name":"Example","latest_run_date":null,"notes":null,"updated_at":null}`,
+		},
+		{
+			name: "Uptime settings are flattened",
+			site: &ohdear.Site{
+				URL: "https://example.com",
+				Uptime: &ohdear.UptimeSettings{
+					MaxRedirects: ohdear.NewNullable(0),
+					Method:       http.MethodPost,
+					Payload:      []ohdear.UptimePayloadField{{Key: "ping", Value: "1"}},
+				},
+			},
+			want: `{"created_at":null,"latest_run_date":null,"updated_at":null,"uptime_check_http_verb":"POST","uptime_check_max_redirect_count":0,"uptime_check_payload":[{"key":"ping","value":"1"}],"url":"https://example.com"}`,
+		},
+		{
+			name: "Deprecated uptime fields are mapped",
+			site: &ohdear.Site{
+				URL:                "https://example.com",
+				HTTPClientHeaders:  ohdear.NewNullable("X-Token: secret\nAccept: text/html"),
+				UptimeCheckPayload: []string{"ping=1"},
+				Uptime: &ohdear.UptimeSettings{
+					Method:  http.MethodPost,
+					Payload: []ohdear.UptimePayloadField{{Key: "check", Value: "deep"}},
+				},
+			},
+			want: `{"created_at":null,"http_client_headers":[{"name":"X-Token","value":"secret"},{"name":"Accept","value":"text/html"}],"latest_run_date":null,"updated_at":null,"uptime_check_http_verb":"POST","uptime_check_payload":[{"key":"check","value":"deep"}],"url":"https://example.com"}`,
+		},
+		{
+			name: "Deprecated headers cleared",
+			site: &ohdear.Site{
+				URL:               "https://example.com",
+				HTTPClientHeaders: ohdear.Null[string](),
+			},
+			want: `{"created_at":null,"http_client_headers":null,"latest_run_date":null,"updated_at":null,"url":"https://example.com"}`,
+		},
+		{
+			name: "Uptime settings are preserved",
+			give: `{"id":1,"uptime_check_look_for_string":"OK","uptime_check_absent_string":null,"http_client_headers":[{"name":"X-Token","value":"secret"}],"uptime_check_verify_tls":false}`,
+			want: `{"created_at":null,"http_client_headers":[{"name":"X-Token","value":"secret"}],"id":1,"latest_run_date":null,"updated_at":null,"uptime_check_absent_string":null,"uptime_check_look_for_string":"OK","uptime_check_verify_tls":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			site := tt.site
+			if site == nil {
+				site = &ohdear.Site{}
+
+				if err := json.Unmarshal([]byte(tt.give), site); err != nil {
+					t.Fatalf("Unmarshal() error = %v", err)
+				}
+			}
+
+			got, err := json.Marshal(site)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package sla
+
+import (
+	"time"
+)
+
+// Default values for the BusinessHours struct.
+const (
+	DefaultBusinessHoursStart time.Duration = 9 * time.Hour
+	DefaultBusinessHoursEnd   time.Duration = 17 * time.Hour
+)
+
+// BusinessHours restricts a calculation to the working hours of a timezone.
+type BusinessHours struct {
+	// Location is the timezone the hours are in.
+	//
+	// This field is optional. It defaults to UTC.
+	Location *time.Location
+
+	// Days are the days of the week with business hours.
+	//
+	// This field is optional. It defaults to Monday through Friday.
+	Days []time.Weekday
+
+	// Start is the time of day business hours start, as an offset from
+	// midnight in wall clock time.
+	//
+	// This field is optional. It defaults to DefaultBusinessHoursStart.
+	Start time.Duration
+
+	// End is the time of day business hours end, as an offset from midnight in
+	// wall clock time. It must be after Start.
+	//
+	// This field is optional. It defaults to DefaultBusinessHoursEnd.
+	End time.Duration
+}
+
+// DefaultBusinessDays returns the days of the week with business hours by
+// default.
+func DefaultBusinessDays() []time.Weekday {
+	return []time.Weekday{
+		time.Monday,
+		time.Tuesday,
+		time.Wednesday,
+		time.Thursday,
+		time.Friday,
+	}
+}
+
+// validate returns an error if the business hours are invalid.
+func (b *BusinessHours) validate() error {
+	start, end := b.hours()
+
+	if start < 0 || end > 24*time.Hour || end <= start {
+		return ErrInvalidBusinessHours
+	}
+
+	return nil
+}
+
+// hours returns the start and end of business hours, with defaults applied.
+func (b *BusinessHours) hours() (start, end time.Duration) {
+	start, end = b.Start, b.End
+
+	if start == 0 && end == 0 {
+		return DefaultBusinessHoursStart, DefaultBusinessHoursEnd
+	}
+
+	return start, end
+}
+
+// periods returns the business hours inside bounds, merged.
+func (b *BusinessHours) periods(bounds Period) []Period {
+	var (
+		loc        = b.Location
+		days       = b.Days
+		start, end = b.hours()
+	)
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if len(days) == 0 {
+		days = DefaultBusinessDays()
+	}
+
+	open := make(map[time.Weekday]bool, len(days))
+
+	for _, day := range days {
+		open[day] = true
+	}
+
+	var (
+		first   = bounds.Start.In(loc)
+		periods []Period
+	)
+
+	// Days are stepped with time.Date rather than by adding 24 hours, so
+	// daylight saving time changes don't shift the hours.
+	for y, m, d := first.Date(); ; d++ {
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if !midnight.Before(bounds.End) {
+			break
+		}
+
+		if !open[midnight.Weekday()] {
+			continue
+		}
+
+		p := Period{
+			Start: wallClock(y, m, d, start, loc),
+			End:   wallClock(y, m, d, end, loc),
+		}
+
+		if p = p.clip(bounds); !p.IsEmpty() {
+			periods = append(periods, p)
+		}
+	}
+
+	return merge(periods)
+}
+
+// wallClock returns the time offset from midnight of the given day in wall
+// clock time.
+func wallClock(year int, month time.Month, day int, offset time.Duration, loc *time.Location) time.Time {
+	var (
+		hour   = int(offset / time.Hour)
+		minute = int(offset % time.Hour / time.Minute)
+		sec    = int(offset % time.Minute / time.Second)
+	)
+
+	return time.Date(year, month, day, hour, minute, sec, 0, loc)
+}
@@ -0,0 +1,140 @@
+package sla
+
+import (
+	"sort"
+	"time"
+)
+
+// Period is a span of time between Start, inclusive, and End, exclusive.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the period, or zero if it's empty.
+func (p Period) Duration() time.Duration {
+	if !p.End.After(p.Start) {
+		return 0
+	}
+
+	return p.End.Sub(p.Start)
+}
+
+// IsEmpty returns true if the period doesn't span any time.
+func (p Period) IsEmpty() bool {
+	return !p.End.After(p.Start)
+}
+
+// clip returns the part of p inside bounds.
+func (p Period) clip(bounds Period) Period {
+	if p.Start.Before(bounds.Start) {
+		p.Start = bounds.Start
+	}
+
+	if p.End.After(bounds.End) {
+		p.End = bounds.End
+	}
+
+	return p
+}
+
+// merge returns the non-empty periods sorted by start time, with overlapping
+// and adjacent periods joined.
+func merge(periods []Period) []Period {
+	sorted := make([]Period, 0, len(periods))
+
+	for _, p := range periods {
+		if !p.IsEmpty() {
+			sorted = append(sorted, p)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	merged := make([]Period, 0, len(sorted))
+
+	for _, p := range sorted {
+		last := len(merged) - 1
+
+		if last >= 0 && !p.Start.After(merged[last].End) {
+			if p.End.After(merged[last].End) {
+				merged[last].End = p.End
+			}
+
+			continue
+		}
+
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+// intersect returns the parts of a that are also in b. Both must be merged.
+func intersect(a, b []Period) []Period {
+	var (
+		out  []Period
+		i, j int
+	)
+
+	for i < len(a) && j < len(b) {
+		p := a[i].clip(b[j])
+		if !p.IsEmpty() {
+			out = append(out, p)
+		}
+
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return out
+}
+
+// subtract returns the parts of a that are not in b. Both must be merged.
+func subtract(a, b []Period) []Period {
+	var out []Period
+
+	for _, p := range a {
+		for _, q := range b {
+			if !q.End.After(p.Start) {
+				continue
+			}
+
+			if !q.Start.Before(p.End) {
+				break
+			}
+
+			if q.Start.After(p.Start) {
+				out = append(out, Period{Start: p.Start, End: q.Start})
+			}
+
+			p.Start = q.End
+
+			if p.IsEmpty() {
+				break
+			}
+		}
+
+		if !p.IsEmpty() {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// total returns the combined duration of the periods.
+func total(periods []Period) time.Duration {
+	var d time.Duration
+
+	for _, p := range periods {
+		d += p.Duration()
+	}
+
+	return d
+}
@@ -0,0 +1,275 @@
+// Package sla calculates service level figures, such as availability and
+// error budgets, from the downtime of an Oh Dear site.
+//
+// Figures are computed over a reporting range, optionally leaving out
+// maintenance periods and time outside business hours, so they match the way
+// service level agreements are usually written.
+//
+//	report, err := sla.Fetch(ctx, client, siteID, sla.Period{
+//		Start: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+//		End:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+//	}, &sla.Options{
+//		Target:             99.9,
+//		ExcludeMaintenance: true,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	fmt.Printf("%.3f%% available, %s of error budget left\n", report.Availability, report.ErrorBudgetRemaining)
+package sla
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrInvalidRange is returned when the reporting range doesn't end after
+	// it starts.
+	ErrInvalidRange xerrors.Error = "reporting range must end after it starts"
+
+	// ErrInvalidTarget is returned when the availability target is not
+	// between zero and 100.
+	ErrInvalidTarget xerrors.Error = "target must be greater than 0 and at most 100"
+
+	// ErrInvalidBusinessHours is returned when business hours don't end after
+	// they start within a single day.
+	ErrInvalidBusinessHours xerrors.Error = "business hours must end after they start within a day"
+
+	// ErrClientRequired is returned when Fetch is called without a client.
+	ErrClientRequired xerrors.Error = "client cannot be nil"
+)
+
+// DefaultTarget is the availability target used when none is given, as a
+// percentage.
+const DefaultTarget float64 = 99.9
+
+// Options configures a calculation.
+type Options struct {
+	// BusinessHours restricts the calculation to business hours. Downtime
+	// outside them is ignored.
+	//
+	// This field is optional. By default, every hour counts.
+	BusinessHours *BusinessHours
+
+	// Target is the availability promised by the agreement, as a percentage,
+	// from which the error budget is derived.
+	//
+	// This field is optional. It defaults to DefaultTarget.
+	Target float64
+
+	// ExcludeMaintenance leaves maintenance periods out of the calculation,
+	// so downtime during them doesn't count against the target.
+	//
+	// This field is optional. It defaults to false.
+	ExcludeMaintenance bool
+}
+
+// Outage is a period of downtime counted in a report.
+type Outage struct {
+	// Period is when the outage happened, limited to the reporting range.
+	Period
+
+	// Counted is how much of the outage counted against the target, after
+	// maintenance periods and time outside business hours are left out.
+	Counted time.Duration
+}
+
+// Report holds the service level figures of a reporting range.
+type Report struct {
+	// Range is the reporting range.
+	Range Period
+
+	// Outages are the outages that counted against the target, sorted by
+	// start time. Overlapping downtime periods are merged into one outage.
+	Outages []Outage
+
+	// LongestOutage is the outage with the most counted downtime, or the zero
+	// value if there were none.
+	LongestOutage Outage
+
+	// Target is the availability target, as a percentage.
+	Target float64
+
+	// Availability is the percentage of the measured time the site was up.
+	Availability float64
+
+	// ErrorBudgetConsumed is the fraction of the error budget used by
+	// downtime. It's greater than one when the target was missed.
+	ErrorBudgetConsumed float64
+
+	// Measured is the time covered by the calculation: the reporting range
+	// minus excluded maintenance periods and time outside business hours.
+	Measured time.Duration
+
+	// Downtime is the counted downtime.
+	Downtime time.Duration
+
+	// ErrorBudget is the downtime allowed by the target.
+	ErrorBudget time.Duration
+
+	// ErrorBudgetRemaining is the downtime still allowed before the target is
+	// missed, or zero if it already was.
+	ErrorBudgetRemaining time.Duration
+
+	// MTTR is the mean time to recovery, the average counted duration of the
+	// outages.
+	MTTR time.Duration
+
+	// MTBF is the mean time between failures, the measured uptime divided by
+	// the number of outages.
+	MTBF time.Duration
+}
+
+// Met returns true if the availability target was met.
+func (r *Report) Met() bool {
+	return r.Availability >= r.Target
+}
+
+// Calculate returns the service level figures of a reporting range given the
+// downtime and maintenance periods of a site. Downtime without an end is
+// treated as ongoing until the end of the range, and maintenance periods are
+// only used if Options.ExcludeMaintenance is set.
+func Calculate(rng Period, downtime, maintenance []Period, opts *Options) (*Report, error) {
+	if rng.IsEmpty() {
+		return nil, ErrInvalidRange
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	target := opts.Target
+	if target == 0 {
+		target = DefaultTarget
+	}
+
+	if target < 0 || target > 100 {
+		return nil, ErrInvalidTarget
+	}
+
+	measured := []Period{rng}
+
+	if opts.BusinessHours != nil {
+		if err := opts.BusinessHours.validate(); err != nil {
+			return nil, err
+		}
+
+		measured = opts.BusinessHours.periods(rng)
+	}
+
+	if opts.ExcludeMaintenance {
+		excluded := make([]Period, 0, len(maintenance))
+
+		for _, p := range maintenance {
+			excluded = append(excluded, p.clip(rng))
+		}
+
+		measured = subtract(measured, merge(excluded))
+	}
+
+	down := make([]Period, 0, len(downtime))
+
+	for _, p := range downtime {
+		if p.End.IsZero() {
+			p.End = rng.End
+		}
+
+		down = append(down, p.clip(rng))
+	}
+
+	report := &Report{
+		Range:    rng,
+		Target:   target,
+		Measured: total(measured),
+	}
+
+	for _, p := range merge(down) {
+		counted := total(intersect([]Period{p}, measured))
+		if counted == 0 {
+			continue
+		}
+
+		outage := Outage{
+			Period:  p,
+			Counted: counted,
+		}
+
+		report.Outages = append(report.Outages, outage)
+		report.Downtime += counted
+
+		if counted > report.LongestOutage.Counted {
+			report.LongestOutage = outage
+		}
+	}
+
+	report.Availability = 100
+	if report.Measured > 0 {
+		report.Availability = 100 * float64(report.Measured-report.Downtime) / float64(report.Measured)
+	}
+
+	report.ErrorBudget = time.Duration(math.Round(float64(report.Measured) * (100 - target) / 100))
+	report.ErrorBudgetRemaining = max(0, report.ErrorBudget-report.Downtime)
+
+	switch {
+	case report.ErrorBudget > 0:
+		report.ErrorBudgetConsumed = float64(report.Downtime) / float64(report.ErrorBudget)
+	case report.Downtime > 0:
+		// A target of 100% leaves no budget, so any downtime exhausts it.
+		report.ErrorBudgetConsumed = 1
+	}
+
+	if n := time.Duration(len(report.Outages)); n > 0 {
+		report.MTTR = report.Downtime / n
+		report.MTBF = (report.Measured - report.Downtime) / n
+	}
+
+	return report, nil
+}
+
+// Fetch retrieves the downtime and, if Options.ExcludeMaintenance is set, the
+// maintenance periods of a site through client and returns the service level
+// figures of the reporting range.
+func Fetch(ctx context.Context, client *ohdear.Client, siteID uint, rng Period, opts *Options, reqOpts ...ohdear.RequestOption) (*Report, error) {
+	if client == nil {
+		return nil, ErrClientRequired
+	}
+
+	if rng.IsEmpty() {
+		return nil, ErrInvalidRange
+	}
+
+	downtime, _, err := client.Sites.Downtime(ctx, siteID, rng.Start, rng.End, reqOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch downtime: %w", err)
+	}
+
+	down := make([]Period, 0, len(downtime))
+
+	for _, d := range downtime {
+		down = append(down, Period{Start: d.StartedAt.Time, End: d.EndedAt.Time})
+	}
+
+	var maintenance []Period
+
+	if opts != nil && opts.ExcludeMaintenance {
+		periods, _, err := client.Sites.MaintenancePeriods(ctx, siteID, reqOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch maintenance periods: %w", err)
+		}
+
+		maintenance = make([]Period, 0, len(periods))
+
+		for _, p := range periods {
+			maintenance = append(maintenance, Period{Start: p.StartsAt.Time, End: p.EndsAt.Time})
+		}
+	}
+
+	return Calculate(rng, down, maintenance, opts)
+}
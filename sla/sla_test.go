@@ -0,0 +1,302 @@
+package sla_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+	"git.sr.ht/~jamesponddotco/ohdear-go/sla"
+)
+
+// day returns midnight UTC of the given day of January 2024, which starts on
+// a Monday.
+func day(d int) time.Time {
+	return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalculate(t *testing.T) {
+	t.Parallel()
+
+	// The first week of January 2024, Monday through Sunday.
+	week := sla.Period{Start: day(1), End: day(8)}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("could not load timezone: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		rng          sla.Period
+		downtime     []sla.Period
+		maintenance  []sla.Period
+		opts         *sla.Options
+		wantErr      error
+		wantDowntime time.Duration
+		wantMeasured time.Duration
+		wantOutages  int
+		wantLongest  time.Duration
+		wantMet      bool
+	}{
+		{
+			name:         "No downtime",
+			rng:          week,
+			wantMeasured: 7 * 24 * time.Hour,
+			wantMet:      true,
+		},
+		{
+			name: "Overlapping downtime is merged",
+			rng:  week,
+			downtime: []sla.Period{
+				{Start: day(2).Add(time.Hour), End: day(2).Add(2 * time.Hour)},
+				{Start: day(2).Add(90 * time.Minute), End: day(2).Add(3 * time.Hour)},
+				{Start: day(3), End: day(3).Add(10 * time.Minute)},
+			},
+			wantDowntime: 2*time.Hour + 10*time.Minute,
+			wantMeasured: 7 * 24 * time.Hour,
+			wantOutages:  2,
+			wantLongest:  2 * time.Hour,
+		},
+		{
+			name: "Downtime is clipped to the range",
+			rng:  week,
+			downtime: []sla.Period{
+				{Start: day(1).Add(-time.Hour), End: day(1).Add(5 * time.Minute)},
+				{Start: day(7).Add(23*time.Hour + 55*time.Minute)},
+			},
+			wantDowntime: 10 * time.Minute,
+			wantMeasured: 7 * 24 * time.Hour,
+			wantOutages:  2,
+			wantLongest:  5 * time.Minute,
+			wantMet:      true,
+		},
+		{
+			name: "Maintenance is excluded",
+			rng:  week,
+			downtime: []sla.Period{
+				{Start: day(2), End: day(2).Add(2 * time.Hour)},
+				{Start: day(4), End: day(4).Add(time.Hour)},
+			},
+			maintenance: []sla.Period{
+				{Start: day(2).Add(-time.Hour), End: day(2).Add(90 * time.Minute)},
+				{Start: day(4), End: day(4).Add(time.Hour)},
+			},
+			opts:         &sla.Options{ExcludeMaintenance: true},
+			wantDowntime: 30 * time.Minute,
+			wantMeasured: 7*24*time.Hour - 210*time.Minute,
+			wantOutages:  1,
+			wantLongest:  30 * time.Minute,
+		},
+		{
+			name: "Maintenance is ignored unless excluded",
+			rng:  week,
+			downtime: []sla.Period{
+				{Start: day(4), End: day(4).Add(time.Hour)},
+			},
+			maintenance: []sla.Period{
+				{Start: day(4), End: day(4).Add(time.Hour)},
+			},
+			wantDowntime: time.Hour,
+			wantMeasured: 7 * 24 * time.Hour,
+			wantOutages:  1,
+			wantLongest:  time.Hour,
+		},
+		{
+			name: "Business hours",
+			rng:  week,
+			downtime: []sla.Period{
+				// Monday night, outside business hours.
+				{Start: day(1).Add(20 * time.Hour), End: day(1).Add(22 * time.Hour)},
+				// Tuesday, partly inside business hours.
+				{Start: day(2).Add(16 * time.Hour), End: day(2).Add(18 * time.Hour)},
+				// Saturday, outside business days.
+				{Start: day(6).Add(10 * time.Hour), End: day(6).Add(12 * time.Hour)},
+			},
+			opts:         &sla.Options{BusinessHours: &sla.BusinessHours{}},
+			wantDowntime: time.Hour,
+			wantMeasured: 5 * 8 * time.Hour,
+			wantOutages:  1,
+			wantLongest:  time.Hour,
+		},
+		{
+			name: "Business hours in another timezone",
+			rng:  week,
+			downtime: []sla.Period{
+				// 08:00 to 09:00 UTC is 09:00 to 10:00 in Berlin.
+				{Start: day(2).Add(8 * time.Hour), End: day(2).Add(9 * time.Hour)},
+			},
+			opts: &sla.Options{
+				BusinessHours: &sla.BusinessHours{Location: berlin},
+				Target:        99,
+			},
+			wantDowntime: time.Hour,
+			wantMeasured: 5 * 8 * time.Hour,
+			wantOutages:  1,
+			wantLongest:  time.Hour,
+		},
+		{
+			name:    "Invalid range",
+			rng:     sla.Period{Start: day(2), End: day(1)},
+			wantErr: sla.ErrInvalidRange,
+		},
+		{
+			name:    "Invalid target",
+			rng:     week,
+			opts:    &sla.Options{Target: 101},
+			wantErr: sla.ErrInvalidTarget,
+		},
+		{
+			name: "Invalid business hours",
+			rng:  week,
+			opts: &sla.Options{BusinessHours: &sla.BusinessHours{
+				Start: 17 * time.Hour,
+				End:   9 * time.Hour,
+			}},
+			wantErr: sla.ErrInvalidBusinessHours,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := sla.Calculate(tt.rng, tt.downtime, tt.maintenance, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Calculate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if report.Downtime != tt.wantDowntime {
+				t.Errorf("Downtime = %v, want %v", report.Downtime, tt.wantDowntime)
+			}
+
+			if report.Measured != tt.wantMeasured {
+				t.Errorf("Measured = %v, want %v", report.Measured, tt.wantMeasured)
+			}
+
+			if len(report.Outages) != tt.wantOutages {
+				t.Errorf("len(Outages) = %d, want %d", len(report.Outages), tt.wantOutages)
+			}
+
+			if report.LongestOutage.Counted != tt.wantLongest {
+				t.Errorf("LongestOutage.Counted = %v, want %v", report.LongestOutage.Counted, tt.wantLongest)
+			}
+
+			if report.Met() != tt.wantMet {
+				t.Errorf("Met() = %t, want %t (availability %f%%)", report.Met(), tt.wantMet, report.Availability)
+			}
+		})
+	}
+}
+
+func TestCalculate_Figures(t *testing.T) {
+	t.Parallel()
+
+	// 30 days with a 99.9% target allow 43m12s of downtime.
+	rng := sla.Period{Start: day(1), End: day(31)}
+
+	downtime := []sla.Period{
+		{Start: day(5), End: day(5).Add(20 * time.Minute)},
+		{Start: day(20), End: day(20).Add(40 * time.Minute)},
+	}
+
+	report, err := sla.Calculate(rng, downtime, nil, nil)
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	if want := 43*time.Minute + 12*time.Second; report.ErrorBudget != want {
+		t.Errorf("ErrorBudget = %v, want %v", report.ErrorBudget, want)
+	}
+
+	if report.ErrorBudgetRemaining != 0 {
+		t.Errorf("ErrorBudgetRemaining = %v, want 0", report.ErrorBudgetRemaining)
+	}
+
+	if want := 60.0 / 43.2; math.Abs(report.ErrorBudgetConsumed-want) > 1e-9 {
+		t.Errorf("ErrorBudgetConsumed = %f, want %f", report.ErrorBudgetConsumed, want)
+	}
+
+	if want := 100 * (1 - 60.0/(30*24*60)); math.Abs(report.Availability-want) > 1e-9 {
+		t.Errorf("Availability = %f, want %f", report.Availability, want)
+	}
+
+	if want := 30 * time.Minute; report.MTTR != want {
+		t.Errorf("MTTR = %v, want %v", report.MTTR, want)
+	}
+
+	if want := (30*24*time.Hour - time.Hour) / 2; report.MTBF != want {
+		t.Errorf("MTBF = %v, want %v", report.MTBF, want)
+	}
+
+	if !report.LongestOutage.Start.Equal(day(20)) {
+		t.Errorf("LongestOutage.Start = %v, want %v", report.LongestOutage.Start, day(20))
+	}
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	site := srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+
+	srv.SetDowntime(site.ID, []ohdeartest.Downtime{
+		{
+			StartedAt: jsonutil.Time{Time: day(2)},
+			EndedAt:   jsonutil.Time{Time: day(2).Add(time.Hour)},
+		},
+		{
+			StartedAt: jsonutil.Time{Time: day(3)},
+			EndedAt:   jsonutil.Time{Time: day(3).Add(time.Hour)},
+		},
+		// Outside the reporting range.
+		{
+			StartedAt: jsonutil.Time{Time: day(20)},
+			EndedAt:   jsonutil.Time{Time: day(20).Add(time.Hour)},
+		},
+	})
+
+	srv.AddMaintenancePeriod(ohdeartest.MaintenancePeriod{
+		StartsAt: jsonutil.Time{Time: day(3)},
+		EndsAt:   jsonutil.Time{Time: day(3).Add(time.Hour)},
+		SiteID:   site.ID,
+	})
+
+	var (
+		ctx    = context.Background()
+		client = srv.Client()
+		rng    = sla.Period{Start: day(1), End: day(8)}
+	)
+
+	report, err := sla.Fetch(ctx, client, uint(site.ID), rng, &sla.Options{ExcludeMaintenance: true})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if report.Downtime != time.Hour || len(report.Outages) != 1 {
+		t.Errorf("Fetch() downtime = %v in %d outages, want 1h in 1 outage", report.Downtime, len(report.Outages))
+	}
+
+	if want := 7*24*time.Hour - time.Hour; report.Measured != want {
+		t.Errorf("Fetch() measured = %v, want %v", report.Measured, want)
+	}
+
+	_, err = sla.Fetch(ctx, client, 999, rng, nil)
+
+	var apiErr *ohdear.APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("Fetch() with unknown site error = %v, want *ohdear.APIError", err)
+	}
+}
@@ -0,0 +1,393 @@
+package ohdear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+)
+
+// StatusPagesService handles communication with the /status-pages endpoint of
+// Oh Dear's API.
+type StatusPagesService service
+
+// StatusPageSeverity is the severity of a status page update, which sets how
+// it's highlighted on the status page.
+type StatusPageSeverity string
+
+// Severities supported by the API.
+const (
+	StatusPageSeverityInfo      StatusPageSeverity = "info"
+	StatusPageSeverityWarning   StatusPageSeverity = "warning"
+	StatusPageSeverityHigh      StatusPageSeverity = "high"
+	StatusPageSeverityResolved  StatusPageSeverity = "resolved"
+	StatusPageSeverityScheduled StatusPageSeverity = "scheduled"
+)
+
+// Known returns true if s is one of the severities defined by this package.
+func (s StatusPageSeverity) Known() bool {
+	switch s {
+	case StatusPageSeverityInfo,
+		StatusPageSeverityWarning,
+		StatusPageSeverityHigh,
+		StatusPageSeverityResolved,
+		StatusPageSeverityScheduled:
+		return true
+	default:
+		return false
+	}
+}
+
+type StatusPages struct {
+	Data []StatusPage `json:"data"`
+	Pagination
+}
+
+// StatusPage represents a public status page showing the status of some of
+// the sites of a team.
+type StatusPage struct {
+	Title            string `json:"title,omitempty"`
+	Slug             string `json:"slug,omitempty"`
+	SummarizedStatus string `json:"summarized_status,omitempty"`
+	SiteIDs          []int  `json:"site_ids,omitempty"`
+	ID               int    `json:"id,omitempty"`
+	TeamID           int    `json:"team_id,omitempty"`
+}
+
+type StatusPageUpdates struct {
+	Data []StatusPageUpdate `json:"data"`
+	Pagination
+}
+
+// StatusPageUpdate represents a message posted to a status page, such as the
+// announcement of an incident or its resolution. Time defaults to the moment
+// the update is added.
+type StatusPageUpdate struct {
+	Time         jsonutil.Time      `json:"time,omitempty"`
+	Title        string             `json:"title,omitempty"`
+	Text         string             `json:"text,omitempty"`
+	Severity     StatusPageSeverity `json:"severity,omitempty"`
+	ID           int                `json:"id,omitempty"`
+	StatusPageID int                `json:"status_page_id,omitempty"`
+	Pinned       bool               `json:"pinned,omitempty"`
+}
+
+// List returns a list of the status pages in your account.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-pages
+func (s *StatusPagesService) List(ctx context.Context, page uint, opts ...RequestOption) (*StatusPages, *Pagination, *Response, error) {
+	if ctx == nil {
+		return nil, nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.List")
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPages
+
+	if page > 1 {
+		path += "?page[number]=" + strconv.Itoa(int(page))
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var pages StatusPages
+	if err := json.Unmarshal(ret.Body, &pages); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not unmarshal status pages: %w", err)
+	}
+
+	return &pages, &pages.Pagination, ret, nil
+}
+
+// Get returns a single status page by ID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-pages
+func (s *StatusPagesService) Get(ctx context.Context, id uint, opts ...RequestOption) (*StatusPage, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.Get")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidStatusPageID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPages + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var statusPage StatusPage
+	if err := json.Unmarshal(ret.Body, &statusPage); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal status page: %w", err)
+	}
+
+	return &statusPage, ret, nil
+}
+
+// Add adds a new status page to your account.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-pages
+func (s *StatusPagesService) Add(ctx context.Context, statusPage *StatusPage, opts ...RequestOption) (*StatusPage, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.Add")
+
+	if statusPage == nil {
+		return nil, nil, ErrNilStatusPage
+	}
+
+	if statusPage.TeamID == 0 {
+		return nil, nil, ErrInvalidTeamID
+	}
+
+	payload, err := json.Marshal(statusPage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal status page: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPages
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var addedStatusPage StatusPage
+	if err := json.Unmarshal(ret.Body, &addedStatusPage); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal added status page: %w", err)
+	}
+
+	return &addedStatusPage, ret, nil
+}
+
+// Update updates the settings of a status page by ID. Fields left empty in
+// statusPage are not changed.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-pages
+func (s *StatusPagesService) Update(ctx context.Context, id uint, statusPage *StatusPage, opts ...RequestOption) (*StatusPage, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.Update")
+
+	if id == 0 {
+		return nil, nil, ErrInvalidStatusPageID
+	}
+
+	if statusPage == nil {
+		return nil, nil, ErrNilStatusPage
+	}
+
+	payload, err := json.Marshal(statusPage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal status page: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPages + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var updatedStatusPage StatusPage
+	if err := json.Unmarshal(ret.Body, &updatedStatusPage); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal updated status page: %w", err)
+	}
+
+	return &updatedStatusPage, ret, nil
+}
+
+// Remove removes a status page and its updates from your account.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-pages
+func (s *StatusPagesService) Remove(ctx context.Context, id uint, opts ...RequestOption) (*Response, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.Remove")
+
+	if id == 0 {
+		return nil, ErrInvalidStatusPageID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPages + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Updates returns a list of the updates posted to a status page, newest
+// first.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-page-updates
+func (s *StatusPagesService) Updates(ctx context.Context, id, page uint, opts ...RequestOption) (*StatusPageUpdates, *Pagination, *Response, error) {
+	if ctx == nil {
+		return nil, nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.Updates")
+
+	if id == 0 {
+		return nil, nil, nil, ErrInvalidStatusPageID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPages + "/" + strconv.Itoa(int(id)) + "/updates"
+
+	if page > 1 {
+		path += "?page[number]=" + strconv.Itoa(int(page))
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var updates StatusPageUpdates
+	if err := json.Unmarshal(ret.Body, &updates); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not unmarshal status page updates: %w", err)
+	}
+
+	return &updates, &updates.Pagination, ret, nil
+}
+
+// AddUpdate posts an update to the status page set in update.StatusPageID.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-page-updates
+func (s *StatusPagesService) AddUpdate(ctx context.Context, update *StatusPageUpdate, opts ...RequestOption) (*StatusPageUpdate, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.AddUpdate")
+
+	if update == nil {
+		return nil, nil, ErrNilStatusPage
+	}
+
+	if update.StatusPageID == 0 {
+		return nil, nil, ErrInvalidStatusPageID
+	}
+
+	if !update.Severity.Known() {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidSeverity, update.Severity)
+	}
+
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not marshal status page update: %w", err)
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPageUpdates
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var addedUpdate StatusPageUpdate
+	if err := json.Unmarshal(ret.Body, &addedUpdate); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal added status page update: %w", err)
+	}
+
+	return &addedUpdate, ret, nil
+}
+
+// RemoveUpdate removes an update from its status page.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#status-page-updates
+func (s *StatusPagesService) RemoveUpdate(ctx context.Context, id uint, opts ...RequestOption) (*Response, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "StatusPages.RemoveUpdate")
+
+	if id == 0 {
+		return nil, ErrInvalidStatusPageUpdateID
+	}
+
+	path := s.client.cfg.BaseURL + endpoint.StatusPageUpdates + "/" + strconv.Itoa(int(id))
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,88 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+func TestStatusPagesService(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		srv = ohdeartest.NewServer(nil)
+	)
+
+	defer srv.Close()
+
+	client := srv.Client()
+
+	added, _, err := client.StatusPages.Add(ctx, &ohdear.StatusPage{
+		Title:  "Example",
+		TeamID: 1,
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	got, _, err := client.StatusPages.Get(ctx, uint(added.ID))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.Title != "Example" || got.Slug == "" {
+		t.Errorf("Get() = %+v, want %+v", got, added)
+	}
+
+	pages, _, _, err := client.StatusPages.List(ctx, 1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(pages.Data) != 1 {
+		t.Errorf("List() = %d status pages, want 1", len(pages.Data))
+	}
+
+	update, _, err := client.StatusPages.AddUpdate(ctx, &ohdear.StatusPageUpdate{
+		Title:        "Investigating",
+		Severity:     ohdear.StatusPageSeverityHigh,
+		StatusPageID: added.ID,
+	})
+	if err != nil {
+		t.Fatalf("AddUpdate() error = %v", err)
+	}
+
+	if update.ID == 0 || update.Time.IsZero() {
+		t.Errorf("AddUpdate() = %+v, want an ID and a time", update)
+	}
+
+	updates, _, _, err := client.StatusPages.Updates(ctx, uint(added.ID), 1)
+	if err != nil {
+		t.Fatalf("Updates() error = %v", err)
+	}
+
+	if len(updates.Data) != 1 || updates.Data[0].Severity != ohdear.StatusPageSeverityHigh {
+		t.Errorf("Updates() = %+v, want the added update", updates.Data)
+	}
+
+	if _, err = client.StatusPages.RemoveUpdate(ctx, uint(update.ID)); err != nil {
+		t.Fatalf("RemoveUpdate() error = %v", err)
+	}
+
+	if _, err = client.StatusPages.Remove(ctx, uint(added.ID)); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	_, _, err = client.StatusPages.AddUpdate(ctx, &ohdear.StatusPageUpdate{
+		Title:        "Investigating",
+		Severity:     "critical",
+		StatusPageID: added.ID,
+	})
+	if !errors.Is(err, ohdear.ErrInvalidSeverity) {
+		t.Errorf("AddUpdate() error = %v, want %v", err, ohdear.ErrInvalidSeverity)
+	}
+}
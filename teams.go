@@ -0,0 +1,70 @@
+package ohdear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/endpoint"
+)
+
+// TeamsService handles communication with the /me endpoint of Oh Dear's API,
+// which lists the teams of the owner of the API key.
+type TeamsService service
+
+// User represents the owner of the API key.
+type User struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Teams []Team `json:"teams,omitempty"`
+	ID    int    `json:"id,omitempty"`
+}
+
+// Team represents a team, which owns sites and status pages.
+type Team struct {
+	Name string `json:"name,omitempty"`
+	ID   int    `json:"id,omitempty"`
+}
+
+// Me returns the owner of the API key, along with their teams.
+//
+// [API Reference].
+//
+// [API Reference]: https://ohdear.app/docs/integrations/the-oh-dear-api#me
+func (s *TeamsService) Me(ctx context.Context, opts ...RequestOption) (*User, *Response, error) {
+	if ctx == nil {
+		return nil, nil, ErrNilContext
+	}
+
+	ctx = withOperation(ctx, "Teams.Me")
+
+	path := s.client.cfg.BaseURL + endpoint.Me
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, http.NoBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := s.client.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user User
+	if err := json.Unmarshal(ret.Body, &user); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal user: %w", err)
+	}
+
+	return &user, ret, nil
+}
+
+// List returns the teams of the owner of the API key.
+func (s *TeamsService) List(ctx context.Context, opts ...RequestOption) ([]Team, error) {
+	user, _, err := s.Me(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Teams, nil
+}
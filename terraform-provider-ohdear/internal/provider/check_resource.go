@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time check to ensure checkResource implements the resource
+// interfaces.
+var _ resource.ResourceWithConfigure = (*checkResource)(nil)
+
+// checkResource manages whether a check of a site is enabled. Checks can't
+// be created or removed through the API, so the resource adopts the check of
+// the given type and disables it when it's destroyed.
+type checkResource struct {
+	client *ohdear.Client
+}
+
+// checkModel is the state of a check.
+type checkModel struct {
+	ID      types.String `tfsdk:"id"`
+	SiteID  types.String `tfsdk:"site_id"`
+	Type    types.String `tfsdk:"type"`
+	Label   types.String `tfsdk:"label"`
+	Enabled types.Bool   `tfsdk:"enabled"`
+}
+
+func newCheckResource() resource.Resource {
+	return &checkResource{}
+}
+
+// Metadata implements the resource.Resource interface.
+func (r *checkResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_check"
+}
+
+// Schema implements the resource.Resource interface.
+func (r *checkResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Enables or disables a check of a site, such as uptime or certificate_health. Destroying the resource disables the check.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the check.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"site_id": schema.StringAttribute{
+				Description: "The ID of the site.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The type of the check, such as uptime, broken_links or certificate_health.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether the check is enabled. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"label": schema.StringAttribute{
+				Description: "The label of the check.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Configure implements the resource.ResourceWithConfigure interface.
+func (r *checkResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Create implements the resource.Resource interface.
+func (r *checkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan checkModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	siteID := parseID(plan.SiteID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	site, _, err := r.client.Sites.Get(ctx, siteID)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not read site", err.Error())
+
+		return
+	}
+
+	checkType := ohdear.CheckType(plan.Type.ValueString())
+
+	var check *ohdear.Check
+
+	for i := range site.Checks {
+		if site.Checks[i].Type == checkType {
+			check = &site.Checks[i]
+
+			break
+		}
+	}
+
+	if check == nil {
+		resp.Diagnostics.AddError("Check not found", fmt.Sprintf("Site %d has no %s check.", site.ID, checkType))
+
+		return
+	}
+
+	updated, err := r.setEnabled(ctx, uint(check.ID), plan.Enabled.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Could not update check", err.Error())
+
+		return
+	}
+
+	plan.set(updated)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements the resource.Resource interface.
+func (r *checkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state checkModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	siteID := parseID(state.SiteID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	site, _, err := r.client.Sites.Get(ctx, siteID)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("Could not read site", err.Error())
+
+		return
+	}
+
+	for i := range site.Checks {
+		if formatID(site.Checks[i].ID) == state.ID.ValueString() {
+			state.set(&site.Checks[i])
+
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+
+			return
+		}
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Update implements the resource.Resource interface.
+func (r *checkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan checkModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	id := parseID(plan.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.setEnabled(ctx, id, plan.Enabled.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Could not update check", err.Error())
+
+		return
+	}
+
+	plan.set(updated)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements the resource.Resource interface.
+func (r *checkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state checkModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.setEnabled(ctx, id, false); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Could not disable check", err.Error())
+	}
+}
+
+// setEnabled enables or disables a check.
+func (r *checkResource) setEnabled(ctx context.Context, id uint, enabled bool) (*ohdear.Check, error) {
+	var (
+		check *ohdear.Check
+		err   error
+	)
+
+	if enabled {
+		check, _, err = r.client.Checks.Enable(ctx, id)
+	} else {
+		check, _, err = r.client.Checks.Disable(ctx, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return check, nil
+}
+
+// set stores the check returned by the API in the model.
+func (m *checkModel) set(check *ohdear.Check) {
+	m.ID = types.StringValue(formatID(check.ID))
+	m.Type = types.StringValue(string(check.Type))
+	m.Label = types.StringValue(check.Label)
+	m.Enabled = types.BoolValue(check.Enabled)
+}
@@ -0,0 +1,265 @@
+package provider
+
+import (
+	"context"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time check to ensure cronCheckResource implements the resource
+// interfaces.
+var _ resource.ResourceWithConfigure = (*cronCheckResource)(nil)
+
+// cronCheckResource manages a cron check.
+type cronCheckResource struct {
+	client *ohdear.Client
+}
+
+// cronCheckModel is the state of a cron check.
+type cronCheckModel struct {
+	ID                 types.String `tfsdk:"id"`
+	SiteID             types.String `tfsdk:"site_id"`
+	Name               types.String `tfsdk:"name"`
+	Type               types.String `tfsdk:"type"`
+	CronExpression     types.String `tfsdk:"cron_expression"`
+	Description        types.String `tfsdk:"description"`
+	ServerTimezone     types.String `tfsdk:"server_timezone"`
+	UUID               types.String `tfsdk:"uuid"`
+	PingURL            types.String `tfsdk:"ping_url"`
+	FrequencyInMinutes types.Int64  `tfsdk:"frequency_in_minutes"`
+	GraceTimeInMinutes types.Int64  `tfsdk:"grace_time_in_minutes"`
+}
+
+func newCronCheckResource() resource.Resource {
+	return &cronCheckResource{}
+}
+
+// Metadata implements the resource.Resource interface.
+func (r *cronCheckResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cron_check"
+}
+
+// Schema implements the resource.Resource interface.
+func (r *cronCheckResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	computed := []planmodifier.String{
+		stringplanmodifier.UseStateForUnknown(),
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "A scheduled task monitored by Oh Dear, which pings ping_url every time it runs.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description:   "The ID of the cron check.",
+				Computed:      true,
+				PlanModifiers: computed,
+			},
+			"site_id": schema.StringAttribute{
+				Description: "The ID of the site.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the cron check.",
+				Required:    true,
+			},
+			"type": schema.StringAttribute{
+				Description: "Either simple, which expects a ping every frequency_in_minutes, or cron, which expects a ping on the schedule of cron_expression.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(ohdear.CronCheckTypeSimple), string(ohdear.CronCheckTypeCron)),
+				},
+			},
+			"frequency_in_minutes": schema.Int64Attribute{
+				Description: "How often a ping is expected, for simple checks.",
+				Optional:    true,
+			},
+			"cron_expression": schema.StringAttribute{
+				Description: "The schedule pings are expected on, for cron checks.",
+				Optional:    true,
+			},
+			"grace_time_in_minutes": schema.Int64Attribute{
+				Description: "How late a ping can be before Oh Dear notifies.",
+				Optional:    true,
+			},
+			"server_timezone": schema.StringAttribute{
+				Description: "The timezone cron_expression is interpreted in.",
+				Optional:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "A description of the task.",
+				Optional:    true,
+			},
+			"uuid": schema.StringAttribute{
+				Description:   "The UUID of the cron check.",
+				Computed:      true,
+				PlanModifiers: computed,
+			},
+			"ping_url": schema.StringAttribute{
+				Description:   "The URL the task must ping when it runs.",
+				Computed:      true,
+				PlanModifiers: computed,
+			},
+		},
+	}
+}
+
+// Configure implements the resource.ResourceWithConfigure interface.
+func (r *cronCheckResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Create implements the resource.Resource interface.
+func (r *cronCheckResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan cronCheckModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	siteID := parseID(plan.SiteID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	added, _, err := r.client.CronChecks.Add(ctx, siteID, plan.check())
+	if err != nil {
+		resp.Diagnostics.AddError("Could not add cron check", err.Error())
+
+		return
+	}
+
+	plan.set(added)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements the resource.Resource interface.
+func (r *cronCheckResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state cronCheckModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	siteID := parseID(state.SiteID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checks, _, err := r.client.CronChecks.List(ctx, siteID)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("Could not read cron checks", err.Error())
+
+		return
+	}
+
+	for i := range checks {
+		if formatID(checks[i].ID) == state.ID.ValueString() {
+			state.set(&checks[i])
+
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+
+			return
+		}
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Update implements the resource.Resource interface.
+func (r *cronCheckResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan cronCheckModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	id := parseID(plan.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, _, err := r.client.CronChecks.Update(ctx, id, plan.check())
+	if err != nil {
+		resp.Diagnostics.AddError("Could not update cron check", err.Error())
+
+		return
+	}
+
+	plan.set(updated)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements the resource.Resource interface.
+func (r *cronCheckResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state cronCheckModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.client.CronChecks.Remove(ctx, id); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Could not remove cron check", err.Error())
+	}
+}
+
+// check returns the cron check described by the model.
+func (m *cronCheckModel) check() *ohdear.CronCheck {
+	return &ohdear.CronCheck{
+		Name:               m.Name.ValueString(),
+		Type:               ohdear.CronCheckType(m.Type.ValueString()),
+		CronExpression:     m.CronExpression.ValueString(),
+		Description:        m.Description.ValueString(),
+		ServerTimezone:     m.ServerTimezone.ValueString(),
+		FrequencyInMinutes: int(m.FrequencyInMinutes.ValueInt64()),
+		GraceTimeInMinutes: int(m.GraceTimeInMinutes.ValueInt64()),
+	}
+}
+
+// set stores the cron check returned by the API in the model. Optional
+// attributes the API leaves empty are kept null.
+func (m *cronCheckModel) set(check *ohdear.CronCheck) {
+	m.ID = types.StringValue(formatID(check.ID))
+	m.Name = types.StringValue(check.Name)
+	m.Type = types.StringValue(string(check.Type))
+	m.UUID = types.StringValue(check.UUID)
+	m.PingURL = types.StringValue(check.PingURL)
+	m.CronExpression = optionalString(check.CronExpression)
+	m.Description = optionalString(check.Description)
+	m.ServerTimezone = optionalString(check.ServerTimezone)
+	m.FrequencyInMinutes = optionalInt64(check.FrequencyInMinutes)
+	m.GraceTimeInMinutes = optionalInt64(check.GraceTimeInMinutes)
+}
+
+// optionalString returns the state value of an optional string attribute,
+// which is null when empty.
+func optionalString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(value)
+}
+
+// optionalInt64 returns the state value of an optional number attribute,
+// which is null when zero.
+func optionalInt64(value int) types.Int64 {
+	if value == 0 {
+		return types.Int64Null()
+	}
+
+	return types.Int64Value(int64(value))
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time check to ensure maintenancePeriodResource implements the
+// resource interfaces.
+var _ resource.ResourceWithConfigure = (*maintenancePeriodResource)(nil)
+
+// maintenancePeriodResource manages a scheduled maintenance period. The API
+// can't change maintenance periods, so every change replaces them.
+type maintenancePeriodResource struct {
+	client *ohdear.Client
+}
+
+// maintenancePeriodModel is the state of a maintenance period.
+type maintenancePeriodModel struct {
+	ID       types.String `tfsdk:"id"`
+	SiteID   types.String `tfsdk:"site_id"`
+	StartsAt types.String `tfsdk:"starts_at"`
+	EndsAt   types.String `tfsdk:"ends_at"`
+}
+
+func newMaintenancePeriodResource() resource.Resource {
+	return &maintenancePeriodResource{}
+}
+
+// Metadata implements the resource.Resource interface.
+func (r *maintenancePeriodResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_maintenance_period"
+}
+
+// Schema implements the resource.Resource interface.
+func (r *maintenancePeriodResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{
+		stringplanmodifier.RequiresReplace(),
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "A scheduled maintenance period of a site, during which its checks don't send notifications.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the maintenance period.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"site_id": schema.StringAttribute{
+				Description:   "The ID of the site.",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"starts_at": schema.StringAttribute{
+				Description:   "When the maintenance period starts, in RFC 3339 format.",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"ends_at": schema.StringAttribute{
+				Description:   "When the maintenance period ends, in RFC 3339 format.",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+		},
+	}
+}
+
+// Configure implements the resource.ResourceWithConfigure interface.
+func (r *maintenancePeriodResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Create implements the resource.Resource interface.
+func (r *maintenancePeriodResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan maintenancePeriodModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	var (
+		siteID   = parseID(plan.SiteID.ValueString(), &resp.Diagnostics)
+		startsAt = parseTime(path.Root("starts_at"), plan.StartsAt.ValueString(), &resp.Diagnostics)
+		endsAt   = parseTime(path.Root("ends_at"), plan.EndsAt.ValueString(), &resp.Diagnostics)
+	)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	period := &ohdear.MaintenancePeriod{
+		SiteID: int(siteID),
+	}
+
+	period.StartsAt.Time = startsAt
+	period.EndsAt.Time = endsAt
+
+	added, _, err := r.client.MaintenancePeriods.Add(ctx, period)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not add maintenance period", err.Error())
+
+		return
+	}
+
+	plan.ID = types.StringValue(formatID(added.ID))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements the resource.Resource interface.
+func (r *maintenancePeriodResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state maintenancePeriodModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	siteID := parseID(state.SiteID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	periods, _, err := r.client.Sites.MaintenancePeriods(ctx, siteID)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("Could not read maintenance periods", err.Error())
+
+		return
+	}
+
+	for i := range periods {
+		if formatID(periods[i].ID) != state.ID.ValueString() {
+			continue
+		}
+
+		// The configured times are kept when they're the same instant, so
+		// offsets other than UTC don't show up as changes.
+		state.StartsAt = timeValue(state.StartsAt, periods[i].StartsAt.Time)
+		state.EndsAt = timeValue(state.EndsAt, periods[i].EndsAt.Time)
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Update implements the resource.Resource interface. Every attribute requires
+// replacement, so it's never called.
+func (r *maintenancePeriodResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError("Maintenance periods can't be updated", "Every change replaces the maintenance period.")
+}
+
+// Delete implements the resource.Resource interface.
+func (r *maintenancePeriodResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state maintenancePeriodModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.client.MaintenancePeriods.Remove(ctx, id); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Could not remove maintenance period", err.Error())
+	}
+}
+
+// parseTime parses an RFC 3339 time set in the configuration.
+func parseTime(attr path.Path, value string, diags *diag.Diagnostics) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		diags.AddAttributeError(attr, "Invalid time", "Expected a time in RFC 3339 format, such as 2024-01-02T03:00:00Z.")
+	}
+
+	return t
+}
+
+// timeValue returns the state value of a time returned by the API, keeping
+// the current value if it's the same instant.
+func timeValue(current types.String, t time.Time) types.String {
+	if parsed, err := time.Parse(time.RFC3339, current.ValueString()); err == nil && parsed.Equal(t) {
+		return current
+	}
+
+	return types.StringValue(t.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time check to ensure notificationDestinationResource implements the
+// resource interfaces.
+var _ resource.ResourceWithConfigure = (*notificationDestinationResource)(nil)
+
+// notificationDestinationResource manages a notification destination of a
+// site. The API can't change destinations, so every change replaces them.
+type notificationDestinationResource struct {
+	client *ohdear.Client
+}
+
+// notificationDestinationModel is the state of a notification destination.
+type notificationDestinationModel struct {
+	ID          types.String `tfsdk:"id"`
+	SiteID      types.String `tfsdk:"site_id"`
+	Channel     types.String `tfsdk:"channel"`
+	Destination types.Map    `tfsdk:"destination"`
+}
+
+func newNotificationDestinationResource() resource.Resource {
+	return &notificationDestinationResource{}
+}
+
+// Metadata implements the resource.Resource interface.
+func (r *notificationDestinationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_notification_destination"
+}
+
+// Schema implements the resource.Resource interface.
+func (r *notificationDestinationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A channel the notifications about a site are sent to.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the notification destination.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"site_id": schema.StringAttribute{
+				Description: "The ID of the site.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"channel": schema.StringAttribute{
+				Description: "The channel notifications are sent through, such as mail, slack or webhook.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"destination": schema.MapAttribute{
+				Description: "The settings of the channel, such as mail for mail or url for webhooks.",
+				ElementType: types.StringType,
+				Required:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+// Configure implements the resource.ResourceWithConfigure interface.
+func (r *notificationDestinationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Create implements the resource.Resource interface.
+func (r *notificationDestinationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan notificationDestinationModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	siteID := parseID(plan.SiteID.ValueString(), &resp.Diagnostics)
+
+	destination := &ohdear.NotificationDestination{
+		Channel: plan.Channel.ValueString(),
+	}
+
+	resp.Diagnostics.Append(plan.Destination.ElementsAs(ctx, &destination.Destination, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	added, _, err := r.client.NotificationDestinations.Add(ctx, siteID, destination)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not add notification destination", err.Error())
+
+		return
+	}
+
+	plan.ID = types.StringValue(formatID(added.ID))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements the resource.Resource interface.
+func (r *notificationDestinationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state notificationDestinationModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	siteID := parseID(state.SiteID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	destinations, _, err := r.client.NotificationDestinations.List(ctx, siteID)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("Could not read notification destinations", err.Error())
+
+		return
+	}
+
+	for i := range destinations {
+		if formatID(destinations[i].ID) != state.ID.ValueString() {
+			continue
+		}
+
+		destination, d := types.MapValueFrom(ctx, types.StringType, destinations[i].Destination)
+		resp.Diagnostics.Append(d...)
+
+		state.Channel = types.StringValue(destinations[i].Channel)
+		state.Destination = destination
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Update implements the resource.Resource interface. Every attribute requires
+// replacement, so it's never called.
+func (r *notificationDestinationResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError("Notification destinations can't be updated", "Every change replaces the notification destination.")
+}
+
+// Delete implements the resource.Resource interface.
+func (r *notificationDestinationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state notificationDestinationModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	var (
+		siteID = parseID(state.SiteID.ValueString(), &resp.Diagnostics)
+		id     = parseID(state.ID.ValueString(), &resp.Diagnostics)
+	)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.client.NotificationDestinations.Remove(ctx, siteID, id); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Could not remove notification destination", err.Error())
+	}
+}
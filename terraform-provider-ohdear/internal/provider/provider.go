@@ -0,0 +1,151 @@
+// Package provider implements the Oh Dear Terraform provider on top of the
+// services of the ohdear package.
+//
+// The acceptance tests run against the fake API of the ohdeartest package
+// when TF_ACC is set, so they need a Terraform binary but no Oh Dear account:
+//
+//	TF_ACC=1 go test ./...
+package provider
+
+import (
+	"context"
+	"os"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Environment variables read when the provider configuration leaves the
+// matching attribute unset.
+const (
+	EnvAPIToken string = "OHDEAR_API_TOKEN"
+	EnvBaseURL  string = "OHDEAR_BASE_URL"
+)
+
+// _contact is the contact information sent in the user agent of the
+// provider.
+const _contact string = "https://git.sr.ht/~jamesponddotco/ohdear-go"
+
+// Compile-time check to ensure ohdearProvider implements provider.Provider.
+var _ provider.Provider = (*ohdearProvider)(nil)
+
+// ohdearProvider is the Oh Dear provider.
+type ohdearProvider struct {
+	// version is the version of the provider, sent in the user agent.
+	version string
+}
+
+// providerModel is the configuration of the provider.
+type providerModel struct {
+	APIToken types.String `tfsdk:"api_token"`
+	BaseURL  types.String `tfsdk:"base_url"`
+}
+
+// New returns a function creating the provider, as expected by
+// providerserver.Serve.
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &ohdearProvider{
+			version: version,
+		}
+	}
+}
+
+// Metadata implements the provider.Provider interface.
+func (p *ohdearProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "ohdear"
+	resp.Version = p.version
+}
+
+// Schema implements the provider.Provider interface.
+func (p *ohdearProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages Oh Dear sites, checks, maintenance periods, cron checks, status pages and notification destinations.",
+		Attributes: map[string]schema.Attribute{
+			"api_token": schema.StringAttribute{
+				Description: "The Oh Dear API token. Defaults to the " + EnvAPIToken + " environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"base_url": schema.StringAttribute{
+				Description: "The base URL of the Oh Dear API. Defaults to the " + EnvBaseURL + " environment variable, then to " + ohdear.DefaultBaseURL + ".",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// Configure implements the provider.Provider interface.
+func (p *ohdearProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var cfg providerModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token := os.Getenv(EnvAPIToken)
+	if !cfg.APIToken.IsNull() && !cfg.APIToken.IsUnknown() {
+		token = cfg.APIToken.ValueString()
+	}
+
+	if token == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_token"),
+			"Missing Oh Dear API token",
+			"Set the api_token attribute or the "+EnvAPIToken+" environment variable.",
+		)
+
+		return
+	}
+
+	clientCfg := ohdear.NewConfig(token, &ohdear.Application{
+		Name:    "terraform-provider-ohdear",
+		Version: p.version,
+		Contact: _contact,
+	})
+
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		clientCfg.BaseURL = baseURL
+	}
+
+	if !cfg.BaseURL.IsNull() && !cfg.BaseURL.IsUnknown() {
+		clientCfg.BaseURL = cfg.BaseURL.ValueString()
+	}
+
+	client, err := ohdear.NewClient(clientCfg)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not create Oh Dear client", err.Error())
+
+		return
+	}
+
+	resp.DataSourceData = client
+	resp.ResourceData = client
+}
+
+// Resources implements the provider.Provider interface.
+func (p *ohdearProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newSiteResource,
+		newCheckResource,
+		newMaintenancePeriodResource,
+		newCronCheckResource,
+		newStatusPageResource,
+		newNotificationDestinationResource,
+	}
+}
+
+// DataSources implements the provider.Provider interface.
+func (p *ohdearProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		newTeamsDataSource,
+		newSitesDataSource,
+	}
+}
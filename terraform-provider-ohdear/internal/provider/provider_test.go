@@ -0,0 +1,371 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+	"git.sr.ht/~jamesponddotco/ohdear-go/terraform-provider-ohdear/internal/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// providerFactories returns the provider factories of acceptance tests.
+func providerFactories() map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"ohdear": providerserver.NewProtocol6WithError(provider.New("test")()),
+	}
+}
+
+// providerConfig returns the configuration of a provider talking to the fake
+// server.
+func providerConfig(srv *ohdeartest.Server) string {
+	return fmt.Sprintf(`
+provider "ohdear" {
+  api_token = %q
+  base_url  = %q
+}
+`, ohdeartest.DefaultKey, srv.URL)
+}
+
+func TestProvider_Schema(t *testing.T) {
+	t.Parallel()
+
+	server, err := providerserver.NewProtocol6WithError(provider.New("test")())()
+	if err != nil {
+		t.Fatalf("NewProtocol6WithError() error = %v", err)
+	}
+
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema() error = %v", err)
+	}
+
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Errorf("GetProviderSchema() diagnostic = %s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	resources := []string{
+		"ohdear_site",
+		"ohdear_check",
+		"ohdear_maintenance_period",
+		"ohdear_cron_check",
+		"ohdear_status_page",
+		"ohdear_notification_destination",
+	}
+
+	for _, name := range resources {
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("resource %s is missing", name)
+		}
+	}
+
+	for _, name := range []string{"ohdear_teams", "ohdear_sites"} {
+		if _, ok := resp.DataSourceSchemas[name]; !ok {
+			t.Errorf("data source %s is missing", name)
+		}
+	}
+}
+
+func TestAccSiteResource(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+  tags    = ["production"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ohdear_site.example", "id"),
+					resource.TestCheckResourceAttr("ohdear_site.example", "label", "example.com"),
+					resource.TestCheckResourceAttr("ohdear_site.example", "tags.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "ohdear_site.example",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url        = "https://www.example.com"
+  team_id    = 1
+  label      = "Example"
+  group_name = "Marketing"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ohdear_site.example", "url", "https://www.example.com"),
+					resource.TestCheckResourceAttr("ohdear_site.example", "label", "Example"),
+					resource.TestCheckResourceAttr("ohdear_site.example", "group_name", "Marketing"),
+					resource.TestCheckNoResourceAttr("ohdear_site.example", "tags"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCheckResource(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_check" "broken_links" {
+  site_id = ohdear_site.example.id
+  type    = "broken_links"
+  enabled = false
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ohdear_check.broken_links", "id"),
+					resource.TestCheckResourceAttr("ohdear_check.broken_links", "enabled", "false"),
+				),
+			},
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_check" "broken_links" {
+  site_id = ohdear_site.example.id
+  type    = "broken_links"
+}
+`,
+				Check: resource.TestCheckResourceAttr("ohdear_check.broken_links", "enabled", "true"),
+			},
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_check" "missing" {
+  site_id = ohdear_site.example.id
+  type    = "cron"
+}
+`,
+				ExpectError: regexp.MustCompile("Check not found"),
+			},
+		},
+	})
+}
+
+func TestAccMaintenancePeriodResource(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_maintenance_period" "deploy" {
+  site_id   = ohdear_site.example.id
+  starts_at = "2030-01-02T05:00:00+02:00"
+  ends_at   = "2030-01-02T06:00:00+02:00"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ohdear_maintenance_period.deploy", "id"),
+					resource.TestCheckResourceAttr("ohdear_maintenance_period.deploy", "starts_at", "2030-01-02T05:00:00+02:00"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCronCheckResource(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_cron_check" "backup" {
+  site_id              = ohdear_site.example.id
+  name                 = "backup"
+  type                 = "simple"
+  frequency_in_minutes = 60
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ohdear_cron_check.backup", "ping_url"),
+					resource.TestCheckResourceAttr("ohdear_cron_check.backup", "frequency_in_minutes", "60"),
+				),
+			},
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_cron_check" "backup" {
+  site_id         = ohdear_site.example.id
+  name            = "backup"
+  type            = "cron"
+  cron_expression = "0 3 * * *"
+  server_timezone = "Europe/Brussels"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ohdear_cron_check.backup", "cron_expression", "0 3 * * *"),
+					resource.TestCheckNoResourceAttr("ohdear_cron_check.backup", "frequency_in_minutes"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccStatusPageResource(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_status_page" "public" {
+  team_id  = 1
+  title    = "Example status"
+  site_ids = [ohdear_site.example.id]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ohdear_status_page.public", "slug"),
+					resource.TestCheckResourceAttr("ohdear_status_page.public", "site_ids.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "ohdear_status_page.public",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_status_page" "public" {
+  team_id  = 1
+  title    = "Example"
+  site_ids = [ohdear_site.example.id]
+}
+`,
+				Check: resource.TestCheckResourceAttr("ohdear_status_page.public", "title", "Example"),
+			},
+		},
+	})
+}
+
+func TestAccNotificationDestinationResource(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "example" {
+  url     = "https://example.com"
+  team_id = 1
+}
+
+resource "ohdear_notification_destination" "ops" {
+  site_id     = ohdear_site.example.id
+  channel     = "mail"
+  destination = {
+    mail = "ops@example.com"
+  }
+}
+`,
+				Check: resource.TestCheckResourceAttrSet("ohdear_notification_destination.ops", "id"),
+			},
+		},
+	})
+}
+
+func TestAccDataSources(t *testing.T) {
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig(srv) + `
+resource "ohdear_site" "production" {
+  url     = "https://example.com"
+  team_id = 1
+  tags    = ["production"]
+}
+
+resource "ohdear_site" "staging" {
+  url     = "https://staging.example.com"
+  team_id = 1
+}
+
+data "ohdear_teams" "all" {}
+
+data "ohdear_sites" "production" {
+  tag = "production"
+
+  depends_on = [ohdear_site.production, ohdear_site.staging]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ohdear_teams.all", "teams.#", "1"),
+					resource.TestCheckResourceAttr("data.ohdear_teams.all", "teams.0.name", ohdeartest.DefaultTeamName),
+					resource.TestCheckResourceAttr("data.ohdear_sites.production", "sites.#", "1"),
+					resource.TestCheckResourceAttr("data.ohdear_sites.production", "sites.0.url", "https://example.com"),
+				),
+			},
+		},
+	})
+}
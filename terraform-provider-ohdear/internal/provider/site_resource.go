@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time checks to ensure siteResource implements the resource
+// interfaces.
+var (
+	_ resource.ResourceWithConfigure   = (*siteResource)(nil)
+	_ resource.ResourceWithImportState = (*siteResource)(nil)
+)
+
+// siteResource manages a site.
+type siteResource struct {
+	client *ohdear.Client
+}
+
+// siteModel is the state of a site.
+type siteModel struct {
+	ID           types.String `tfsdk:"id"`
+	URL          types.String `tfsdk:"url"`
+	Label        types.String `tfsdk:"label"`
+	GroupName    types.String `tfsdk:"group_name"`
+	FriendlyName types.String `tfsdk:"friendly_name"`
+	SortURL      types.String `tfsdk:"sort_url"`
+	Tags         types.Set    `tfsdk:"tags"`
+	TeamID       types.Int64  `tfsdk:"team_id"`
+}
+
+func newSiteResource() resource.Resource {
+	return &siteResource{}
+}
+
+// Metadata implements the resource.Resource interface.
+func (r *siteResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_site"
+}
+
+// Schema implements the resource.Resource interface.
+func (r *siteResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A site monitored by Oh Dear. Oh Dear enables its default checks when the site is added; use ohdear_check to enable or disable them.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the site.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"url": schema.StringAttribute{
+				Description: "The URL of the site.",
+				Required:    true,
+			},
+			"team_id": schema.Int64Attribute{
+				Description: "The ID of the team owning the site. Changing it replaces the site.",
+				Required:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"label": schema.StringAttribute{
+				Description: "The label of the site. Defaults to its URL.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group_name": schema.StringAttribute{
+				Description: "The group the site is shown in.",
+				Optional:    true,
+			},
+			"friendly_name": schema.StringAttribute{
+				Description: "A friendly name for the site, used in notifications.",
+				Optional:    true,
+			},
+			"tags": schema.SetAttribute{
+				Description: "The tags of the site.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"sort_url": schema.StringAttribute{
+				Description: "The URL Oh Dear sorts and deduplicates sites by, derived from url.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure implements the resource.ResourceWithConfigure interface.
+func (r *siteResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Create implements the resource.Resource interface.
+func (r *siteResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan siteModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	site := plan.site(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	site.TeamID = int(plan.TeamID.ValueInt64())
+
+	added, _, err := r.client.Sites.Add(ctx, site)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not add site", err.Error())
+
+		return
+	}
+
+	plan.set(ctx, added, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements the resource.Resource interface.
+func (r *siteResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state siteModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	site, _, err := r.client.Sites.Get(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("Could not read site", err.Error())
+
+		return
+	}
+
+	state.set(ctx, site, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update implements the resource.Resource interface.
+func (r *siteResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan siteModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	site := plan.site(ctx, &resp.Diagnostics)
+	id := parseID(plan.ID.ValueString(), &resp.Diagnostics)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, _, err := r.client.Sites.Update(ctx, id, site)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not update site", err.Error())
+
+		return
+	}
+
+	plan.set(ctx, updated, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements the resource.Resource interface.
+func (r *siteResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state siteModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.client.Sites.Remove(ctx, id); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Could not remove site", err.Error())
+	}
+}
+
+// ImportState implements the resource.ResourceWithImportState interface. Sites
+// are imported by ID.
+func (r *siteResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// site returns the site described by the model. Unset optional attributes
+// are sent as null, so removing them from the configuration clears them.
+func (m *siteModel) site(ctx context.Context, diags *diag.Diagnostics) *ohdear.Site {
+	site := &ohdear.Site{
+		URL:          m.URL.ValueString(),
+		Label:        m.Label.ValueString(),
+		GroupName:    ohdear.Null[string](),
+		FriendlyName: ohdear.Null[string](),
+	}
+
+	if !m.GroupName.IsNull() {
+		site.GroupName = ohdear.NewNullable(m.GroupName.ValueString())
+	}
+
+	if !m.FriendlyName.IsNull() {
+		site.FriendlyName = ohdear.NewNullable(m.FriendlyName.ValueString())
+	}
+
+	if !m.Tags.IsNull() && !m.Tags.IsUnknown() {
+		diags.Append(m.Tags.ElementsAs(ctx, &site.Tags, false)...)
+	}
+
+	// Empty tags are left out of the payload, so they're sent explicitly
+	// to clear the tags of the site.
+	if len(site.Tags) == 0 {
+		site.Extra = map[string]json.RawMessage{
+			"tags": json.RawMessage("[]"),
+		}
+	}
+
+	return site
+}
+
+// set stores the site returned by the API in the model.
+func (m *siteModel) set(ctx context.Context, site *ohdear.Site, diags *diag.Diagnostics) {
+	m.ID = types.StringValue(formatID(site.ID))
+	m.URL = types.StringValue(site.URL)
+	m.Label = types.StringValue(site.Label)
+	m.SortURL = types.StringValue(site.SortURL)
+
+	if site.TeamID != 0 {
+		m.TeamID = types.Int64Value(int64(site.TeamID))
+	}
+
+	m.GroupName = types.StringNull()
+	if value, ok := site.GroupName.Get(); ok {
+		m.GroupName = types.StringValue(value)
+	}
+
+	m.FriendlyName = types.StringNull()
+	if value, ok := site.FriendlyName.Get(); ok {
+		m.FriendlyName = types.StringValue(value)
+	}
+
+	if len(site.Tags) == 0 {
+		m.Tags = types.SetNull(types.StringType)
+
+		return
+	}
+
+	tags, d := types.SetValueFrom(ctx, types.StringType, site.Tags)
+	diags.Append(d...)
+
+	m.Tags = tags
+}
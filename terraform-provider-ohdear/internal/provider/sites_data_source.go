@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time check to ensure sitesDataSource implements the data source
+// interfaces.
+var _ datasource.DataSourceWithConfigure = (*sitesDataSource)(nil)
+
+// sitesDataSource lists the sites in the account, optionally filtered by team
+// and tag.
+type sitesDataSource struct {
+	client *ohdear.Client
+}
+
+// sitesModel is the state of the sites data source.
+type sitesModel struct {
+	Tag    types.String    `tfsdk:"tag"`
+	Sites  []siteDataModel `tfsdk:"sites"`
+	TeamID types.Int64     `tfsdk:"team_id"`
+}
+
+// siteDataModel is a site in the sites data source.
+type siteDataModel struct {
+	ID     types.String   `tfsdk:"id"`
+	URL    types.String   `tfsdk:"url"`
+	Label  types.String   `tfsdk:"label"`
+	Tags   []types.String `tfsdk:"tags"`
+	TeamID types.Int64    `tfsdk:"team_id"`
+}
+
+func newSitesDataSource() datasource.DataSource {
+	return &sitesDataSource{}
+}
+
+// Metadata implements the datasource.DataSource interface.
+func (d *sitesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sites"
+}
+
+// Schema implements the datasource.DataSource interface.
+func (d *sitesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The sites in the account.",
+		Attributes: map[string]schema.Attribute{
+			"team_id": schema.Int64Attribute{
+				Description: "Only list the sites of this team.",
+				Optional:    true,
+			},
+			"tag": schema.StringAttribute{
+				Description: "Only list the sites with this tag.",
+				Optional:    true,
+			},
+			"sites": schema.ListNestedAttribute{
+				Description: "The sites.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The ID of the site.",
+							Computed:    true,
+						},
+						"url": schema.StringAttribute{
+							Description: "The URL of the site.",
+							Computed:    true,
+						},
+						"label": schema.StringAttribute{
+							Description: "The label of the site.",
+							Computed:    true,
+						},
+						"team_id": schema.Int64Attribute{
+							Description: "The ID of the team owning the site.",
+							Computed:    true,
+						},
+						"tags": schema.ListAttribute{
+							Description: "The tags of the site.",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure implements the datasource.DataSourceWithConfigure interface.
+func (d *sitesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Read implements the datasource.DataSource interface.
+func (d *sitesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state sitesModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sites, err := d.client.Sites.ListAll(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not list sites", err.Error())
+
+		return
+	}
+
+	state.Sites = make([]siteDataModel, 0, len(sites))
+
+	for i := range sites {
+		site := &sites[i]
+
+		if !state.TeamID.IsNull() && int64(site.TeamID) != state.TeamID.ValueInt64() {
+			continue
+		}
+
+		if !state.Tag.IsNull() && !hasTag(site, state.Tag.ValueString()) {
+			continue
+		}
+
+		tags := make([]types.String, 0, len(site.Tags))
+
+		for _, tag := range site.Tags {
+			tags = append(tags, types.StringValue(tag))
+		}
+
+		state.Sites = append(state.Sites, siteDataModel{
+			ID:     types.StringValue(formatID(site.ID)),
+			URL:    types.StringValue(site.URL),
+			Label:  types.StringValue(site.Label),
+			Tags:   tags,
+			TeamID: types.Int64Value(int64(site.TeamID)),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// hasTag returns true if the site has the given tag.
+func hasTag(site *ohdear.Site, tag string) bool {
+	for _, t := range site.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
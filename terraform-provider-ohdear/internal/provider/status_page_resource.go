@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"context"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time checks to ensure statusPageResource implements the resource
+// interfaces.
+var (
+	_ resource.ResourceWithConfigure   = (*statusPageResource)(nil)
+	_ resource.ResourceWithImportState = (*statusPageResource)(nil)
+)
+
+// statusPageResource manages a status page.
+type statusPageResource struct {
+	client *ohdear.Client
+}
+
+// statusPageModel is the state of a status page.
+type statusPageModel struct {
+	ID      types.String `tfsdk:"id"`
+	Title   types.String `tfsdk:"title"`
+	Slug    types.String `tfsdk:"slug"`
+	SiteIDs types.Set    `tfsdk:"site_ids"`
+	TeamID  types.Int64  `tfsdk:"team_id"`
+}
+
+func newStatusPageResource() resource.Resource {
+	return &statusPageResource{}
+}
+
+// Metadata implements the resource.Resource interface.
+func (r *statusPageResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_status_page"
+}
+
+// Schema implements the resource.Resource interface.
+func (r *statusPageResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A public status page showing the status of some of the sites of a team.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the status page.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"team_id": schema.Int64Attribute{
+				Description: "The ID of the team owning the status page. Changing it replaces the status page.",
+				Required:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"title": schema.StringAttribute{
+				Description: "The title of the status page.",
+				Required:    true,
+			},
+			"slug": schema.StringAttribute{
+				Description: "The slug in the URL of the status page. Defaults to one generated by Oh Dear.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"site_ids": schema.SetAttribute{
+				Description: "The IDs of the sites shown on the status page.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// Configure implements the resource.ResourceWithConfigure interface.
+func (r *statusPageResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Create implements the resource.Resource interface.
+func (r *statusPageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan statusPageModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	statusPage := plan.statusPage(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	statusPage.TeamID = int(plan.TeamID.ValueInt64())
+
+	added, _, err := r.client.StatusPages.Add(ctx, statusPage)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not add status page", err.Error())
+
+		return
+	}
+
+	plan.set(ctx, added, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read implements the resource.Resource interface.
+func (r *statusPageResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state statusPageModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	statusPage, _, err := r.client.StatusPages.Get(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+
+			return
+		}
+
+		resp.Diagnostics.AddError("Could not read status page", err.Error())
+
+		return
+	}
+
+	state.set(ctx, statusPage, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update implements the resource.Resource interface.
+func (r *statusPageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan statusPageModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	statusPage := plan.statusPage(ctx, &resp.Diagnostics)
+	id := parseID(plan.ID.ValueString(), &resp.Diagnostics)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, _, err := r.client.StatusPages.Update(ctx, id, statusPage)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not update status page", err.Error())
+
+		return
+	}
+
+	plan.set(ctx, updated, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete implements the resource.Resource interface.
+func (r *statusPageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state statusPageModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	id := parseID(state.ID.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.client.StatusPages.Remove(ctx, id); err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Could not remove status page", err.Error())
+	}
+}
+
+// ImportState implements the resource.ResourceWithImportState interface.
+// Status pages are imported by ID.
+func (r *statusPageResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// statusPage returns the status page described by the model.
+func (m *statusPageModel) statusPage(ctx context.Context, diags *diag.Diagnostics) *ohdear.StatusPage {
+	statusPage := &ohdear.StatusPage{
+		Title: m.Title.ValueString(),
+		Slug:  m.Slug.ValueString(),
+	}
+
+	if m.SiteIDs.IsNull() || m.SiteIDs.IsUnknown() {
+		return statusPage
+	}
+
+	var ids []string
+
+	diags.Append(m.SiteIDs.ElementsAs(ctx, &ids, false)...)
+
+	for _, id := range ids {
+		statusPage.SiteIDs = append(statusPage.SiteIDs, int(parseID(id, diags)))
+	}
+
+	return statusPage
+}
+
+// set stores the status page returned by the API in the model.
+func (m *statusPageModel) set(ctx context.Context, statusPage *ohdear.StatusPage, diags *diag.Diagnostics) {
+	m.ID = types.StringValue(formatID(statusPage.ID))
+	m.Title = types.StringValue(statusPage.Title)
+	m.Slug = types.StringValue(statusPage.Slug)
+
+	if statusPage.TeamID != 0 {
+		m.TeamID = types.Int64Value(int64(statusPage.TeamID))
+	}
+
+	if len(statusPage.SiteIDs) == 0 {
+		m.SiteIDs = types.SetNull(types.StringType)
+
+		return
+	}
+
+	ids := make([]string, 0, len(statusPage.SiteIDs))
+
+	for _, id := range statusPage.SiteIDs {
+		ids = append(ids, formatID(id))
+	}
+
+	siteIDs, d := types.SetValueFrom(ctx, types.StringType, ids)
+	diags.Append(d...)
+
+	m.SiteIDs = siteIDs
+}
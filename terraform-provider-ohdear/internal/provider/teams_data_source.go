@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Compile-time check to ensure teamsDataSource implements the data source
+// interfaces.
+var _ datasource.DataSourceWithConfigure = (*teamsDataSource)(nil)
+
+// teamsDataSource lists the teams of the owner of the API token.
+type teamsDataSource struct {
+	client *ohdear.Client
+}
+
+// teamsModel is the state of the teams data source.
+type teamsModel struct {
+	Teams []teamModel `tfsdk:"teams"`
+}
+
+// teamModel is a team in the teams data source.
+type teamModel struct {
+	ID   types.Int64  `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+}
+
+func newTeamsDataSource() datasource.DataSource {
+	return &teamsDataSource{}
+}
+
+// Metadata implements the datasource.DataSource interface.
+func (d *teamsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_teams"
+}
+
+// Schema implements the datasource.DataSource interface.
+func (d *teamsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The teams of the owner of the API token.",
+		Attributes: map[string]schema.Attribute{
+			"teams": schema.ListNestedAttribute{
+				Description: "The teams.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Description: "The ID of the team.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the team.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure implements the datasource.DataSourceWithConfigure interface.
+func (d *teamsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.client = clientFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+// Read implements the datasource.DataSource interface.
+func (d *teamsDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	teams, err := d.client.Teams.List(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not list teams", err.Error())
+
+		return
+	}
+
+	state := teamsModel{
+		Teams: make([]teamModel, 0, len(teams)),
+	}
+
+	for _, team := range teams {
+		state.Teams = append(state.Teams, teamModel{
+			ID:   types.Int64Value(int64(team.ID)),
+			Name: types.StringValue(team.Name),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
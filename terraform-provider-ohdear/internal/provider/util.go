@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// clientFrom returns the client passed by the provider to resources and data
+// sources. It returns nil before the provider is configured, such as during
+// validation.
+func clientFrom(data any, diags *diag.Diagnostics) *ohdear.Client {
+	if data == nil {
+		return nil
+	}
+
+	client, ok := data.(*ohdear.Client)
+	if !ok {
+		diags.AddError("Unexpected provider data", fmt.Sprintf("Expected *ohdear.Client, got %T.", data))
+
+		return nil
+	}
+
+	return client
+}
+
+// isNotFound returns true if err is an API error caused by a missing
+// resource, which means it was removed outside of Terraform.
+func isNotFound(err error) bool {
+	var apiErr *ohdear.APIError
+
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// parseID parses a resource ID stored in the state.
+func parseID(id string, diags *diag.Diagnostics) uint {
+	n, err := strconv.ParseUint(id, 10, 0)
+	if err != nil || n == 0 {
+		diags.AddError("Invalid ID", fmt.Sprintf("Expected a positive integer ID, got %q.", id))
+
+		return 0
+	}
+
+	return uint(n)
+}
+
+// formatID formats an ID returned by the API for the state.
+func formatID(id int) string {
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,41 @@
+// Command terraform-provider-ohdear is a Terraform provider managing Oh Dear
+// sites, checks, maintenance periods, cron checks, status pages and
+// notification destinations.
+//
+// The provider is configured with an API token and, optionally, the base URL
+// of the API:
+//
+//	provider "ohdear" {
+//	  api_token = var.ohdear_api_token # or OHDEAR_API_TOKEN
+//	}
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/terraform-provider-ohdear/internal/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+)
+
+// version is the version of the provider, set at build time with
+// -ldflags "-X main.version=...".
+var version = "dev"
+
+// _address is the registry address of the provider.
+const _address string = "registry.terraform.io/jamesponddotco/ohdear"
+
+func main() {
+	debug := flag.Bool("debug", false, "run the provider with support for debuggers")
+
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
+		Address: _address,
+		Debug:   *debug,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
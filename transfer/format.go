@@ -0,0 +1,333 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ErrUnknownFormat is returned when a document is encoded or decoded
+	// using a format this package doesn't support.
+	ErrUnknownFormat xerrors.Error = "unknown document format"
+
+	// ErrInvalidCSV is returned when a CSV document doesn't have the expected
+	// columns.
+	ErrInvalidCSV xerrors.Error = "invalid CSV document"
+)
+
+// Format is the encoding of a document.
+type Format string
+
+// Formats supported by Encode and Decode.
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+)
+
+// _csvSeparator separates the values of list columns in CSV documents.
+const _csvSeparator string = ";"
+
+// _csvHeader holds the columns of CSV documents, in order.
+var _csvHeader = []string{
+	"id",
+	"team_id",
+	"url",
+	"label",
+	"friendly_name",
+	"group_name",
+	"notes",
+	"tags",
+	"broken_links_whitelisted_urls",
+	"broken_links_check_include_external_links",
+	"checks",
+	"uptime",
+}
+
+// FormatFromPath returns the format matching the extension of path.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".csv":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, filepath.Ext(path))
+	}
+}
+
+// Encode writes doc to w using the given format.
+//
+// CSV documents hold one site per row. List columns are separated by
+// semicolons, and checks are written as type=enabled pairs, such as
+// "uptime=true;dns=false". The export time and format version are not
+// included.
+func Encode(w io.Writer, doc *Document, format Format) error {
+	if doc == nil {
+		return ErrDocumentRequired
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("could not encode JSON document: %w", err)
+		}
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("could not encode YAML document: %w", err)
+		}
+
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("could not encode YAML document: %w", err)
+		}
+	case FormatCSV:
+		return encodeCSV(w, doc)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	return nil
+}
+
+// Decode reads a document from r using the given format.
+func Decode(r io.Reader, format Format) (*Document, error) {
+	var doc Document
+
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode JSON document: %w", err)
+		}
+	case FormatYAML:
+		if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode YAML document: %w", err)
+		}
+	case FormatCSV:
+		return decodeCSV(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	if doc.Version < Version {
+		doc.upgrade()
+	}
+
+	return &doc, nil
+}
+
+// encodeCSV writes doc to w as CSV.
+func encodeCSV(w io.Writer, doc *Document) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(_csvHeader); err != nil {
+		return fmt.Errorf("could not encode CSV document: %w", err)
+	}
+
+	for i := range doc.Sites {
+		site := &doc.Sites[i]
+
+		checks := make([]string, 0, len(site.Checks))
+		for _, check := range site.Checks {
+			checks = append(checks, check.Type+"="+strconv.FormatBool(check.Enabled))
+		}
+
+		uptime, err := csvUptime(site.Uptime)
+		if err != nil {
+			return err
+		}
+
+		record := []string{
+			strconv.Itoa(site.ID),
+			strconv.Itoa(site.TeamID),
+			site.URL,
+			site.Label,
+			site.FriendlyName,
+			site.GroupName,
+			site.Notes,
+			strings.Join(site.Tags, _csvSeparator),
+			site.BrokenLinksWhitelistedURLs,
+			strconv.FormatBool(site.BrokenLinksCheckIncludeExternalLinks),
+			strings.Join(checks, _csvSeparator),
+			uptime,
+		}
+
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("could not encode CSV document: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("could not encode CSV document: %w", err)
+	}
+
+	return nil
+}
+
+// decodeCSV reads a CSV document from r. The columns may appear in any order,
+// and only the url column is required.
+func decodeCSV(r io.Reader) (*Document, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode CSV document: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidCSV)
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	if _, ok := columns["url"]; !ok {
+		return nil, fmt.Errorf("%w: missing url column", ErrInvalidCSV)
+	}
+
+	doc := &Document{
+		Sites:   make([]Site, 0, len(records)-1),
+		Version: Version,
+	}
+
+	for line, record := range records[1:] {
+		site, err := csvSite(columns, record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidCSV, line+2, err)
+		}
+
+		doc.Sites = append(doc.Sites, site)
+	}
+
+	// CSV documents of version 1 have http_client_headers and
+	// uptime_check_payload columns instead of uptime.
+	doc.upgrade()
+
+	return doc, nil
+}
+
+// csvSite returns the site held by a CSV record.
+func csvSite(columns map[string]int, record []string) (Site, error) {
+	get := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	var (
+		site = Site{
+			URL:                        get("url"),
+			Label:                      get("label"),
+			FriendlyName:               get("friendly_name"),
+			GroupName:                  get("group_name"),
+			Notes:                      get("notes"),
+			BrokenLinksWhitelistedURLs: get("broken_links_whitelisted_urls"),
+			Tags:                       splitList(get("tags")),
+			HTTPClientHeaders:          get("http_client_headers"),
+			UptimeCheckPayload:         splitList(get("uptime_check_payload")),
+		}
+		err error
+	)
+
+	if site.ID, err = parseInt(get("id")); err != nil {
+		return Site{}, fmt.Errorf("invalid id: %w", err)
+	}
+
+	if site.TeamID, err = parseInt(get("team_id")); err != nil {
+		return Site{}, fmt.Errorf("invalid team_id: %w", err)
+	}
+
+	if value := get("broken_links_check_include_external_links"); value != "" {
+		site.BrokenLinksCheckIncludeExternalLinks, err = strconv.ParseBool(value)
+		if err != nil {
+			return Site{}, fmt.Errorf("invalid broken_links_check_include_external_links: %w", err)
+		}
+	}
+
+	if value := get("uptime"); value != "" {
+		if err = json.Unmarshal([]byte(value), &site.Uptime); err != nil {
+			return Site{}, fmt.Errorf("invalid uptime: %w", err)
+		}
+	}
+
+	for _, pair := range splitList(get("checks")) {
+		checkType, value, found := strings.Cut(pair, "=")
+
+		check := Check{
+			Type:    strings.TrimSpace(checkType),
+			Enabled: true,
+		}
+
+		if found {
+			if check.Enabled, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				return Site{}, fmt.Errorf("invalid check %q: %w", pair, err)
+			}
+		}
+
+		site.Checks = append(site.Checks, check)
+	}
+
+	return site, nil
+}
+
+// csvUptime returns the uptime column of a site, which holds its uptime
+// check settings as JSON.
+func csvUptime(uptime *Uptime) (string, error) {
+	if uptime == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(uptime)
+	if err != nil {
+		return "", fmt.Errorf("could not encode uptime settings: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// splitList splits a list column into its values, ignoring empty ones.
+func splitList(value string) []string {
+	var values []string
+
+	for _, v := range strings.Split(value, _csvSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// parseInt parses an integer column, treating empty values as zero.
+func parseInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,621 @@
+// Package transfer exports the sites of an Oh Dear account to a portable
+// document and imports them into another team or account.
+//
+// Documents hold the settings, tags, notes and checks of every site and can
+// be encoded as JSON, YAML or CSV, which makes them suitable both for
+// migrating between teams and for keeping backups of a monitoring
+// configuration.
+//
+//	doc, err := transfer.Export(ctx, source, nil)
+//	if err != nil {
+//		return err
+//	}
+//
+//	report, err := transfer.Import(ctx, target, doc, &transfer.ImportOptions{
+//		TeamID:   42,
+//		Conflict: transfer.ConflictUpdate,
+//	})
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/urlutil"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrClientRequired is returned when Export or Import are called without
+	// a client.
+	ErrClientRequired xerrors.Error = "client cannot be nil"
+
+	// ErrDocumentRequired is returned when Import is called without a
+	// document.
+	ErrDocumentRequired xerrors.Error = "document cannot be nil"
+
+	// ErrUnsupportedVersion is returned when a document was written by a
+	// newer, incompatible version of this package.
+	ErrUnsupportedVersion xerrors.Error = "unsupported document version"
+
+	// ErrSiteExists is returned when a site in the document already exists
+	// in the target account and ConflictFail is used.
+	ErrSiteExists xerrors.Error = "site already exists"
+)
+
+// Version is the version of the document format written by this package.
+// Version 2 replaced the http_client_headers and uptime_check_payload fields
+// of sites with uptime. Decode and Import move them into Uptime when they're
+// found in older documents.
+const Version int = 2
+
+// Document is a portable representation of the sites of an account.
+type Document struct {
+	// ExportedAt is the moment the document was exported.
+	ExportedAt time.Time `json:"exported_at" yaml:"exported_at"`
+
+	// Sites holds every exported site.
+	Sites []Site `json:"sites" yaml:"sites"`
+
+	// Version is the version of the document format.
+	Version int `json:"version" yaml:"version"`
+}
+
+// Site is the portable representation of a site. Fields managed by Oh Dear,
+// such as check results and timestamps, are not included.
+type Site struct {
+	URL                        string   `json:"url" yaml:"url"`
+	Label                      string   `json:"label,omitempty" yaml:"label,omitempty"`
+	FriendlyName               string   `json:"friendly_name,omitempty" yaml:"friendly_name,omitempty"`
+	GroupName                  string   `json:"group_name,omitempty" yaml:"group_name,omitempty"`
+	Notes                      string   `json:"notes,omitempty" yaml:"notes,omitempty"`
+	BrokenLinksWhitelistedURLs string   `json:"broken_links_whitelisted_urls,omitempty" yaml:"broken_links_whitelisted_urls,omitempty"`
+	Tags                       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Checks                     []Check  `json:"checks,omitempty" yaml:"checks,omitempty"`
+	Uptime                     *Uptime  `json:"uptime,omitempty" yaml:"uptime,omitempty"`
+
+	// HTTPClientHeaders holds the headers of the uptime check in documents
+	// of version 1, one per line as "Name: Value".
+	//
+	// Deprecated: Use Uptime.Headers instead.
+	HTTPClientHeaders string `json:"http_client_headers,omitempty" yaml:"http_client_headers,omitempty"`
+
+	// UptimeCheckPayload holds the payload of the uptime check in documents
+	// of version 1, as "key=value".
+	//
+	// Deprecated: Use Uptime.Payload instead.
+	UptimeCheckPayload []string `json:"uptime_check_payload,omitempty" yaml:"uptime_check_payload,omitempty"`
+
+	ID                                   int  `json:"id,omitempty" yaml:"id,omitempty"`
+	TeamID                               int  `json:"team_id,omitempty" yaml:"team_id,omitempty"`
+	BrokenLinksCheckIncludeExternalLinks bool `json:"broken_links_check_include_external_links,omitempty" yaml:"broken_links_check_include_external_links,omitempty"`
+}
+
+// Check is the portable representation of a check.
+type Check struct {
+	Type    string `json:"type" yaml:"type"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+}
+
+// Uptime is the portable representation of the uptime check settings of a
+// site.
+type Uptime struct {
+	MaxRedirects        *int       `json:"max_redirects,omitempty" yaml:"max_redirects,omitempty"`
+	VerifyTLS           *bool      `json:"verify_tls,omitempty" yaml:"verify_tls,omitempty"`
+	LookForString       string     `json:"look_for_string,omitempty" yaml:"look_for_string,omitempty"`
+	AbsentString        string     `json:"absent_string,omitempty" yaml:"absent_string,omitempty"`
+	Method              string     `json:"method,omitempty" yaml:"method,omitempty"`
+	IPVersion           string     `json:"ip_version,omitempty" yaml:"ip_version,omitempty"`
+	ExpectedStatusCodes []string   `json:"expected_status_codes,omitempty" yaml:"expected_status_codes,omitempty"`
+	Headers             []KeyValue `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Payload             []KeyValue `json:"payload,omitempty" yaml:"payload,omitempty"`
+	TimeoutInSeconds    int        `json:"timeout_in_seconds,omitempty" yaml:"timeout_in_seconds,omitempty"`
+}
+
+// KeyValue is the portable representation of a header or payload field of
+// the uptime check of a site.
+type KeyValue struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// NewUptime returns the portable representation of settings, or nil if
+// settings is nil.
+func NewUptime(settings *ohdear.UptimeSettings) *Uptime {
+	if settings == nil {
+		return nil
+	}
+
+	ret := &Uptime{
+		LookForString:       settings.LookForString.Value(),
+		AbsentString:        settings.AbsentString.Value(),
+		Method:              settings.Method,
+		IPVersion:           string(settings.IPVersion),
+		ExpectedStatusCodes: append([]string(nil), settings.ExpectedStatusCodes...),
+		TimeoutInSeconds:    settings.TimeoutInSeconds,
+	}
+
+	if redirects, ok := settings.MaxRedirects.Get(); ok {
+		ret.MaxRedirects = &redirects
+	}
+
+	if verify, ok := settings.VerifyTLS.Get(); ok {
+		ret.VerifyTLS = &verify
+	}
+
+	for _, header := range settings.Headers {
+		ret.Headers = append(ret.Headers, KeyValue{Key: header.Name, Value: header.Value})
+	}
+
+	for _, field := range settings.Payload {
+		ret.Payload = append(ret.Payload, KeyValue{Key: field.Key, Value: field.Value})
+	}
+
+	return ret
+}
+
+// Settings returns u as *ohdear.UptimeSettings, or nil if u is nil.
+func (u *Uptime) Settings() *ohdear.UptimeSettings {
+	if u == nil {
+		return nil
+	}
+
+	ret := &ohdear.UptimeSettings{
+		LookForString:       optional(u.LookForString),
+		AbsentString:        optional(u.AbsentString),
+		Method:              u.Method,
+		IPVersion:           ohdear.IPVersion(u.IPVersion),
+		ExpectedStatusCodes: append([]string(nil), u.ExpectedStatusCodes...),
+		TimeoutInSeconds:    u.TimeoutInSeconds,
+	}
+
+	if u.MaxRedirects != nil {
+		ret.MaxRedirects = ohdear.NewNullable(*u.MaxRedirects)
+	}
+
+	if u.VerifyTLS != nil {
+		ret.VerifyTLS = ohdear.NewNullable(*u.VerifyTLS)
+	}
+
+	for _, header := range u.Headers {
+		ret.Headers = append(ret.Headers, ohdear.UptimeHeader{Name: header.Key, Value: header.Value})
+	}
+
+	for _, field := range u.Payload {
+		ret.Payload = append(ret.Payload, ohdear.UptimePayloadField{Key: field.Key, Value: field.Value})
+	}
+
+	return ret
+}
+
+// NewSite returns the portable representation of site.
+func NewSite(site *ohdear.Site) Site {
+	ret := Site{
+		URL:                                  site.URL,
+		Label:                                site.Label,
+		FriendlyName:                         site.FriendlyName.Value(),
+		GroupName:                            site.GroupName.Value(),
+		Notes:                                site.Notes.Value(),
+		BrokenLinksWhitelistedURLs:           site.BrokenLinksWhitelistedURLs.Value(),
+		Tags:                                 append([]string(nil), site.Tags...),
+		Uptime:                               NewUptime(site.Uptime),
+		ID:                                   site.ID,
+		TeamID:                               site.TeamID,
+		BrokenLinksCheckIncludeExternalLinks: site.BrokenLinksCheckIncludeExternalLinks,
+	}
+
+	for _, check := range site.Checks {
+		ret.Checks = append(ret.Checks, Check{
+			Type:    string(check.Type),
+			Enabled: check.Enabled,
+		})
+	}
+
+	return ret
+}
+
+// Site returns the settings of s as an *ohdear.Site, ready to be added to the
+// given team. Checks are not included, as they're managed separately.
+func (s *Site) Site(teamID int) *ohdear.Site {
+	return &ohdear.Site{
+		URL:                                  s.URL,
+		Label:                                s.Label,
+		FriendlyName:                         optional(s.FriendlyName),
+		GroupName:                            optional(s.GroupName),
+		Notes:                                optional(s.Notes),
+		BrokenLinksWhitelistedURLs:           optional(s.BrokenLinksWhitelistedURLs),
+		Tags:                                 append([]string(nil), s.Tags...),
+		Uptime:                               s.uptime().Settings(),
+		TeamID:                               teamID,
+		BrokenLinksCheckIncludeExternalLinks: s.BrokenLinksCheckIncludeExternalLinks,
+	}
+}
+
+// uptime returns the uptime check settings of the site, completed with the
+// headers and payload of documents of version 1.
+func (s *Site) uptime() *Uptime {
+	if s.HTTPClientHeaders == "" && len(s.UptimeCheckPayload) == 0 {
+		return s.Uptime
+	}
+
+	ret := &Uptime{}
+	if s.Uptime != nil {
+		*ret = *s.Uptime
+	}
+
+	if len(ret.Headers) == 0 {
+		for _, line := range strings.Split(s.HTTPClientHeaders, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+
+			name, value, _ := strings.Cut(line, ":")
+			ret.Headers = append(ret.Headers, KeyValue{Key: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+		}
+	}
+
+	if len(ret.Payload) == 0 {
+		for _, field := range s.UptimeCheckPayload {
+			key, value, _ := strings.Cut(field, "=")
+			ret.Payload = append(ret.Payload, KeyValue{Key: key, Value: value})
+		}
+	}
+
+	return ret
+}
+
+// upgrade moves the fields of documents of older versions into their
+// replacements, and sets the version of the document to Version.
+func (d *Document) upgrade() {
+	for i := range d.Sites {
+		site := &d.Sites[i]
+
+		site.Uptime = site.uptime()
+		site.HTTPClientHeaders = ""
+		site.UptimeCheckPayload = nil
+	}
+
+	d.Version = Version
+}
+
+// ExportOptions holds the configuration for Export.
+type ExportOptions struct {
+	// TeamID restricts the export to the sites of a single team.
+	//
+	// This field is optional. Every site is exported if it's zero.
+	TeamID int
+}
+
+// Export returns a document holding every site of the account used by
+// client.
+func Export(ctx context.Context, client *ohdear.Client, opts *ExportOptions) (*Document, error) {
+	if client == nil {
+		return nil, ErrClientRequired
+	}
+
+	sites, err := client.Sites.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list sites: %w", err)
+	}
+
+	doc := &Document{
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Sites:      make([]Site, 0, len(sites)),
+		Version:    Version,
+	}
+
+	for i := range sites {
+		if opts != nil && opts.TeamID != 0 && sites[i].TeamID != opts.TeamID {
+			continue
+		}
+
+		doc.Sites = append(doc.Sites, NewSite(&sites[i]))
+	}
+
+	return doc, nil
+}
+
+// ConflictPolicy defines what Import does with sites that already exist in
+// the target account, identified by their URL.
+type ConflictPolicy int
+
+const (
+	// ConflictSkip leaves existing sites untouched.
+	ConflictSkip ConflictPolicy = iota
+
+	// ConflictUpdate overwrites the settings and checks of existing sites
+	// with the ones in the document.
+	ConflictUpdate
+
+	// ConflictFail makes Import fail without changing anything if any site
+	// already exists.
+	ConflictFail
+)
+
+// ImportOptions holds the configuration for Import.
+type ImportOptions struct {
+	// TeamID is the team the sites are added to.
+	//
+	// This field is optional. If it's zero, each site is added to the team it
+	// was exported from.
+	TeamID int
+
+	// Conflict defines what to do with sites that already exist.
+	//
+	// This field is optional. It defaults to ConflictSkip.
+	Conflict ConflictPolicy
+
+	// Workers is the number of sites added concurrently.
+	//
+	// This field is optional. It defaults to ohdear.DefaultBulkWorkers.
+	Workers int
+}
+
+// Action describes what Import did with a site.
+type Action string
+
+// Actions taken by Import.
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionSkipped Action = "skipped"
+	ActionFailed  Action = "failed"
+)
+
+// Result is the outcome of importing a single site.
+type Result struct {
+	// Err is the error that made the import of the site fail, or that
+	// prevented its checks from being fully restored.
+	Err error
+
+	// URL is the URL of the site.
+	URL string
+
+	// Action is what Import did with the site.
+	Action Action
+
+	// OldID is the ID of the site in the document.
+	OldID int
+
+	// NewID is the ID of the site in the target account.
+	NewID int
+}
+
+// Report describes the outcome of Import.
+type Report struct {
+	// IDs maps the IDs of the sites in the document to their IDs in the
+	// target account, for sites that were created, updated or skipped.
+	IDs map[int]int
+
+	// Results holds the outcome of every site, in the same order as the
+	// document.
+	Results []Result
+}
+
+// Err returns an error joining the errors of every site that failed, or nil
+// if every site was imported.
+func (r *Report) Err() error {
+	var errs []error
+
+	for i := range r.Results {
+		if r.Results[i].Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Results[i].URL, r.Results[i].Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Import recreates the sites in doc in the account used by client. Sites are
+// matched to existing ones of the team they're imported into by URL and
+// handled according to the conflict policy. Once a site exists, its checks
+// are enabled and disabled to match the document.
+//
+// Failing sites don't stop the import; their errors are recorded in the
+// report. An error is returned only if the import couldn't start.
+func Import(ctx context.Context, client *ohdear.Client, doc *Document, opts *ImportOptions) (*Report, error) {
+	if client == nil {
+		return nil, ErrClientRequired
+	}
+
+	if doc == nil {
+		return nil, ErrDocumentRequired
+	}
+
+	if doc.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, doc.Version)
+	}
+
+	var o ImportOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	var listOpts []ohdear.RequestOption
+	if o.TeamID != 0 {
+		listOpts = append(listOpts, ohdear.WithQuery("filter[team_id]", strconv.Itoa(o.TeamID)))
+	}
+
+	existing, err := client.Sites.ListAll(ctx, listOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list existing sites: %w", err)
+	}
+
+	byKey := make(map[siteKey]*ohdear.Site, len(existing))
+	for i := range existing {
+		byKey[newSiteKey(existing[i].TeamID, existing[i].URL)] = &existing[i]
+	}
+
+	if o.Conflict == ConflictFail {
+		for i := range doc.Sites {
+			if _, ok := byKey[newSiteKey(o.team(&doc.Sites[i]), doc.Sites[i].URL)]; ok {
+				return nil, fmt.Errorf("%w: %s", ErrSiteExists, doc.Sites[i].URL)
+			}
+		}
+	}
+
+	var (
+		report = &Report{
+			IDs:     make(map[int]int, len(doc.Sites)),
+			Results: make([]Result, len(doc.Sites)),
+		}
+		created = make([]int, 0, len(doc.Sites))
+		toAdd   = make([]*ohdear.Site, 0, len(doc.Sites))
+	)
+
+	for i := range doc.Sites {
+		site := &doc.Sites[i]
+
+		report.Results[i] = Result{
+			URL:   site.URL,
+			OldID: site.ID,
+		}
+
+		teamID := o.team(site)
+
+		current, ok := byKey[newSiteKey(teamID, site.URL)]
+		if !ok {
+			created = append(created, i)
+			toAdd = append(toAdd, site.Site(teamID))
+
+			continue
+		}
+
+		if o.Conflict != ConflictUpdate {
+			report.record(i, ActionSkipped, current.ID, nil)
+
+			continue
+		}
+
+		updated, _, err := client.Sites.Update(ctx, uint(current.ID), site.Site(0))
+		if err != nil {
+			report.record(i, ActionFailed, 0, err)
+
+			continue
+		}
+
+		report.record(i, ActionUpdated, updated.ID, syncChecks(ctx, client, site.Checks, updated.Checks))
+	}
+
+	results := client.Sites.AddMany(ctx, toAdd, &ohdear.BulkOptions{Workers: o.Workers})
+
+	for j, i := range created {
+		if results[j].Err != nil {
+			report.record(i, ActionFailed, 0, results[j].Err)
+
+			continue
+		}
+
+		added := results[j].Value
+
+		report.record(i, ActionCreated, added.ID, syncChecks(ctx, client, doc.Sites[i].Checks, added.Checks))
+	}
+
+	return report, nil
+}
+
+// team returns the team site is imported into.
+func (o *ImportOptions) team(site *Site) int {
+	if o.TeamID != 0 {
+		return o.TeamID
+	}
+
+	return site.TeamID
+}
+
+// siteKey identifies a site by its team and normalized URL, as the same URL
+// can be monitored by several teams.
+type siteKey struct {
+	url    string
+	teamID int
+}
+
+// newSiteKey returns the siteKey of the site of a team with the given URL.
+func newSiteKey(teamID int, rawURL string) siteKey {
+	return siteKey{
+		url:    normalizeURL(rawURL),
+		teamID: teamID,
+	}
+}
+
+// record stores the outcome of the site at index i.
+func (r *Report) record(i int, action Action, newID int, err error) {
+	r.Results[i].Action = action
+	r.Results[i].NewID = newID
+	r.Results[i].Err = err
+
+	if newID != 0 {
+		r.IDs[r.Results[i].OldID] = newID
+	}
+}
+
+// syncChecks enables and disables the checks of a site to match want. Checks
+// of types the site doesn't have are reported as an error, as the API doesn't
+// allow adding them after the site is created.
+func syncChecks(ctx context.Context, client *ohdear.Client, want []Check, have []ohdear.Check) error {
+	if len(want) == 0 {
+		return nil
+	}
+
+	enabled := make(map[string]bool, len(want))
+	for _, check := range want {
+		enabled[check.Type] = check.Enabled
+	}
+
+	var (
+		found = make(map[string]bool, len(have))
+		errs  []error
+	)
+
+	for _, check := range have {
+		found[string(check.Type)] = true
+
+		wantEnabled := enabled[string(check.Type)]
+		if check.Enabled == wantEnabled {
+			continue
+		}
+
+		var err error
+
+		if wantEnabled {
+			_, _, err = client.Checks.Enable(ctx, uint(check.ID))
+		} else {
+			_, _, err = client.Checks.Disable(ctx, uint(check.ID))
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not update %s check: %w", check.Type, err))
+		}
+	}
+
+	for _, check := range want {
+		if check.Enabled && !found[check.Type] {
+			errs = append(errs, fmt.Errorf("site has no %s check to enable", check.Type))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// normalizeURL returns the form of rawURL used to match sites.
+func normalizeURL(rawURL string) string {
+	if normalized, err := urlutil.Normalize(rawURL); err == nil {
+		return normalized
+	}
+
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(rawURL)), "/")
+}
+
+// optional returns s as a Nullable, which is not set if s is empty.
+func optional(s string) ohdear.Nullable[string] {
+	if s == "" {
+		return ohdear.Nullable[string]{}
+	}
+
+	return ohdear.NewNullable(s)
+}
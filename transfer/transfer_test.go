@@ -0,0 +1,235 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+	"git.sr.ht/~jamesponddotco/ohdear-go/transfer"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	redirects := 0
+
+	doc := &transfer.Document{
+		Version: transfer.Version,
+		Sites: []transfer.Site{
+			{
+				URL:          "https://example.com",
+				FriendlyName: "Example, Inc.",
+				Notes:        "Line one\nLine two",
+				Tags:         []string{"production", "eu"},
+				Checks: []transfer.Check{
+					{Type: "uptime", Enabled: true},
+					{Type: "broken_links", Enabled: false},
+				},
+				Uptime: &transfer.Uptime{
+					MaxRedirects:        &redirects,
+					LookForString:       "healthy",
+					Method:              "POST",
+					ExpectedStatusCodes: []string{"2*"},
+					Headers:             []transfer.KeyValue{{Key: "Authorization", Value: "Bearer token"}},
+					Payload:             []transfer.KeyValue{{Key: "check", Value: "deep"}},
+				},
+				ID:     1,
+				TeamID: 10,
+			},
+			{
+				URL:    "http://example.org",
+				ID:     2,
+				TeamID: 10,
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format transfer.Format
+	}{
+		{name: "json", format: transfer.FormatJSON},
+		{name: "yaml", format: transfer.FormatYAML},
+		{name: "csv", format: transfer.FormatCSV},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			if err := transfer.Encode(&buf, doc, tt.format); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			got, err := transfer.Decode(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got.Sites, doc.Sites) {
+				t.Errorf("Decode() sites = %+v, want %+v", got.Sites, doc.Sites)
+			}
+		})
+	}
+
+	if _, err := transfer.FormatFromPath("sites.xml"); !errors.Is(err, transfer.ErrUnknownFormat) {
+		t.Errorf("FormatFromPath() error = %v, want %v", err, transfer.ErrUnknownFormat)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	source := ohdeartest.NewServer(nil)
+	defer source.Close()
+
+	target := ohdeartest.NewServer(nil)
+	defer target.Close()
+
+	const notes = "Main website"
+
+	first := source.AddSite(&ohdear.Site{
+		URL:    "https://example.com",
+		Notes:  ohdear.NewNullable(notes),
+		Tags:   []string{"production"},
+		TeamID: 1,
+		Checks: []ohdear.Check{
+			{Type: ohdear.CheckTypeUptime, Enabled: true},
+			{Type: ohdear.CheckTypeBrokenLinks, Enabled: false},
+		},
+	})
+
+	second := source.AddSite(&ohdear.Site{URL: "https://example.org", TeamID: 1})
+	source.AddSite(&ohdear.Site{URL: "https://other-team.example.com", TeamID: 2})
+
+	existing := target.AddSite(&ohdear.Site{URL: "https://example.org/", TeamID: 5})
+	otherTeam := target.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 6})
+
+	doc, err := transfer.Export(ctx, source.Client(), &transfer.ExportOptions{TeamID: 1})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if len(doc.Sites) != 2 {
+		t.Fatalf("Export() returned %d sites, want 2", len(doc.Sites))
+	}
+
+	client := target.Client()
+
+	_, err = transfer.Import(ctx, client, doc, &transfer.ImportOptions{TeamID: 5, Conflict: transfer.ConflictFail})
+	if !errors.Is(err, transfer.ErrSiteExists) {
+		t.Fatalf("Import() with ConflictFail error = %v, want %v", err, transfer.ErrSiteExists)
+	}
+
+	report, err := transfer.Import(ctx, client, doc, &transfer.ImportOptions{TeamID: 5})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if err = report.Err(); err != nil {
+		t.Fatalf("Import() report error = %v", err)
+	}
+
+	if got := report.Results[1].Action; got != transfer.ActionSkipped {
+		t.Errorf("existing site action = %q, want %q", got, transfer.ActionSkipped)
+	}
+
+	if got := report.IDs[second.ID]; got != existing.ID {
+		t.Errorf("existing site mapped to ID %d, want %d", got, existing.ID)
+	}
+
+	if got := report.Results[0].Action; got != transfer.ActionCreated {
+		t.Fatalf("new site action = %q, want %q", got, transfer.ActionCreated)
+	}
+
+	if got := report.IDs[first.ID]; got == otherTeam.ID {
+		t.Fatalf("site mapped to ID %d of another team", got)
+	}
+
+	created, ok := target.Site(report.IDs[first.ID])
+	if !ok {
+		t.Fatalf("imported site %d not found", report.IDs[first.ID])
+	}
+
+	if created.TeamID != 5 || created.Notes.Value() != notes {
+		t.Errorf("imported site = %+v, want team 5 with notes %q", created, notes)
+	}
+
+	for _, check := range created.Checks {
+		want := check.Type == ohdear.CheckTypeUptime
+
+		if check.Enabled != want {
+			t.Errorf("imported %s check enabled = %t, want %t", check.Type, check.Enabled, want)
+		}
+	}
+}
+
+func TestDecode_Version1(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format transfer.Format
+		give   string
+	}{
+		{
+			name:   "json",
+			format: transfer.FormatJSON,
+			give:   `{"version":1,"sites":[{"url":"https://example.com","http_client_headers":"X-Token: secret\nAccept: text/html","uptime_check_payload":["ping=1"]}]}`,
+		},
+		{
+			name:   "yaml",
+			format: transfer.FormatYAML,
+			give: `version: 1
+sites:
+  - url: https://example.com
+    http_client_headers: "X-Token: secret\nAccept: text/html"
+    uptime_check_payload: ["ping=1"]
+`,
+		},
+		{
+			name:   "csv",
+			format: transfer.FormatCSV,
+			give:   "url,http_client_headers,uptime_check_payload\nhttps://example.com,\"X-Token: secret\nAccept: text/html\",ping=1\n",
+		},
+	}
+
+	want := &transfer.Uptime{
+		Headers: []transfer.KeyValue{{Key: "X-Token", Value: "secret"}, {Key: "Accept", Value: "text/html"}},
+		Payload: []transfer.KeyValue{{Key: "ping", Value: "1"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			doc, err := transfer.Decode(strings.NewReader(tt.give), tt.format)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			site := doc.Sites[0]
+
+			if !reflect.DeepEqual(site.Uptime, want) {
+				t.Errorf("Uptime = %+v, want %+v", site.Uptime, want)
+			}
+
+			if doc.Version != transfer.Version || site.HTTPClientHeaders != "" || site.UptimeCheckPayload != nil {
+				t.Errorf("Decode() = %+v, want a document of the current version", doc)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package ohdear
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"golang.org/x/net/http/httpguts"
+)
+
+// IPVersion is the IP version uptime checks connect over.
+type IPVersion string
+
+// IP versions supported by Oh Dear. Uptime checks use either version when
+// none is set.
+const (
+	IPVersion4 IPVersion = "ipv4"
+	IPVersion6 IPVersion = "ipv6"
+)
+
+// Known returns true if the IP version is one supported by Oh Dear.
+func (v IPVersion) Known() bool {
+	switch v {
+	case IPVersion4, IPVersion6:
+		return true
+	default:
+		return false
+	}
+}
+
+// _uptimeHeadersField is the member of a site object holding the headers of
+// the uptime check.
+const _uptimeHeadersField string = "http_client_headers"
+
+// _uptimeMethods are the HTTP methods uptime checks can use.
+var _uptimeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// UptimeHeader is a header sent with the requests of uptime checks.
+type UptimeHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UptimePayloadField is a field of the form sent as the body of the requests
+// of uptime checks.
+type UptimePayloadField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// UptimeSettings holds the settings of the uptime check of a site, which
+// control the request made to the site and how its response is judged.
+//
+// Fields left empty are not changed when the settings are sent to the API.
+// Use Null to clear the nullable ones.
+type UptimeSettings struct {
+	// LookForString is text the response must contain for the site to be
+	// considered up.
+	LookForString Nullable[string] `json:"uptime_check_look_for_string,omitempty"`
+
+	// AbsentString is text the response must not contain for the site to be
+	// considered up.
+	AbsentString Nullable[string] `json:"uptime_check_absent_string,omitempty"`
+
+	// MaxRedirects is how many redirects are followed. Zero disables
+	// following redirects.
+	MaxRedirects Nullable[int] `json:"uptime_check_max_redirect_count,omitempty"`
+
+	// VerifyTLS tells whether the TLS certificate of the site is verified.
+	VerifyTLS Nullable[bool] `json:"uptime_check_verify_tls,omitempty"`
+
+	// Method is the HTTP method of the request, such as http.MethodGet.
+	Method string `json:"uptime_check_http_verb,omitempty"`
+
+	// IPVersion is the IP version the request is made over.
+	IPVersion IPVersion `json:"uptime_check_ip_version,omitempty"`
+
+	// ExpectedStatusCodes holds the status codes the response may have for
+	// the site to be considered up. Codes can end with wildcards, such as 2*
+	// for every 2xx status.
+	ExpectedStatusCodes []string `json:"uptime_check_expected_response_codes,omitempty"`
+
+	// Headers are sent with the request.
+	Headers []UptimeHeader `json:"http_client_headers,omitempty"`
+
+	// Payload is sent as the body of the request. Only POST, PUT and PATCH
+	// requests can have one.
+	Payload []UptimePayloadField `json:"uptime_check_payload,omitempty"`
+
+	// TimeoutInSeconds is how long the response is waited for.
+	TimeoutInSeconds int `json:"uptime_check_timeout_in_seconds,omitempty"`
+}
+
+// Validate returns an error wrapping ErrInvalidUptimeSettings if the settings
+// would be rejected by Oh Dear.
+func (u *UptimeSettings) Validate() error {
+	if u.Method != "" && !containsFold(_uptimeMethods, u.Method) {
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidUptimeSettings, u.Method)
+	}
+
+	if u.IPVersion != "" && !u.IPVersion.Known() {
+		return fmt.Errorf("%w: unknown IP version %q", ErrInvalidUptimeSettings, u.IPVersion)
+	}
+
+	for _, code := range u.ExpectedStatusCodes {
+		if !validStatusPattern(code) {
+			return fmt.Errorf("%w: invalid status code %q", ErrInvalidUptimeSettings, code)
+		}
+	}
+
+	for _, header := range u.Headers {
+		if !httpguts.ValidHeaderFieldName(header.Name) || !httpguts.ValidHeaderFieldValue(header.Value) {
+			return fmt.Errorf("%w: invalid header %q", ErrInvalidUptimeSettings, header.Name)
+		}
+	}
+
+	if len(u.Payload) > 0 && !containsFold([]string{http.MethodPost, http.MethodPut, http.MethodPatch}, u.Method) {
+		return fmt.Errorf("%w: a payload requires the POST, PUT or PATCH method", ErrInvalidUptimeSettings)
+	}
+
+	for _, field := range u.Payload {
+		if field.Key == "" {
+			return fmt.Errorf("%w: payload field without a key", ErrInvalidUptimeSettings)
+		}
+	}
+
+	if redirects, ok := u.MaxRedirects.Get(); ok && redirects < 0 {
+		return fmt.Errorf("%w: negative max redirects", ErrInvalidUptimeSettings)
+	}
+
+	if u.TimeoutInSeconds < 0 {
+		return fmt.Errorf("%w: negative timeout", ErrInvalidUptimeSettings)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. Nullable fields that
+// are not set are left out.
+func (u UptimeSettings) MarshalJSON() ([]byte, error) {
+	type settings UptimeSettings
+
+	data, err := jsonutil.MarshalObject(settings(u), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return data, nil
+}
+
+// clone returns a deep copy of the settings.
+func (u *UptimeSettings) clone() *UptimeSettings {
+	c := *u
+	c.ExpectedStatusCodes = append([]string(nil), u.ExpectedStatusCodes...)
+	c.Headers = append([]UptimeHeader(nil), u.Headers...)
+	c.Payload = append([]UptimePayloadField(nil), u.Payload...)
+
+	return &c
+}
+
+// fields returns the members of the JSON object of the settings.
+func (u *UptimeSettings) fields() (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal uptime settings: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("could not unmarshal uptime settings: %w", err)
+	}
+
+	return fields, nil
+}
+
+// splitUptimeSettings decodes the uptime settings held by the members of a
+// site object that don't match a field of Site, on top of current, and
+// returns them with the remaining members. If no member holds an uptime
+// setting or they can't be decoded, current and extra are returned as-is.
+func splitUptimeSettings(current *UptimeSettings, extra map[string]json.RawMessage) (*UptimeSettings, map[string]json.RawMessage) {
+	if len(extra) == 0 {
+		return current, extra
+	}
+
+	data, err := json.Marshal(extra)
+	if err != nil {
+		return current, extra
+	}
+
+	type settings UptimeSettings
+
+	var decoded settings
+	if current != nil {
+		decoded = settings(*current.clone())
+	}
+
+	rest, err := jsonutil.UnmarshalObject(data, &decoded)
+	if err != nil || len(rest) == len(extra) {
+		return current, extra
+	}
+
+	uptime := UptimeSettings(decoded)
+
+	return &uptime, rest
+}
+
+// parseHeaderLines returns the headers in text, given one per line as
+// "Name: Value".
+func parseHeaderLines(text string) []UptimeHeader {
+	var headers []UptimeHeader
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+
+		headers = append(headers, UptimeHeader{
+			Name:  strings.TrimSpace(name),
+			Value: strings.TrimSpace(value),
+		})
+	}
+
+	return headers
+}
+
+// parsePayloadFields returns the payload fields in entries, given as
+// "key=value".
+func parsePayloadFields(entries []string) []UptimePayloadField {
+	var fields []UptimePayloadField
+
+	for _, entry := range entries {
+		key, value, _ := strings.Cut(entry, "=")
+
+		fields = append(fields, UptimePayloadField{
+			Key:   key,
+			Value: value,
+		})
+	}
+
+	return fields
+}
+
+// validStatusPattern reports whether code is a status code, such as 200, or a
+// pattern of status codes, such as 2* or 20*.
+func validStatusPattern(code string) bool {
+	if len(code) < 2 || len(code) > 3 || code[0] < '1' || code[0] > '5' {
+		return false
+	}
+
+	for i := 1; i < len(code); i++ {
+		switch {
+		case code[i] == '*':
+			return i == len(code)-1
+		case code[i] < '0' || code[i] > '9':
+			return false
+		}
+	}
+
+	return len(code) == 3
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(entry, s) {
+			return true
+		}
+	}
+
+	return false
+}
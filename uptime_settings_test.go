@@ -0,0 +1,168 @@
+package ohdear_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+func TestUptimeSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		settings  ohdear.UptimeSettings
+		wantError bool
+	}{
+		{
+			name:     "Empty",
+			settings: ohdear.UptimeSettings{},
+		},
+		{
+			name: "Valid",
+			settings: ohdear.UptimeSettings{
+				LookForString:       ohdear.NewNullable("healthy"),
+				MaxRedirects:        ohdear.NewNullable(0),
+				VerifyTLS:           ohdear.NewNullable(false),
+				Method:              "post",
+				IPVersion:           ohdear.IPVersion6,
+				ExpectedStatusCodes: []string{"200", "2*", "30*"},
+				Headers:             []ohdear.UptimeHeader{{Name: "Authorization", Value: "Bearer token"}},
+				Payload:             []ohdear.UptimePayloadField{{Key: "check", Value: "deep"}},
+				TimeoutInSeconds:    10,
+			},
+		},
+		{
+			name:      "Unknown method",
+			settings:  ohdear.UptimeSettings{Method: "FETCH"},
+			wantError: true,
+		},
+		{
+			name:      "Unknown IP version",
+			settings:  ohdear.UptimeSettings{IPVersion: "ipv5"},
+			wantError: true,
+		},
+		{
+			name:      "Invalid status code",
+			settings:  ohdear.UptimeSettings{ExpectedStatusCodes: []string{"2*0"}},
+			wantError: true,
+		},
+		{
+			name:      "Invalid header name",
+			settings:  ohdear.UptimeSettings{Headers: []ohdear.UptimeHeader{{Name: "X Token", Value: "secret"}}},
+			wantError: true,
+		},
+		{
+			name:      "Header value with a newline",
+			settings:  ohdear.UptimeSettings{Headers: []ohdear.UptimeHeader{{Name: "X-Token", Value: "a\nb"}}},
+			wantError: true,
+		},
+		{
+			name:      "Payload without a method allowing it",
+			settings:  ohdear.UptimeSettings{Payload: []ohdear.UptimePayloadField{{Key: "check", Value: "deep"}}},
+			wantError: true,
+		},
+		{
+			name:      "Negative max redirects",
+			settings:  ohdear.UptimeSettings{MaxRedirects: ohdear.NewNullable(-1)},
+			wantError: true,
+		},
+		{
+			name:      "Negative timeout",
+			settings:  ohdear.UptimeSettings{TimeoutInSeconds: -1},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.settings.Validate()
+
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Validate() error = %v, wantError %v", err, tt.wantError)
+			}
+
+			if err != nil && !errors.Is(err, ohdear.ErrInvalidUptimeSettings) {
+				t.Errorf("Validate() error = %v, want %v", err, ohdear.ErrInvalidUptimeSettings)
+			}
+		})
+	}
+}
+
+func TestSitesService_UpdateUptimeSettings(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx = context.Background()
+		srv = ohdeartest.NewServer(nil)
+	)
+
+	defer srv.Close()
+
+	client := srv.Client()
+
+	_, _, err := client.Sites.Add(ctx, &ohdear.Site{
+		URL:    "https://example.com",
+		TeamID: 1,
+		Uptime: &ohdear.UptimeSettings{Method: "FETCH"},
+	})
+	if !errors.Is(err, ohdear.ErrInvalidUptimeSettings) {
+		t.Errorf("Add() with invalid uptime settings error = %v, want %v", err, ohdear.ErrInvalidUptimeSettings)
+	}
+
+	added, _, err := client.Sites.Add(ctx, &ohdear.Site{
+		URL:    "https://example.com",
+		TeamID: 1,
+		Uptime: &ohdear.UptimeSettings{
+			LookForString: ohdear.NewNullable("healthy"),
+			Method:        http.MethodGet,
+			Headers:       []ohdear.UptimeHeader{{Name: "Authorization", Value: "Bearer token"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if added.Uptime == nil || added.Uptime.LookForString.Value() != "healthy" || len(added.Uptime.Headers) != 1 {
+		t.Fatalf("Add() uptime settings = %+v", added.Uptime)
+	}
+
+	if _, _, err = client.Sites.UpdateUptimeSettings(ctx, uint(added.ID), nil); !errors.Is(err, ohdear.ErrNilUptimeSettings) {
+		t.Errorf("UpdateUptimeSettings(nil) error = %v, want %v", err, ohdear.ErrNilUptimeSettings)
+	}
+
+	updated, _, err := client.Sites.UpdateUptimeSettings(ctx, uint(added.ID), &ohdear.UptimeSettings{
+		LookForString:       ohdear.Null[string](),
+		Method:              http.MethodPost,
+		ExpectedStatusCodes: []string{"2*"},
+		Payload:             []ohdear.UptimePayloadField{{Key: "check", Value: "deep"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUptimeSettings() error = %v", err)
+	}
+
+	got := updated.Uptime
+
+	switch {
+	case got == nil:
+		t.Fatal("UpdateUptimeSettings() returned no uptime settings")
+	case !got.LookForString.IsNull():
+		t.Errorf("LookForString = %v, want null", got.LookForString)
+	case got.Method != http.MethodPost || len(got.ExpectedStatusCodes) != 1 || len(got.Payload) != 1:
+		t.Errorf("UpdateUptimeSettings() = %+v, want the new method, status codes and payload", got)
+	case len(got.Headers) != 1:
+		t.Errorf("UpdateUptimeSettings() headers = %+v, want the headers to be kept", got.Headers)
+	}
+
+	if updated.URL != added.URL || updated.Extra["uptime_check_http_verb"] != nil {
+		t.Errorf("UpdateUptimeSettings() = %+v, want the other fields unchanged", updated)
+	}
+}
@@ -0,0 +1,324 @@
+package ohdear
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Default values for the WatchOptions struct.
+const (
+	DefaultWatchInterval time.Duration = time.Minute
+	DefaultFlapWindow    time.Duration = 30 * time.Minute
+	DefaultFlapThreshold int           = 5
+)
+
+// EventType identifies the kind of change reported by an Event.
+type EventType string
+
+// Event types emitted by Client.Watch.
+const (
+	// EventSiteAdded is emitted when a site appears in the account.
+	EventSiteAdded EventType = "site_added"
+
+	// EventSiteRemoved is emitted when a site disappears from the account.
+	EventSiteRemoved EventType = "site_removed"
+
+	// EventCheckChanged is emitted when the result of a check changes and
+	// holds for at least WatchOptions.Debounce.
+	EventCheckChanged EventType = "check_changed"
+
+	// EventCheckFlapping is emitted when a check changes result too often
+	// within WatchOptions.FlapWindow. Changes of the check are not reported
+	// until it's stable again.
+	EventCheckFlapping EventType = "check_flapping"
+
+	// EventCheckStable is emitted when a flapping check held the same result
+	// for a full WatchOptions.FlapWindow.
+	EventCheckStable EventType = "check_stable"
+
+	// EventError is emitted when the sites could not be listed. Watching
+	// continues with the next poll.
+	EventError EventType = "error"
+)
+
+// Event is a change observed by Client.Watch.
+type Event struct {
+	// Time is when the change was observed.
+	Time time.Time
+
+	// Err is the error that made a poll fail, for EventError.
+	Err error
+
+	// Check is the check that changed, for check events.
+	Check *Check
+
+	// Type is the kind of change.
+	Type EventType
+
+	// From is the result the check was last reported with, for check events.
+	From CheckResult
+
+	// To is the current result of the check, for check events.
+	To CheckResult
+
+	// Site is the site that changed, or the site of the check that changed.
+	// For EventSiteRemoved, it's the last known state of the site.
+	Site Site
+}
+
+// WatchOptions configures Client.Watch.
+type WatchOptions struct {
+	// RequestOptions are applied to every request made to list the sites.
+	//
+	// This field is optional.
+	RequestOptions []RequestOption
+
+	// Interval is how often the sites are listed.
+	//
+	// This field is optional. It defaults to DefaultWatchInterval.
+	Interval time.Duration
+
+	// Debounce is how long a new check result must hold before it's
+	// reported. Results that revert sooner are never reported.
+	//
+	// This field is optional. By default, changes are reported as soon as
+	// they're observed.
+	Debounce time.Duration
+
+	// FlapWindow is the window in which changes are counted to detect
+	// flapping checks, and how long a flapping check must be stable before
+	// it's reported again.
+	//
+	// This field is optional. It defaults to DefaultFlapWindow.
+	FlapWindow time.Duration
+
+	// FlapThreshold is the number of changes within FlapWindow that make a
+	// check flapping. A negative value disables flap detection.
+	//
+	// This field is optional. It defaults to DefaultFlapThreshold.
+	FlapThreshold int
+}
+
+// checkState is the state of a check kept between polls.
+type checkState struct {
+	// pendingSince is when observed was first seen.
+	pendingSince time.Time
+
+	// lastChange is when the result of the check last changed.
+	lastChange time.Time
+
+	// changes holds when the result of the check changed within the flap
+	// window.
+	changes []time.Time
+
+	// reported is the result last reported.
+	reported CheckResult
+
+	// observed is the result seen in the last poll.
+	observed CheckResult
+
+	// flapping is true while the check is flapping.
+	flapping bool
+}
+
+// watcher diffs the sites returned by consecutive polls.
+type watcher struct {
+	sites  map[int]Site
+	checks map[int]*checkState
+	opts   WatchOptions
+
+	// ready is true once the baseline has been set by a successful poll.
+	ready bool
+}
+
+// Watch polls the sites of the account at an interval and reports the changes
+// between polls on the returned channel, which is closed when ctx is done.
+//
+// The first poll sets the baseline and reports no changes. Changes are
+// reported in the order they're found, and polling waits for the caller to
+// receive them, so the channel should be drained promptly.
+//
+// A nil opts uses the default options.
+func (c *Client) Watch(ctx context.Context, opts *WatchOptions) (<-chan Event, error) {
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+
+	w := newWatcher(opts)
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(w.opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			sites, err := c.Sites.ListAll(ctx, w.opts.RequestOptions...)
+
+			var pending []Event
+
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				pending = []Event{{Type: EventError, Time: time.Now(), Err: err}}
+			default:
+				pending = w.observe(time.Now(), sites)
+			}
+
+			for i := range pending {
+				select {
+				case events <- pending[i]:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// newWatcher returns a new watcher with the defaults of opts applied.
+func newWatcher(opts *WatchOptions) *watcher {
+	var o WatchOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Interval < 1 {
+		o.Interval = DefaultWatchInterval
+	}
+
+	if o.FlapWindow < 1 {
+		o.FlapWindow = DefaultFlapWindow
+	}
+
+	if o.FlapThreshold == 0 {
+		o.FlapThreshold = DefaultFlapThreshold
+	}
+
+	return &watcher{
+		sites:  make(map[int]Site),
+		checks: make(map[int]*checkState),
+		opts:   o,
+	}
+}
+
+// observe records the sites returned by a poll made at now and returns the
+// changes since the previous poll. The first call sets the baseline and
+// returns no changes.
+func (w *watcher) observe(now time.Time, sites []Site) []Event {
+	var (
+		events []Event
+		seen   = make(map[int]bool, len(sites))
+		checks = make(map[int]*checkState, len(w.checks))
+	)
+
+	for i := range sites {
+		site := sites[i]
+		seen[site.ID] = true
+
+		if _, ok := w.sites[site.ID]; !ok && w.ready {
+			events = append(events, Event{Type: EventSiteAdded, Time: now, Site: site})
+		}
+
+		w.sites[site.ID] = site
+
+		for j := range site.Checks {
+			check := &site.Checks[j]
+
+			state, ok := w.checks[check.ID]
+			if !ok {
+				state = &checkState{
+					reported: check.LatestRunResult,
+					observed: check.LatestRunResult,
+				}
+			}
+
+			checks[check.ID] = state
+
+			if typ, from, ok := w.update(state, now, check.LatestRunResult); ok {
+				events = append(events, Event{
+					Type:  typ,
+					Time:  now,
+					Site:  site,
+					Check: check,
+					From:  from,
+					To:    check.LatestRunResult,
+				})
+			}
+		}
+	}
+
+	w.checks = checks
+	w.ready = true
+
+	removed := make([]int, 0)
+
+	for id := range w.sites {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	sort.Ints(removed)
+
+	for _, id := range removed {
+		events = append(events, Event{Type: EventSiteRemoved, Time: now, Site: w.sites[id]})
+
+		delete(w.sites, id)
+	}
+
+	return events
+}
+
+// update records the result of a check observed at now and returns the type
+// of event to report, along with the result previously reported, if any.
+func (w *watcher) update(state *checkState, now time.Time, result CheckResult) (typ EventType, from CheckResult, ok bool) {
+	if result != state.observed {
+		state.observed = result
+		state.pendingSince = now
+		state.lastChange = now
+		state.changes = append(state.changes, now)
+	}
+
+	cutoff := now.Add(-w.opts.FlapWindow)
+
+	for len(state.changes) > 0 && !state.changes[0].After(cutoff) {
+		state.changes = state.changes[1:]
+	}
+
+	from = state.reported
+
+	switch {
+	case state.flapping:
+		if now.Sub(state.lastChange) < w.opts.FlapWindow {
+			return "", "", false
+		}
+
+		state.flapping = false
+		state.changes = nil
+		state.reported = state.observed
+
+		return EventCheckStable, from, true
+	case w.opts.FlapThreshold > 0 && len(state.changes) >= w.opts.FlapThreshold:
+		state.flapping = true
+
+		return EventCheckFlapping, from, true
+	case state.observed != state.reported && now.Sub(state.pendingSince) >= w.opts.Debounce:
+		state.reported = state.observed
+
+		return EventCheckChanged, from, true
+	default:
+		return "", "", false
+	}
+}
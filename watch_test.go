@@ -0,0 +1,161 @@
+package ohdear_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/ohdeartest"
+)
+
+// nextEvent returns the next event received from events, failing the test if
+// none arrives in time.
+func nextEvent(t *testing.T, events <-chan ohdear.Event) ohdear.Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("events channel closed")
+		}
+
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+
+	return ohdear.Event{}
+}
+
+// startWatch adds a site to srv and starts watching it with opts, returning
+// the site and the events channel once the baseline is set.
+func startWatch(t *testing.T, srv *ohdeartest.Server, opts *ohdear.WatchOptions) (*ohdear.Site, <-chan ohdear.Event) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	site := srv.AddSite(&ohdear.Site{URL: "https://example.com", TeamID: 1})
+	srv.SetCheckResult(site.ID, ohdear.CheckTypeUptime, ohdear.CheckResultSucceeded)
+
+	var (
+		polled = make(chan struct{})
+		once   sync.Once
+	)
+
+	cfg := ohdear.NewConfig("", nil)
+	cfg.Middleware = []ohdear.Middleware{
+		func(next ohdear.Doer) ohdear.Doer {
+			return ohdear.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.Do(req)
+
+				once.Do(func() { close(polled) })
+
+				return resp, err
+			})
+		},
+	}
+
+	client, err := srv.ClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("ClientWithConfig() error = %v", err)
+	}
+
+	events, err := client.Watch(ctx, opts)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// Sites added after the first poll are missing from the baseline, so
+	// they're reported.
+	<-polled
+
+	added := srv.AddSite(&ohdear.Site{URL: "https://example.org", TeamID: 1})
+
+	if event := nextEvent(t, events); event.Type != ohdear.EventSiteAdded || event.Site.ID != added.ID {
+		t.Fatalf("first event = %s for site %d, want %s for site %d", event.Type, event.Site.ID, ohdear.EventSiteAdded, added.ID)
+	}
+
+	return site, events
+}
+
+func TestClient_Watch(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	site, events := startWatch(t, srv, &ohdear.WatchOptions{
+		Interval:      5 * time.Millisecond,
+		FlapWindow:    500 * time.Millisecond,
+		FlapThreshold: 3,
+	})
+
+	tests := []struct {
+		result   ohdear.CheckResult
+		wantType ohdear.EventType
+		wantFrom ohdear.CheckResult
+	}{
+		{ohdear.CheckResultFailed, ohdear.EventCheckChanged, ohdear.CheckResultSucceeded},
+		{ohdear.CheckResultSucceeded, ohdear.EventCheckChanged, ohdear.CheckResultFailed},
+		{ohdear.CheckResultFailed, ohdear.EventCheckFlapping, ohdear.CheckResultSucceeded},
+		{ohdear.CheckResultFailed, ohdear.EventCheckStable, ohdear.CheckResultSucceeded},
+	}
+
+	for _, tt := range tests {
+		srv.SetCheckResult(site.ID, ohdear.CheckTypeUptime, tt.result)
+
+		event := nextEvent(t, events)
+
+		if event.Type != tt.wantType || event.From != tt.wantFrom || event.To != tt.result {
+			t.Fatalf("event = %s from %q to %q, want %s from %q to %q", event.Type, event.From, event.To, tt.wantType, tt.wantFrom, tt.result)
+		}
+
+		if event.Site.ID != site.ID || event.Check == nil || event.Check.Type != ohdear.CheckTypeUptime {
+			t.Fatalf("event = %+v, want an event for the uptime check of site %d", event, site.ID)
+		}
+	}
+
+	if _, err := srv.Client().Sites.Remove(context.Background(), uint(site.ID)); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	if event := nextEvent(t, events); event.Type != ohdear.EventSiteRemoved || event.Site.ID != site.ID {
+		t.Errorf("event = %s for site %d, want %s for site %d", event.Type, event.Site.ID, ohdear.EventSiteRemoved, site.ID)
+	}
+}
+
+func TestClient_Watch_Debounce(t *testing.T) {
+	t.Parallel()
+
+	srv := ohdeartest.NewServer(nil)
+	defer srv.Close()
+
+	site, events := startWatch(t, srv, &ohdear.WatchOptions{
+		Interval:      5 * time.Millisecond,
+		Debounce:      100 * time.Millisecond,
+		FlapThreshold: -1,
+	})
+
+	// A failure that reverts before the debounce period is never reported.
+	srv.SetCheckResult(site.ID, ohdear.CheckTypeUptime, ohdear.CheckResultFailed)
+	time.Sleep(20 * time.Millisecond)
+	srv.SetCheckResult(site.ID, ohdear.CheckTypeUptime, ohdear.CheckResultSucceeded)
+
+	start := time.Now()
+
+	srv.SetCheckResult(site.ID, ohdear.CheckTypeUptime, ohdear.CheckResultWarning)
+
+	event := nextEvent(t, events)
+
+	if event.Type != ohdear.EventCheckChanged || event.From != ohdear.CheckResultSucceeded || event.To != ohdear.CheckResultWarning {
+		t.Errorf("event = %s from %q to %q, want %s from %q to %q", event.Type, event.From, event.To, ohdear.EventCheckChanged, ohdear.CheckResultSucceeded, ohdear.CheckResultWarning)
+	}
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("event reported after %v, want at least the debounce period", elapsed)
+	}
+}
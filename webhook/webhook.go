@@ -0,0 +1,323 @@
+// Package webhook verifies and parses the webhooks sent by Oh Dear.
+//
+// Oh Dear signs the body of every webhook with the secret configured for it,
+// using HMAC-SHA256, and sends the signature in the Signature header. Parsed
+// webhooks are normalized into an Event, which tells which check of which
+// site changed and what its result is now.
+//
+//	http.Handle("/webhook", webhook.Handler(secret, func(ctx context.Context, event *webhook.Event) error {
+//		log.Printf("%s", event.Summary)
+//
+//		return nil
+//	}))
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/internal/jsonutil"
+	"git.sr.ht/~jamesponddotco/xstd-go/xerrors"
+)
+
+const (
+	// ErrMissingSignature is returned when a webhook has no signature.
+	ErrMissingSignature xerrors.Error = "missing webhook signature"
+
+	// ErrInvalidSignature is returned when the signature of a webhook doesn't
+	// match its body.
+	ErrInvalidSignature xerrors.Error = "invalid webhook signature"
+
+	// ErrSecretRequired is returned when a webhook is verified without a
+	// secret.
+	ErrSecretRequired xerrors.Error = "webhook secret cannot be empty"
+
+	// ErrInvalidPayload is returned when the body of a webhook cannot be
+	// parsed.
+	ErrInvalidPayload xerrors.Error = "invalid webhook payload"
+)
+
+// SignatureHeader is the header holding the signature of a webhook.
+const SignatureHeader string = "Signature"
+
+// MaxBodySize is the maximum size of a webhook body accepted by Handler.
+const MaxBodySize int64 = 1 << 20
+
+// _timeLayout is the layout of the dateTime field of webhooks.
+const _timeLayout string = "20060102150405"
+
+// Event is a normalized Oh Dear webhook.
+type Event struct {
+	// Time is when Oh Dear sent the webhook.
+	Time time.Time `json:"time"`
+
+	// Payload is the payload of the webhook as sent by Oh Dear.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// ID identifies the webhook. It's derived from its body, so deliveries of
+	// the same webhook retried by Oh Dear share the same ID.
+	ID string `json:"id"`
+
+	// Type is the type of the webhook as sent by Oh Dear, such as
+	// "uptimeCheckFailedNotification".
+	Type string `json:"type"`
+
+	// CheckType is the type of check the webhook is about, or empty if it's
+	// not about a check known to this package.
+	CheckType ohdear.CheckType `json:"check_type,omitempty"`
+
+	// Result is the result of the check implied by the webhook, or empty if
+	// it doesn't imply one.
+	Result ohdear.CheckResult `json:"result,omitempty"`
+
+	// Summary is a human-readable description of the webhook, such as
+	// "https://example.com: Uptime check failed".
+	Summary string `json:"summary"`
+
+	// Site is the site the webhook is about.
+	Site Site `json:"site"`
+}
+
+// Site is the site a webhook is about.
+type Site struct {
+	URL    string   `json:"url"`
+	Label  string   `json:"label,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	ID     int      `json:"id"`
+	TeamID int      `json:"team_id,omitempty"`
+}
+
+// Name returns the label of the site, or its URL if it has no label.
+func (s Site) Name() string {
+	if s.Label != "" {
+		return s.Label
+	}
+
+	return s.URL
+}
+
+// HasTag returns true if the site has the given tag.
+func (s Site) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkPrefixes maps the prefix of webhook types to the type of check they're
+// about. Prefixes that extend another one come first, so they take precedence.
+var checkPrefixes = []struct {
+	prefix    string
+	checkType ohdear.CheckType
+}{
+	{"certificateTransparency", ohdear.CheckTypeCertificateTransparency},
+	{"certificateHealth", ohdear.CheckTypeCertificateHealth},
+	{"certificateExpires", ohdear.CheckTypeCertificateHealth},
+	{"applicationHealth", ohdear.CheckTypeApplicationHealth},
+	{"mixedContent", ohdear.CheckTypeMixedContent},
+	{"brokenLinks", ohdear.CheckTypeBrokenLinks},
+	{"performance", ohdear.CheckTypePerformance},
+	{"scheduledTask", ohdear.CheckTypeCron},
+	{"lighthouse", ohdear.CheckTypeLighthouse},
+	{"cronCheck", ohdear.CheckTypeCron},
+	{"dnsRecord", ohdear.CheckTypeDNS},
+	{"sitemap", ohdear.CheckTypeSitemap},
+	{"uptime", ohdear.CheckTypeUptime},
+	{"domain", ohdear.CheckTypeDomain},
+	{"dns", ohdear.CheckTypeDNS},
+}
+
+// resultSuffixes maps the suffix of webhook types to the result they imply.
+var resultSuffixes = []struct {
+	suffix string
+	result ohdear.CheckResult
+}{
+	{"Recovered", ohdear.CheckResultSucceeded},
+	{"Succeeded", ohdear.CheckResultSucceeded},
+	{"Fixed", ohdear.CheckResultSucceeded},
+	{"Failed", ohdear.CheckResultFailed},
+	{"Found", ohdear.CheckResultFailed},
+	{"Exceeded", ohdear.CheckResultWarning},
+	{"ExpiresSoon", ohdear.CheckResultWarning},
+	{"Changed", ohdear.CheckResultWarning},
+	{"Warning", ohdear.CheckResultWarning},
+}
+
+// Sign returns the signature of a webhook body for the given secret.
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature is the signature of body for the given secret.
+func Verify(body []byte, signature, secret string) error {
+	if secret == "" {
+		return ErrSecretRequired
+	}
+
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	want := Sign(body, secret)
+
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(want)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Parse parses the body of a webhook into an Event. It doesn't verify the
+// signature of the webhook.
+func Parse(body []byte) (*Event, error) {
+	var raw struct {
+		Type     string          `json:"type"`
+		DateTime string          `json:"dateTime"`
+		Payload  json.RawMessage `json:"payload"`
+	}
+
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	if raw.Type == "" {
+		return nil, fmt.Errorf("%w: missing type", ErrInvalidPayload)
+	}
+
+	var payload struct {
+		Site Site `json:"site"`
+	}
+
+	if len(raw.Payload) > 0 {
+		if err := json.Unmarshal(raw.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+		}
+	}
+
+	sum := sha256.Sum256(body)
+
+	event := &Event{
+		Payload: raw.Payload,
+		ID:      hex.EncodeToString(sum[:]),
+		Type:    raw.Type,
+		Site:    payload.Site,
+	}
+
+	if t, err := time.ParseInLocation(_timeLayout, raw.DateTime, jsonutil.Location()); err == nil {
+		event.Time = t
+	}
+
+	name := strings.TrimSuffix(raw.Type, "Notification")
+
+	for _, p := range checkPrefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			event.CheckType = p.checkType
+
+			break
+		}
+	}
+
+	for _, s := range resultSuffixes {
+		if strings.HasSuffix(name, s.suffix) {
+			event.Result = s.result
+
+			break
+		}
+	}
+
+	event.Summary = humanize(name)
+	if site := event.Site.Name(); site != "" {
+		event.Summary = site + ": " + event.Summary
+	}
+
+	return event, nil
+}
+
+// ParseRequest verifies the signature of the webhook sent in r and parses it
+// into an Event.
+func ParseRequest(r *http.Request, secret string) (*Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("could not read webhook: %w", err)
+	}
+
+	if err := Verify(body, r.Header.Get(SignatureHeader), secret); err != nil {
+		return nil, err
+	}
+
+	return Parse(body)
+}
+
+// Handler returns an http.Handler that verifies and parses webhooks and passes
+// them to fn.
+//
+// It responds with 401 Unauthorized to webhooks with an invalid signature,
+// 400 Bad Request to webhooks that cannot be parsed and 500 Internal Server
+// Error if fn fails, so Oh Dear retries the webhook later.
+func Handler(secret string, fn func(ctx context.Context, event *Event) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		event, err := ParseRequest(r, secret)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrMissingSignature) || errors.Is(err, ErrInvalidSignature) {
+				status = http.StatusUnauthorized
+			}
+
+			http.Error(w, err.Error(), status)
+
+			return
+		}
+
+		if err := fn(r.Context(), event); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// humanize turns a camel case webhook type, such as "uptimeCheckFailed", into
+// a sentence, such as "Uptime check failed".
+func humanize(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		switch {
+		case i == 0:
+			b.WriteRune(unicode.ToUpper(r))
+		case unicode.IsUpper(r):
+			b.WriteRune(' ')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,249 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jamesponddotco/ohdear-go"
+	"git.sr.ht/~jamesponddotco/ohdear-go/webhook"
+)
+
+const _testSecret string = "s3cr3t"
+
+const _testBody string = `{
+	"type": "uptimeCheckFailedNotification",
+	"dateTime": "20240102030405",
+	"payload": {
+		"site": {
+			"id": 1,
+			"url": "https://example.com",
+			"label": "Example",
+			"tags": ["production"]
+		}
+	}
+}`
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(_testBody)
+
+	tests := []struct {
+		name      string
+		signature string
+		secret    string
+		wantErr   error
+	}{
+		{
+			name:      "Valid signature",
+			signature: webhook.Sign(body, _testSecret),
+			secret:    _testSecret,
+		},
+		{
+			name:      "Uppercase signature",
+			signature: strings.ToUpper(webhook.Sign(body, _testSecret)),
+			secret:    _testSecret,
+		},
+		{
+			name:      "Wrong secret",
+			signature: webhook.Sign(body, "other"),
+			secret:    _testSecret,
+			wantErr:   webhook.ErrInvalidSignature,
+		},
+		{
+			name:    "Missing signature",
+			secret:  _testSecret,
+			wantErr: webhook.ErrMissingSignature,
+		},
+		{
+			name:      "Missing secret",
+			signature: webhook.Sign(body, _testSecret),
+			wantErr:   webhook.ErrSecretRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := webhook.Verify(body, tt.signature, tt.secret); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		body          string
+		wantErr       error
+		wantCheckType ohdear.CheckType
+		wantResult    ohdear.CheckResult
+		wantSummary   string
+	}{
+		{
+			name:          "Uptime check failed",
+			body:          _testBody,
+			wantCheckType: ohdear.CheckTypeUptime,
+			wantResult:    ohdear.CheckResultFailed,
+			wantSummary:   "Example: Uptime check failed",
+		},
+		{
+			name:          "Certificate expires soon",
+			body:          `{"type": "certificateExpiresSoonNotification", "payload": {"site": {"url": "https://example.com"}}}`,
+			wantCheckType: ohdear.CheckTypeCertificateHealth,
+			wantResult:    ohdear.CheckResultWarning,
+			wantSummary:   "https://example.com: Certificate expires soon",
+		},
+		{
+			name:          "Broken links fixed without suffix",
+			body:          `{"type": "brokenLinksFixed"}`,
+			wantCheckType: ohdear.CheckTypeBrokenLinks,
+			wantResult:    ohdear.CheckResultSucceeded,
+			wantSummary:   "Broken links fixed",
+		},
+		{
+			name:        "Unknown type",
+			body:        `{"type": "somethingHappened"}`,
+			wantSummary: "Something happened",
+		},
+		{
+			name:    "Missing type",
+			body:    `{"payload": {}}`,
+			wantErr: webhook.ErrInvalidPayload,
+		},
+		{
+			name:    "Invalid JSON",
+			body:    `{`,
+			wantErr: webhook.ErrInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := webhook.Parse([]byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if event.CheckType != tt.wantCheckType || event.Result != tt.wantResult || event.Summary != tt.wantSummary {
+				t.Errorf("Parse() = %q %q %q, want %q %q %q", event.CheckType, event.Result, event.Summary, tt.wantCheckType, tt.wantResult, tt.wantSummary)
+			}
+
+			if len(event.ID) != 64 {
+				t.Errorf("Parse() ID = %q, want a SHA-256 hex digest", event.ID)
+			}
+		})
+	}
+}
+
+func TestParse_Site(t *testing.T) {
+	t.Parallel()
+
+	event, err := webhook.Parse([]byte(_testBody))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if event.Site.ID != 1 || event.Site.URL != "https://example.com" || !event.Site.HasTag("Production") {
+		t.Errorf("Parse() site = %+v, want site 1 tagged production", event.Site)
+	}
+
+	if want := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC); !event.Time.Equal(want) {
+		t.Errorf("Parse() time = %v, want %v", event.Time, want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	failing := errors.New("sink unavailable")
+
+	tests := []struct {
+		name       string
+		method     string
+		signature  string
+		handlerErr error
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "Valid webhook",
+			method:     http.MethodPost,
+			signature:  webhook.Sign([]byte(_testBody), _testSecret),
+			wantStatus: http.StatusNoContent,
+			wantCalled: true,
+		},
+		{
+			name:       "Invalid signature",
+			method:     http.MethodPost,
+			signature:  "invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Handler error",
+			method:     http.MethodPost,
+			signature:  webhook.Sign([]byte(_testBody), _testSecret),
+			handlerErr: failing,
+			wantStatus: http.StatusInternalServerError,
+			wantCalled: true,
+		},
+		{
+			name:       "Wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var called bool
+
+			handler := webhook.Handler(_testSecret, func(_ context.Context, event *webhook.Event) error {
+				called = true
+
+				if event.Site.ID != 1 {
+					t.Errorf("handler event site = %d, want 1", event.Site.ID)
+				}
+
+				return tt.handlerErr
+			})
+
+			req := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(_testBody))
+			req.Header.Set(webhook.SignatureHeader, tt.signature)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if called != tt.wantCalled {
+				t.Errorf("handler called = %t, want %t", called, tt.wantCalled)
+			}
+		})
+	}
+}